@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jarvisstreamer/jarvis/internal/audit"
+	"github.com/jarvisstreamer/jarvis/internal/brain"
+	"github.com/jarvisstreamer/jarvis/internal/config"
+	"github.com/jarvisstreamer/jarvis/pkg/logger"
+)
+
+// runAudit implements "jarvis audit": it lists the recorded interactions,
+// or re-executes them with "jarvis audit replay <id>|<desde>-<hasta>"
+func runAudit(args []string) int {
+	if len(args) > 0 && args[0] == "replay" {
+		return runReplay(args[1:])
+	}
+
+	fs := flag.NewFlagSet("audit", flag.ContinueOnError)
+	configPath := fs.String("config", "", "Ruta al archivo de configuración")
+	since := fs.String("since", "", "Desde cuándo: hace cuánto (2h, 30m) o fecha (2006-01-02 15:04)")
+	until := fs.String("until", "", "Hasta cuándo, con el mismo formato que -since")
+	action := fs.String("action", "", "Solo interacciones con esta acción (admite comodín: obs.*)")
+	limit := fs.Int("limit", 50, "Máximo de interacciones, las más recientes (0 = todas)")
+	asJSON := fs.Bool("json", false, "Imprimir cada interacción como JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Uso: jarvis audit [opciones]")
+		fmt.Fprintln(fs.Output(), "     jarvis audit replay [-dry-run] [-yes] <id>|<desde>-<hasta>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error cargando configuración: %v\n", err)
+		return 1
+	}
+
+	filter := audit.Filter{Action: *action}
+	if filter.Since, err = parseAuditTime(*since); err != nil {
+		fmt.Fprintf(os.Stderr, "-since inválido: %v\n", err)
+		return 2
+	}
+	if filter.Until, err = parseAuditTime(*until); err != nil {
+		fmt.Fprintf(os.Stderr, "-until inválido: %v\n", err)
+		return 2
+	}
+
+	entries, err := audit.Read(audit.Path(cfg.General.DataDir), filter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error leyendo el registro: %v\n", err)
+		return 1
+	}
+	if *limit > 0 && len(entries) > *limit {
+		entries = entries[len(entries)-*limit:]
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No hay interacciones registradas con esos filtros.")
+		return 0
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	for _, e := range entries {
+		if *asJSON {
+			_ = enc.Encode(e)
+			continue
+		}
+		printEntry(e)
+	}
+	return 0
+}
+
+// runReplay re-executes the actions of one entry or a range of entries
+func runReplay(args []string) int {
+	fs := flag.NewFlagSet("audit replay", flag.ContinueOnError)
+	configPath := fs.String("config", "", "Ruta al archivo de configuración")
+	dryRun := fs.Bool("dry-run", false, "Mostrar las acciones sin ejecutarlas")
+	yes := fs.Bool("yes", false, "No preguntar antes de las acciones que requieren confirmación")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Uso: jarvis audit replay [opciones] <id>|<desde>-<hasta>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	from, to, err := parseIDRange(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Rango inválido: %v\n", err)
+		return 2
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error cargando configuración: %v\n", err)
+		return 1
+	}
+	logger.Init(cfg.General.LogLevel, nil)
+
+	entries, err := audit.Read(audit.Path(cfg.General.DataDir), audit.Filter{FromID: from, ToID: to})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error leyendo el registro: %v\n", err)
+		return 1
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(os.Stderr, "No hay interacciones con esos ids.")
+		return 1
+	}
+
+	if *dryRun {
+		// Only the confirm list is needed, not the executors
+		b := brain.New(cfg, nil, nil)
+		for _, e := range entries {
+			action, ok := e.Replayable()
+			if !ok {
+				fmt.Printf("#%d  no se ejecutó ninguna acción, se omitiría\n", e.ID)
+				continue
+			}
+			fmt.Printf("#%d  %s\n", e.ID, describeAction(action.Action, action.Params, len(action.Steps)))
+			if _, confirm := b.ConfirmationPrompt(action); confirm && !*yes {
+				fmt.Println("     pediría confirmación")
+			}
+		}
+		return 0
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	app, err := newApp(ctx, cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error iniciando Jarvis: %v\n", err)
+		return 1
+	}
+	defer app.Close()
+
+	// Replay skips the spoken confirmation, so it is asked here instead
+	answers := bufio.NewScanner(os.Stdin)
+	status := 0
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+
+		action, ok := e.Replayable()
+		if !ok {
+			fmt.Printf("#%d  no se ejecutó ninguna acción, se omite\n", e.ID)
+			continue
+		}
+
+		fmt.Printf("#%d  %s\n", e.ID, describeAction(action.Action, action.Params, len(action.Steps)))
+		if prompt, confirm := app.brain.ConfirmationPrompt(action); confirm && !*yes {
+			fmt.Printf("     %s [s/N] ", prompt)
+			if !answers.Scan() || !brain.IsAffirmative(answers.Text()) {
+				fmt.Println("     omitida")
+				continue
+			}
+		}
+
+		response, err := app.brain.ExecuteAction(ctx, action, "replay")
+		if err != nil {
+			fmt.Printf("     ✗ %v\n", err)
+			status = 1
+			continue
+		}
+		if response.Text != "" {
+			fmt.Printf("     ← %s\n", response.Text)
+		}
+	}
+	return status
+}
+
+// printEntry prints one interaction in a compact, readable form
+func printEntry(e audit.Entry) {
+	header := fmt.Sprintf("#%d  %s", e.ID, e.Time.Local().Format("2006-01-02 15:04:05"))
+	if e.Source != "" {
+		header += "  [" + e.Source + "]"
+	}
+	if e.Transcript != "" {
+		header += fmt.Sprintf("  %q", e.Transcript)
+	}
+	fmt.Println(header)
+
+	if e.Action != nil {
+		fmt.Printf("     → %s\n", describeAction(e.Action.Action, e.Action.Params, e.Action.Steps))
+	}
+	for _, r := range e.Results {
+		if r.Success {
+			fmt.Printf("     ✓ %s (%s)\n", r.Action, r.Duration.Round(time.Millisecond))
+		} else {
+			fmt.Printf("     ✗ %s: %s\n", r.Action, r.Error)
+		}
+	}
+	for _, err := range e.Errors {
+		fmt.Printf("     ✗ %s: %s\n", err.Stage, err.Error)
+	}
+	if e.Reply != "" {
+		fmt.Printf("     ← %s\n", e.Reply)
+	}
+
+	if len(e.Timings) > 0 {
+		timings := make([]string, len(e.Timings))
+		for i, t := range e.Timings {
+			timings[i] = strings.TrimSpace(fmt.Sprintf("%s %s %s", t.Stage, t.Provider, t.Duration.Round(time.Millisecond)))
+		}
+		fmt.Printf("     ⏱ %s\n", strings.Join(timings, " · "))
+	}
+}
+
+// describeAction formats an action with its params, or a plan with its step count
+func describeAction(name string, params map[string]interface{}, steps int) string {
+	if steps > 0 {
+		return fmt.Sprintf("%s (%d pasos)", name, steps)
+	}
+	if len(params) == 0 {
+		return name
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return name
+	}
+	return name + " " + string(data)
+}
+
+// parseAuditTime parses a relative duration ("2h" means two hours ago) or
+// a local date; an empty string is the zero time
+func parseAuditTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q no es una duración (2h) ni una fecha (2006-01-02 15:04)", s)
+}
+
+// parseIDRange parses "12" or "10-15"
+func parseIDRange(s string) (int, int, error) {
+	fromStr, toStr, isRange := strings.Cut(s, "-")
+	from, err := strconv.Atoi(strings.TrimSpace(fromStr))
+	if err != nil || from < 1 {
+		return 0, 0, fmt.Errorf("%q no es un id válido", fromStr)
+	}
+	if !isRange {
+		return from, from, nil
+	}
+	to, err := strconv.Atoi(strings.TrimSpace(toStr))
+	if err != nil || to < from {
+		return 0, 0, fmt.Errorf("%q no es un final de rango válido", toStr)
+	}
+	return from, to, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/jarvisstreamer/jarvis/internal/audio"
+	"github.com/jarvisstreamer/jarvis/internal/config"
+)
+
+// runDevices implements "jarvis devices": it lists the audio input devices
+// and marks the one audio.device selects
+func runDevices(args []string) int {
+	fs := flag.NewFlagSet("devices", flag.ContinueOnError)
+	configPath := fs.String("config", "", "Ruta al archivo de configuración")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Uso: jarvis devices [opciones]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error cargando configuración: %v\n", err)
+		return 1
+	}
+
+	devices, err := audio.ListDevices()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listando los dispositivos: %v\n", err)
+		return 1
+	}
+	if len(devices) == 0 {
+		fmt.Println("No hay dispositivos de entrada.")
+		return 0
+	}
+
+	selected, selectErr := audio.SelectDevice(devices, cfg.Audio.Device)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\t#\tNombre\tAPI\tCanales\tFrecuencia\t")
+	for _, d := range devices {
+		mark := ""
+		if selectErr == nil && d.Index == selected.Index {
+			mark = "*"
+		}
+		name := d.Name
+		if d.Default {
+			name += " (predeterminado)"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%d\t%.0f Hz\t\n", mark, d.Index, name, d.HostAPI, d.InputChannels, d.DefaultSampleRate)
+	}
+	w.Flush()
+
+	fmt.Println()
+	if selectErr != nil {
+		fmt.Printf("audio.device = %q: %v\n", cfg.Audio.Device, selectErr)
+		return 1
+	}
+	fmt.Printf("audio.device = %q selecciona el #%d. Usa el número o el nombre del dispositivo.\n", cfg.Audio.Device, selected.Index)
+	return 0
+}
@@ -0,0 +1,351 @@
+// Command jarvis is the main entry point for JarvisStreamer
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jarvisstreamer/jarvis/internal/audio"
+	"github.com/jarvisstreamer/jarvis/internal/audit"
+	"github.com/jarvisstreamer/jarvis/internal/brain"
+	"github.com/jarvisstreamer/jarvis/internal/config"
+	"github.com/jarvisstreamer/jarvis/internal/events"
+	"github.com/jarvisstreamer/jarvis/internal/executor/macro"
+	"github.com/jarvisstreamer/jarvis/internal/executor/music"
+	"github.com/jarvisstreamer/jarvis/internal/executor/obs"
+	"github.com/jarvisstreamer/jarvis/internal/executor/schedule"
+	"github.com/jarvisstreamer/jarvis/internal/executor/twitch"
+	"github.com/jarvisstreamer/jarvis/internal/hotkey"
+	"github.com/jarvisstreamer/jarvis/internal/llm"
+	"github.com/jarvisstreamer/jarvis/internal/pipeline"
+	"github.com/jarvisstreamer/jarvis/internal/server"
+	"github.com/jarvisstreamer/jarvis/internal/stt"
+	"github.com/jarvisstreamer/jarvis/internal/tts"
+	"github.com/jarvisstreamer/jarvis/internal/wakeword"
+	"github.com/jarvisstreamer/jarvis/pkg/logger"
+	"golang.design/x/hotkey/mainthread"
+)
+
+func main() {
+	// The hotkey library needs the main thread on macOS
+	mainthread.Init(func() {
+		os.Exit(run())
+	})
+}
+
+// run parses flags, wires every component together and blocks until shutdown
+func run() int {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "audit":
+			return runAudit(os.Args[2:])
+		case "wakeword":
+			return runWakeWord(os.Args[2:])
+		case "devices":
+			return runDevices(os.Args[2:])
+		}
+	}
+
+	configPath := flag.String("config", "", "Ruta al archivo de configuración (por defecto se busca jarvis.config.yaml)")
+	testMode := flag.Bool("test", false, "Procesa un único comando y termina")
+	command := flag.String("command", "", "Comando a procesar en modo test")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error cargando configuración: %v\n", err)
+		return 1
+	}
+
+	logger.Init(cfg.General.LogLevel, nil)
+	log := logger.Component("main")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	app, err := newApp(ctx, cfg)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to initialize Jarvis")
+		return 1
+	}
+	defer app.Close()
+
+	if *testMode {
+		if *command == "" {
+			fmt.Fprintln(os.Stderr, "El modo test requiere -command")
+			return 2
+		}
+		if err := app.handleLine(ctx, *command); err != nil {
+			return 1
+		}
+		return 0
+	}
+
+	if err := app.Start(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to start pipeline")
+		return 1
+	}
+
+	app.repl(ctx)
+	log.Info().Msg("Shutting down")
+	return 0
+}
+
+// app holds every long-lived component of a running assistant
+type app struct {
+	cfg      *config.Config
+	stt      stt.Provider
+	brain    *brain.Brain
+	pipeline *pipeline.Pipeline
+	capture  interface{ Stop() }
+	hotkey   *hotkey.Listener
+	server   *server.Server
+	audit    *audit.Recorder
+
+	unsubscribe func()
+}
+
+// newApp builds providers, the brain, executors and the pipeline
+func newApp(ctx context.Context, cfg *config.Config) (*app, error) {
+	log := logger.Component("main")
+
+	llmProvider, err := llm.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LLM provider: %w", err)
+	}
+
+	sttProvider, err := stt.New(cfg)
+	if err != nil {
+		log.Warn().Err(err).Msg("STT provider unavailable, voice input disabled")
+		sttProvider = nil
+	}
+
+	ttsProvider, err := tts.New(cfg)
+	if err != nil {
+		log.Warn().Err(err).Msg("TTS provider unavailable, replies will only be printed")
+		ttsProvider = nil
+	}
+
+	brn := brain.New(cfg, llmProvider, ttsProvider)
+
+	obsExec := obs.NewExecutor(cfg.OBS)
+	if cfg.OBS.Enabled {
+		connectCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		if err := obsExec.Connect(connectCtx); err != nil {
+			log.Warn().Err(err).Msg("Could not connect to OBS, will retry on first command")
+		}
+		cancel()
+	}
+	brn.RegisterExecutor(obsExec)
+	brn.RegisterExecutor(twitch.NewExecutor(cfg.Twitch))
+	brn.RegisterExecutor(music.NewExecutor(cfg.Music))
+	if len(cfg.Macros) > 0 {
+		brn.RegisterExecutor(macro.NewExecutor(cfg.Macros, brn.Registry()))
+	}
+	brn.RegisterExecutor(schedule.NewExecutor(cfg.General, brn.Registry(), brn))
+
+	a := &app{
+		cfg:         cfg,
+		stt:         sttProvider,
+		brain:       brn,
+		pipeline:    pipeline.NewPipeline(cfg, sttProvider, brn),
+		unsubscribe: logEvents(),
+	}
+
+	if cfg.Audio.WakeWord.Enabled {
+		d, err := wakeword.Load(cfg.Audio.WakeWord, cfg.General.DataDir, cfg.Audio.SampleRate, cfg.Audio.Channels)
+		if err != nil {
+			log.Warn().Err(err).Msg("Wake word disabled, run 'jarvis wakeword enroll' to record it")
+		} else {
+			a.pipeline.SetWakeWord(d)
+			log.Info().Str("word", d.Word()).Msg("Wake word spotting enabled")
+		}
+	}
+
+	if cfg.Audit.Enabled {
+		rec, err := audit.Open(cfg.General.DataDir)
+		if err != nil {
+			log.Warn().Err(err).Msg("Audit log disabled")
+		} else {
+			a.audit = rec
+		}
+	}
+
+	return a, nil
+}
+
+// logEvents logs stage timings and errors published on the event bus
+func logEvents() func() {
+	log := logger.Component("events")
+
+	return events.Subscribe(func(env events.Envelope) {
+		switch e := env.Event.(type) {
+		case events.Timing:
+			log.Debug().
+				Str("stage", e.Stage).
+				Str("provider", e.Provider).
+				Dur("duration", e.Duration).
+				Msg("Stage timing")
+		case events.Error:
+			log.Debug().
+				Str("stage", e.Stage).
+				Str("error", e.Error).
+				Msg("Stage failed")
+		}
+	}, events.KindTiming, events.KindError)
+}
+
+// Start starts the pipeline, audio capture and the push-to-talk hotkey
+func (a *app) Start(ctx context.Context) error {
+	log := logger.Component("main")
+
+	if err := a.pipeline.Start(ctx); err != nil {
+		return err
+	}
+
+	if a.cfg.Server.Enabled {
+		srv := server.New(a.cfg.Server, a.pipeline, a.brain, a.stt)
+		if err := srv.Start(ctx); err != nil {
+			log.Warn().Err(err).Msg("Control API disabled")
+		} else {
+			a.server = srv
+		}
+	}
+
+	if a.stt == nil {
+		log.Warn().Msg("No STT provider, voice input disabled")
+		return nil
+	}
+
+	c, err := audio.Start(ctx, a.cfg.Audio, a.pipeline)
+	if err != nil {
+		log.Warn().Err(err).Msg("Audio capture disabled, using text mode only")
+	} else {
+		a.capture = c
+		log.Info().Str("device", a.cfg.Audio.Device).Msg("Audio capture started")
+	}
+
+	if a.cfg.Hotkey.Enabled {
+		onDown, onUp := a.hotkeyHandlers()
+		l, err := hotkey.NewListener(ctx, onDown, onUp)
+		if err != nil {
+			log.Warn().Err(err).Msg("Push-to-talk hotkey disabled")
+		} else {
+			a.hotkey = l
+			log.Info().Str("mode", a.cfg.Hotkey.Mode).Msg("Push-to-talk hotkey registered")
+		}
+	}
+
+	return nil
+}
+
+// hotkeyHandlers returns the key down/up callbacks for the configured mode
+func (a *app) hotkeyHandlers() (func(), func()) {
+	if a.cfg.Hotkey.Mode == "toggle" {
+		return func() {
+			if a.pipeline.GetState() == pipeline.StateRecording {
+				a.pipeline.TriggerHotkeyUp()
+			} else {
+				a.pipeline.TriggerHotkeyDown()
+			}
+		}, nil
+	}
+	return a.pipeline.TriggerHotkeyDown, a.pipeline.TriggerHotkeyUp
+}
+
+// repl runs the interactive text prompt until EOF, "quit" or a shutdown signal
+func (a *app) repl(ctx context.Context) {
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	fmt.Println("Jarvis listo. Escribe un comando o 'quit' para salir.")
+	for {
+		fmt.Print("Jarvis> ")
+		select {
+		case <-ctx.Done():
+			fmt.Println()
+			return
+		case line, ok := <-lines:
+			if !ok {
+				return
+			}
+			line = strings.TrimSpace(line)
+			switch strings.ToLower(line) {
+			case "":
+				continue
+			case "quit", "exit", "salir":
+				return
+			}
+			_ = a.handleLine(ctx, line)
+		}
+	}
+}
+
+// handleLine processes a typed command through the pipeline, which tracks
+// the state and speaks the reply, and prints the reply
+func (a *app) handleLine(ctx context.Context, line string) error {
+	response, err := a.pipeline.HandleCommand(ctx, line)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return err
+	}
+	if response.Text == "" {
+		return nil
+	}
+
+	fmt.Println(response.Text)
+	// Links are printed, not spoken
+	for _, result := range response.Results {
+		for key, value := range result.Data {
+			if url, ok := value.(string); ok && strings.HasSuffix(key, "_url") {
+				fmt.Println("  " + url)
+			}
+		}
+	}
+	return nil
+}
+
+// Close stops every component in reverse start order
+func (a *app) Close() {
+	log := logger.Component("main")
+
+	if a.server != nil {
+		if err := a.server.Close(); err != nil {
+			log.Warn().Err(err).Msg("Error closing control API")
+		}
+	}
+	if a.hotkey != nil {
+		a.hotkey.Close()
+	}
+	if a.capture != nil {
+		a.capture.Stop()
+	}
+	a.pipeline.Stop()
+	if a.stt != nil {
+		if err := a.stt.Close(); err != nil {
+			log.Warn().Err(err).Msg("Error closing STT provider")
+		}
+	}
+	if err := a.brain.Close(); err != nil {
+		log.Warn().Err(err).Msg("Error closing brain")
+	}
+	if a.audit != nil {
+		if err := a.audit.Close(); err != nil {
+			log.Warn().Err(err).Msg("Error closing audit log")
+		}
+	}
+	a.unsubscribe()
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jarvisstreamer/jarvis/internal/audio"
+	"github.com/jarvisstreamer/jarvis/internal/config"
+	"github.com/jarvisstreamer/jarvis/internal/wakeword"
+	"github.com/jarvisstreamer/jarvis/pkg/utils"
+)
+
+// runWakeWord implements "jarvis wakeword": "enroll" records the samples the
+// wake word is spotted with, "test" scores recordings against them
+func runWakeWord(args []string) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "enroll":
+			return runEnroll(args[1:])
+		case "test":
+			return runWakeWordTest(args[1:])
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "Uso: jarvis wakeword enroll [opciones] [archivo.wav...]")
+	fmt.Fprintln(os.Stderr, "     jarvis wakeword test [opciones] [archivo.wav...]")
+	return 2
+}
+
+// runEnroll adds samples of the wake word, from WAV files or the microphone
+func runEnroll(args []string) int {
+	fs := flag.NewFlagSet("wakeword enroll", flag.ContinueOnError)
+	configPath := fs.String("config", "", "Ruta al archivo de configuración")
+	count := fs.Int("count", 3, "Grabaciones a tomar del micrófono si no se pasan archivos")
+	seconds := fs.Float64("seconds", 2.5, "Duración de cada grabación del micrófono")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Uso: jarvis wakeword enroll [opciones] [archivo.wav...]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error cargando configuración: %v\n", err)
+		return 1
+	}
+	word := cfg.Audio.WakeWord.Word
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	status := 0
+	save := func(samples []int16, sampleRate, channels int) {
+		path, err := wakeword.Enroll(cfg.General.DataDir, word, samples, sampleRate, channels)
+		if err != nil {
+			fmt.Printf("  ✗ %v\n", err)
+			status = 1
+			return
+		}
+		fmt.Printf("  ✓ %s\n", path)
+	}
+
+	if fs.NArg() > 0 {
+		for _, file := range fs.Args() {
+			fmt.Println(file)
+			samples, rate, channels, err := readWakeWordWAV(file, cfg.Audio.SampleRate)
+			if err != nil {
+				fmt.Printf("  ✗ %v\n", err)
+				status = 1
+				continue
+			}
+			save(samples, rate, channels)
+		}
+	} else {
+		duration := time.Duration(*seconds * float64(time.Second))
+		for i := 1; i <= *count && ctx.Err() == nil; i++ {
+			fmt.Printf("Di «%s» (%d/%d)...\n", word, i, *count)
+			samples, err := audio.Record(ctx, cfg.Audio, duration)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error grabando: %v\n", err)
+				return 1
+			}
+			save(samples, cfg.Audio.SampleRate, cfg.Audio.Channels)
+		}
+	}
+
+	samples, err := wakeword.Samples(cfg.General.DataDir, word)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listando las muestras: %v\n", err)
+		return 1
+	}
+	fmt.Printf("«%s» tiene %d muestras en %s\n", word, len(samples), wakeword.Dir(cfg.General.DataDir, word))
+	if len(samples) < wakeword.MinSamples {
+		fmt.Printf("Hacen falta al menos %d para activarla.\n", wakeword.MinSamples)
+	}
+	return status
+}
+
+// runWakeWordTest prints how closely recordings match the enrolled samples
+func runWakeWordTest(args []string) int {
+	fs := flag.NewFlagSet("wakeword test", flag.ContinueOnError)
+	configPath := fs.String("config", "", "Ruta al archivo de configuración")
+	seconds := fs.Float64("seconds", 3, "Duración de la grabación del micrófono si no se pasan archivos")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Uso: jarvis wakeword test [opciones] [archivo.wav...]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error cargando configuración: %v\n", err)
+		return 1
+	}
+
+	d, err := wakeword.Load(cfg.Audio.WakeWord, cfg.General.DataDir, cfg.Audio.SampleRate, cfg.Audio.Channels)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error cargando la wake word: %v\n", err)
+		return 1
+	}
+
+	report := func(name string, samples []int16, channels int) {
+		score := d.Score(samples, channels)
+		verdict := "no se activaría"
+		if score >= cfg.Audio.WakeWord.Threshold {
+			verdict = "se activaría"
+		}
+		fmt.Printf("%s: %.2f (umbral %.2f, %s)\n", name, score, cfg.Audio.WakeWord.Threshold, verdict)
+	}
+
+	if fs.NArg() > 0 {
+		status := 0
+		for _, file := range fs.Args() {
+			samples, _, channels, err := readWakeWordWAV(file, cfg.Audio.SampleRate)
+			if err != nil {
+				fmt.Printf("%s: %v\n", file, err)
+				status = 1
+				continue
+			}
+			report(file, samples, channels)
+		}
+		return status
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("Di «%s»...\n", d.Word())
+	samples, err := audio.Record(ctx, cfg.Audio, time.Duration(*seconds*float64(time.Second)))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error grabando: %v\n", err)
+		return 1
+	}
+	report("micrófono", samples, cfg.Audio.Channels)
+	return 0
+}
+
+// readWakeWordWAV reads a recording, resampled to the capture sample rate
+func readWakeWordWAV(file string, sampleRate int) ([]int16, int, int, error) {
+	samples, rate, channels, err := utils.ReadWAV(file)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return utils.Resample(samples, rate, sampleRate, channels), sampleRate, channels, nil
+}
@@ -11,7 +11,7 @@ import (
 	"github.com/gordonklaus/portaudio"
 	"github.com/jarvisstreamer/jarvis/internal/config"
 	"github.com/jarvisstreamer/jarvis/internal/pipeline"
-	"github.com/jarvisstreamer/jarvis/internal/stt"
+	"github.com/jarvisstreamer/jarvis/pkg/logger"
 	"github.com/jarvisstreamer/jarvis/pkg/utils"
 )
 
@@ -20,15 +20,12 @@ type capture struct {
 	cancel   context.CancelFunc
 	cfg      config.AudioConfig
 	pipeline *pipeline.Pipeline
-	stt      stt.Provider
-	onWake   func()
 	stream   *portaudio.Stream
+	convert  *converter
 	wg       sync.WaitGroup
-	detected bool
-	lastWake time.Time
 }
 
-func Start(ctx context.Context, cfg config.AudioConfig, pip *pipeline.Pipeline, sttProvider stt.Provider, onWake func()) (*capture, error) {
+func Start(ctx context.Context, cfg config.AudioConfig, pip *pipeline.Pipeline) (*capture, error) {
 	if cfg.SampleRate == 0 {
 		cfg.SampleRate = 16000
 	}
@@ -49,17 +46,16 @@ func Start(ctx context.Context, cfg config.AudioConfig, pip *pipeline.Pipeline,
 		cancel:   cancel,
 		cfg:      cfg,
 		pipeline: pip,
-		stt:      sttProvider,
-		onWake:   onWake,
 	}
 
-	stream, err := portaudio.OpenDefaultStream(cfg.Channels, 0, float64(cfg.SampleRate), cfg.ChunkSize, c.process)
+	stream, format, err := openStream(cfg, c.process)
 	if err != nil {
 		portaudio.Terminate()
 		cancel()
-		return nil, fmt.Errorf("open stream: %w", err)
+		return nil, err
 	}
 	c.stream = stream
+	c.convert = newConverter(format, cfg)
 
 	if err := c.stream.Start(); err != nil {
 		c.stream.Close()
@@ -92,30 +88,202 @@ func (c *capture) process(in []int16) {
 	default:
 	}
 
-	bytesData := utils.Int16ToBytes(in)
+	// Wake word spotting and the VAD run in the pipeline
+	bytesData := utils.Int16ToBytes(c.convert.process(in))
 	c.pipeline.FeedAudio(bytesData)
+}
+
+// Record captures audio from the configured input device for the given
+// duration, or until ctx is cancelled, and returns the samples at the
+// configured sample rate and channel count
+func Record(ctx context.Context, cfg config.AudioConfig, duration time.Duration) ([]int16, error) {
+	if cfg.SampleRate == 0 {
+		cfg.SampleRate = 16000
+	}
+	if cfg.Channels == 0 {
+		cfg.Channels = 1
+	}
+	if cfg.ChunkSize == 0 {
+		cfg.ChunkSize = 1024
+	}
+
+	if err := portaudio.Initialize(); err != nil {
+		return nil, fmt.Errorf("portaudio init: %w", err)
+	}
+	defer portaudio.Terminate()
+
+	var (
+		mu      sync.Mutex
+		samples []int16
+	)
+	stream, format, err := openStream(cfg, func(in []int16) {
+		mu.Lock()
+		samples = append(samples, in...)
+		mu.Unlock()
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	if err := stream.Start(); err != nil {
+		return nil, fmt.Errorf("start stream: %w", err)
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+
+	if err := stream.Stop(); err != nil {
+		return nil, fmt.Errorf("stop stream: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	convert := newConverter(format, cfg)
+	return append(convert.process(samples), convert.flush()...), nil
+}
+
+// ListDevices returns the audio input devices
+func ListDevices() ([]Device, error) {
+	if err := portaudio.Initialize(); err != nil {
+		return nil, fmt.Errorf("portaudio init: %w", err)
+	}
+	defer portaudio.Terminate()
+
+	devices, _, err := inputDevices()
+	return devices, err
+}
+
+// inputDevices lists the input devices, along with PortAudio's info about
+// them by index; PortAudio must be initialized
+func inputDevices() ([]Device, map[int]*portaudio.DeviceInfo, error) {
+	infos, err := portaudio.Devices()
+	if err != nil {
+		return nil, nil, fmt.Errorf("list devices: %w", err)
+	}
+
+	defaultIndex := -1
+	if def, err := portaudio.DefaultInputDevice(); err == nil {
+		defaultIndex = def.Index
+	}
+
+	var devices []Device
+	byIndex := make(map[int]*portaudio.DeviceInfo)
+	for _, info := range infos {
+		if info.MaxInputChannels < 1 {
+			continue
+		}
+		d := Device{
+			Index:             info.Index,
+			Name:              info.Name,
+			InputChannels:     info.MaxInputChannels,
+			DefaultSampleRate: info.DefaultSampleRate,
+			Default:           info.Index == defaultIndex,
+		}
+		if info.HostApi != nil {
+			d.HostAPI = info.HostApi.Name
+		}
+		devices = append(devices, d)
+		byIndex[info.Index] = info
+	}
+	return devices, byIndex, nil
+}
+
+// streamFormat is the sample rate and channel count a stream captures at
+type streamFormat struct {
+	sampleRate int
+	channels   int
+}
+
+// openStream opens an input stream on the device selected by cfg.Device,
+// in the configured format if the device supports it and in its own
+// otherwise; PortAudio must be initialized
+func openStream(cfg config.AudioConfig, process func([]int16)) (*portaudio.Stream, streamFormat, error) {
+	devices, infos, err := inputDevices()
+	if err != nil {
+		return nil, streamFormat{}, err
+	}
+	device, err := SelectDevice(devices, cfg.Device)
+	if err != nil {
+		return nil, streamFormat{}, err
+	}
+	if device.InputChannels < cfg.Channels {
+		return nil, streamFormat{}, fmt.Errorf("input device %q has %d channels, %d configured", device.Name, device.InputChannels, cfg.Channels)
+	}
+
+	params := portaudio.LowLatencyParameters(infos[device.Index], nil)
+	params.Input.Channels = cfg.Channels
+	params.SampleRate = float64(cfg.SampleRate)
+	if portaudio.IsFormatSupported(params, process) != nil {
+		// Many devices only capture at their own rate, some only in stereo;
+		// the audio is converted to the configured format as it arrives
+		params.SampleRate = device.DefaultSampleRate
+		if portaudio.IsFormatSupported(params, process) != nil {
+			params.Input.Channels = min(2, device.InputChannels)
+		}
+	}
+	format := streamFormat{sampleRate: int(params.SampleRate), channels: params.Input.Channels}
+
+	// Keep the chunk duration the configured one
+	params.FramesPerBuffer = cfg.ChunkSize * format.sampleRate / cfg.SampleRate
+
+	stream, err := portaudio.OpenStream(params, process)
+	if err != nil {
+		return nil, streamFormat{}, fmt.Errorf("open stream on %q at %d Hz, %d channels: %w", device.Name, format.sampleRate, format.channels, err)
+	}
+	if format.sampleRate != cfg.SampleRate || format.channels != cfg.Channels {
+		log := logger.Component("audio")
+		log.Info().
+			Int("sample_rate", format.sampleRate).
+			Int("channels", format.channels).
+			Msg("Device doesn't support the configured format, converting from its own")
+	}
+	return stream, format, nil
+}
 
-	// NOTE: Wake word detection via continuous chunk transcription disabled
-	// Reason: Whisper needs significant audio length (30+ seconds) to work correctly
-	// Instead, we now use the pipeline's recordUntilSilence() to collect audio
-	// The pipeline will transcribe the complete recording, not individual chunks
+// converter turns audio captured in a stream's format into the configured
+// one: channels are downmixed to mono and the rate is resampled
+type converter struct {
+	downmix   int // Channels to downmix, 0 to keep them
+	resampler *utils.Resampler
+}
 
-	// Fallback: Keep STT nil check to avoid nil pointer dereference
-	_ = c.stt
-	_ = c.onWake
+func newConverter(format streamFormat, cfg config.AudioConfig) *converter {
+	c := &converter{}
+	channels := format.channels
+	if channels != cfg.Channels {
+		// The stream only has more channels than configured when the
+		// configuration is mono
+		c.downmix = channels
+		channels = 1
+	}
+	if format.sampleRate != cfg.SampleRate {
+		c.resampler = utils.NewResampler(format.sampleRate, cfg.SampleRate, channels)
+	}
+	return c
 }
 
-func detectSpeech(samples []int16) bool {
-	var sum float64
-	for _, s := range samples {
-		sum += float64(s * s)
+func (c *converter) process(samples []int16) []int16 {
+	if c.downmix > 0 {
+		samples = utils.Downmix(samples, c.downmix)
 	}
-	rms := sum / float64(len(samples))
-	// Much lower threshold to allow quiet speech detection
-	// The pipeline will apply its own VAD sensitivity thresholds
-	return rms > 100
+	if c.resampler != nil {
+		samples = c.resampler.Process(samples)
+	}
+	return samples
 }
 
-func containsWakeWord(text string) bool {
-	return utils.ContainsIgnoreCase(text, "jarvis")
+// flush returns the audio the resampler holds back at the end of a stream
+func (c *converter) flush() []int16 {
+	if c.resampler == nil {
+		return nil
+	}
+	return c.resampler.Flush()
 }
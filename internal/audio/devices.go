@@ -0,0 +1,89 @@
+package audio
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultDevice is the audio.device value that selects the system's
+// default input
+const DefaultDevice = "default"
+
+// Device describes an audio input device
+type Device struct {
+	Index             int // Position in the system's device list, usable as audio.device
+	Name              string
+	HostAPI           string // Audio backend: MME, WASAPI, ALSA, Core Audio...
+	InputChannels     int
+	DefaultSampleRate float64
+	Default           bool // The system's default input
+}
+
+// SelectDevice finds the input device audio.device refers to: "default"
+// (or empty), an index from the list, or a name. Names match regardless of
+// case, whole or as part of a single device's name; a device listed under
+// several host APIs is taken from the default input's one.
+func SelectDevice(devices []Device, spec string) (Device, error) {
+	spec = strings.TrimSpace(spec)
+
+	if spec == "" || strings.EqualFold(spec, DefaultDevice) {
+		for _, d := range devices {
+			if d.Default {
+				return d, nil
+			}
+		}
+		return Device{}, fmt.Errorf("no default input device, set audio.device (run 'jarvis devices' to list them)")
+	}
+
+	if index, err := strconv.Atoi(spec); err == nil {
+		for _, d := range devices {
+			if d.Index == index {
+				return d, nil
+			}
+		}
+		return Device{}, fmt.Errorf("no input device with index %d (run 'jarvis devices' to list them)", index)
+	}
+
+	var exact, partial []Device
+	for _, d := range devices {
+		switch {
+		case strings.EqualFold(d.Name, spec):
+			exact = append(exact, d)
+		case strings.Contains(strings.ToLower(d.Name), strings.ToLower(spec)):
+			partial = append(partial, d)
+		}
+	}
+	matches := exact
+	if len(matches) == 0 {
+		matches = partial
+	}
+	if len(matches) == 0 {
+		return Device{}, fmt.Errorf("input device %q not found (run 'jarvis devices' to list them)", spec)
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, d := range matches {
+		if key := strings.ToLower(d.Name); !seen[key] {
+			seen[key] = true
+			names = append(names, fmt.Sprintf("%q", d.Name))
+		}
+	}
+	if len(names) > 1 {
+		return Device{}, fmt.Errorf("input device %q is ambiguous, it matches %s; use the full name or the index", spec, strings.Join(names, ", "))
+	}
+
+	// Same device under several host APIs: prefer the default input's one
+	for _, d := range devices {
+		if !d.Default {
+			continue
+		}
+		for _, m := range matches {
+			if m.HostAPI == d.HostAPI {
+				return m, nil
+			}
+		}
+	}
+	return matches[0], nil
+}
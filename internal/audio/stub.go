@@ -5,17 +5,27 @@ package audio
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/jarvisstreamer/jarvis/internal/config"
 	"github.com/jarvisstreamer/jarvis/internal/pipeline"
-	"github.com/jarvisstreamer/jarvis/internal/stt"
 )
 
 type capture struct{}
 
 // Start returns a stub error when PortAudio is disabled.
-func Start(ctx context.Context, cfg config.AudioConfig, pip *pipeline.Pipeline, sttProvider stt.Provider, onWake func()) (*capture, error) {
+func Start(ctx context.Context, cfg config.AudioConfig, pip *pipeline.Pipeline) (*capture, error) {
 	return nil, fmt.Errorf("PortAudio build tag is required for audio capture")
 }
 
 func (c *capture) Stop() {}
+
+// Record returns a stub error when PortAudio is disabled.
+func Record(ctx context.Context, cfg config.AudioConfig, duration time.Duration) ([]int16, error) {
+	return nil, fmt.Errorf("PortAudio build tag is required for audio capture")
+}
+
+// ListDevices returns a stub error when PortAudio is disabled.
+func ListDevices() ([]Device, error) {
+	return nil, fmt.Errorf("PortAudio build tag is required for audio capture")
+}
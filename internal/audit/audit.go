@@ -0,0 +1,276 @@
+// Package audit records every interaction (transcript, action, results,
+// reply, timings and providers) as JSONL so it can be queried and replayed
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/jarvisstreamer/jarvis/internal/events"
+	"github.com/jarvisstreamer/jarvis/internal/llm"
+	"github.com/jarvisstreamer/jarvis/pkg/logger"
+	"github.com/rs/zerolog"
+)
+
+// FileName is the audit log inside General.DataDir
+const FileName = "audit.jsonl"
+
+const (
+	// replyGrace is how long an answered interaction waits for its speech
+	// before it is written
+	replyGrace = 2 * time.Second
+
+	// idleTimeout closes an interaction that never got a reply
+	idleTimeout = time.Minute
+
+	// pendingMaxAge drops timings (STT) that no transcript claimed
+	pendingMaxAge = time.Minute
+)
+
+// Entry is one recorded interaction
+type Entry struct {
+	ID         int             `json:"id"`
+	Time       time.Time       `json:"time"`
+	Source     string          `json:"source,omitempty"` // "voice", "text", "api", "schedule", "replay"...
+	Transcript string          `json:"transcript,omitempty"`
+	Action     *events.Action  `json:"action,omitempty"`
+	Results    []events.Result `json:"results,omitempty"`
+	Reply      string          `json:"reply,omitempty"`
+	Errors     []events.Error  `json:"errors,omitempty"`
+	Timings    []events.Timing `json:"timings,omitempty"`
+}
+
+// Replayable returns the recorded action, if the entry ran one: an action
+// that was refused, held for confirmation or failed entirely has no
+// successful result and is not replayed
+func (e *Entry) Replayable() (llm.Action, bool) {
+	if e.Action == nil || e.Action.Action == "" || e.Action.Action == "none" {
+		return llm.Action{}, false
+	}
+	ran := false
+	for _, r := range e.Results {
+		ran = ran || r.Success
+	}
+	if !ran {
+		return llm.Action{}, false
+	}
+
+	action := llm.Action{
+		Action:  e.Action.Action,
+		Params:  e.Action.Params,
+		Reply:   e.Action.Reply,
+		OnError: e.Action.OnError,
+	}
+	for _, step := range e.Action.Plan {
+		action.Steps = append(action.Steps, llm.Action{Action: step.Action, Params: step.Params, Reply: step.Reply})
+	}
+	return action, true
+}
+
+// Path returns the audit log location inside a data directory
+func Path(dataDir string) string {
+	return filepath.Join(dataDir, FileName)
+}
+
+// Recorder groups the events of each interaction into an Entry and appends
+// it to the audit log
+type Recorder struct {
+	file *os.File
+	log  zerolog.Logger
+
+	mu            sync.Mutex
+	nextID        int
+	cur           *Entry
+	pending       []events.Envelope
+	speakingSince time.Time
+	timer         *time.Timer
+
+	unsubscribe func()
+}
+
+// Open starts recording to the audit log in dataDir, continuing its numbering
+func Open(dataDir string) (*Recorder, error) {
+	path := Path(dataDir)
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	last, err := lastID(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	r := &Recorder{
+		file:   file,
+		log:    logger.Component("audit"),
+		nextID: last + 1,
+	}
+	r.unsubscribe = events.Subscribe(r.handle,
+		events.KindTranscript, events.KindAction, events.KindResult, events.KindReply,
+		events.KindTTSStarted, events.KindTTSStopped, events.KindError, events.KindTiming,
+	)
+
+	return r, nil
+}
+
+// Close writes the interaction in progress and closes the log
+func (r *Recorder) Close() error {
+	r.unsubscribe()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flushLocked()
+	return r.file.Close()
+}
+
+// handle adds an event to the current interaction, opening or closing
+// interactions as needed
+func (r *Recorder) handle(env events.Envelope) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch e := env.Event.(type) {
+	case events.Transcript:
+		r.flushLocked()
+		r.open(env.Time, e.Source).Transcript = e.Text
+
+	case events.Timing:
+		if r.cur == nil || e.Stage == "stt" {
+			// STT timings arrive before the transcript they belong to
+			r.pending = append(r.pending, env)
+			if len(r.pending) > 1 && env.Time.Sub(r.pending[0].Time) > pendingMaxAge {
+				r.pending = r.pending[1:]
+			}
+			return
+		}
+		r.cur.Timings = append(r.cur.Timings, e)
+
+	case events.Action:
+		if r.cur == nil || r.cur.Action != nil {
+			r.flushLocked()
+			r.open(env.Time, e.Source)
+		}
+		r.cur.Action = &e
+
+	case events.Result:
+		cur := r.current(env.Time)
+		cur.Results = append(cur.Results, e)
+
+	case events.Reply:
+		cur := r.current(env.Time)
+		if cur.Reply != "" {
+			cur.Reply += " "
+		}
+		cur.Reply += e.Text
+
+	case events.Error:
+		cur := r.current(env.Time)
+		cur.Errors = append(cur.Errors, e)
+
+	case events.TTSStarted:
+		if r.cur == nil {
+			return
+		}
+		r.speakingSince = env.Time
+
+	case events.TTSStopped:
+		if r.cur == nil || r.speakingSince.IsZero() {
+			return
+		}
+		r.cur.Timings = append(r.cur.Timings, events.Timing{
+			Stage:    "tts",
+			Provider: e.Provider,
+			Duration: env.Time.Sub(r.speakingSince),
+		})
+		if e.Error != "" {
+			r.cur.Errors = append(r.cur.Errors, events.Error{Stage: "tts", Error: e.Error})
+		}
+		r.speakingSince = time.Time{}
+		if r.cur.Reply != "" {
+			r.flushLocked()
+			return
+		}
+	}
+
+	r.scheduleFlush()
+}
+
+// open starts a new interaction, claiming recent pending timings
+func (r *Recorder) open(at time.Time, source string) *Entry {
+	r.cur = &Entry{Time: at, Source: source}
+	for _, env := range r.pending {
+		if at.Sub(env.Time) <= pendingMaxAge {
+			r.cur.Timings = append(r.cur.Timings, env.Event.(events.Timing))
+		}
+	}
+	r.pending = nil
+	return r.cur
+}
+
+// current returns the open interaction, opening one for events that happen
+// on their own (scheduled reminders, background failures)
+func (r *Recorder) current(at time.Time) *Entry {
+	if r.cur == nil {
+		r.open(at, "")
+	}
+	return r.cur
+}
+
+// scheduleFlush writes the current interaction once it has gone quiet
+func (r *Recorder) scheduleFlush() {
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	if r.cur == nil {
+		return
+	}
+
+	wait := idleTimeout
+	if r.cur.Reply != "" && r.speakingSince.IsZero() {
+		wait = replyGrace
+	}
+
+	entry := r.cur
+	r.timer = time.AfterFunc(wait, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.cur == entry {
+			r.flushLocked()
+		}
+	})
+}
+
+// flushLocked appends the current interaction to the log; r.mu must be held
+func (r *Recorder) flushLocked() {
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+	entry := r.cur
+	r.cur = nil
+	r.speakingSince = time.Time{}
+	if entry == nil {
+		return
+	}
+
+	entry.ID = r.nextID
+	r.nextID++
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		r.log.Warn().Err(err).Int("id", entry.ID).Msg("Failed to encode audit entry")
+		return
+	}
+	if _, err := r.file.Write(append(data, '\n')); err != nil {
+		r.log.Warn().Err(err).Int("id", entry.ID).Msg("Failed to write audit entry")
+	}
+}
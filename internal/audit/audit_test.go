@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/jarvisstreamer/jarvis/internal/events"
+)
+
+func TestReplayable(t *testing.T) {
+	ban := &events.Action{Action: "twitch.ban", Params: map[string]interface{}{"user": "troll"}}
+	plan := &events.Action{Action: "plan", OnError: "continue", Plan: []events.PlanStep{
+		{Action: "obs.scene", Params: map[string]interface{}{"scene": "BRB"}},
+		{Action: "music.pause"},
+	}}
+
+	tests := []struct {
+		name  string
+		entry Entry
+		want  bool
+	}{
+		{"ran", Entry{Action: ban, Results: []events.Result{{Action: "twitch.ban", Success: true}}}, true},
+		{"held or refused", Entry{Action: ban, Reply: "¿Seguro que quieres banear a troll?"}, false},
+		{"failed", Entry{Action: ban, Results: []events.Result{{Action: "twitch.ban", Error: "offline"}}}, false},
+		{"plan partly ran", Entry{Action: plan, Results: []events.Result{
+			{Action: "obs.scene", Error: "offline"},
+			{Action: "music.pause", Success: true},
+		}}, true},
+		{"no action", Entry{Transcript: "hola", Reply: "Hola."}, false},
+		{"conversation", Entry{Action: &events.Action{Action: "none"}}, false},
+	}
+	for _, tt := range tests {
+		action, ok := tt.entry.Replayable()
+		if ok != tt.want {
+			t.Errorf("%s: Replayable = %v, want %v", tt.name, ok, tt.want)
+		}
+		if ok && action.Action != tt.entry.Action.Action {
+			t.Errorf("%s: replays %q, want %q", tt.name, action.Action, tt.entry.Action.Action)
+		}
+	}
+
+	e := Entry{Action: plan, Results: []events.Result{{Success: true}}}
+	action, _ := e.Replayable()
+	if len(action.Steps) != 2 || action.Steps[0].Params["scene"] != "BRB" || action.OnError != "continue" {
+		t.Errorf("replayed plan = %+v", action)
+	}
+}
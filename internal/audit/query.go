@@ -0,0 +1,107 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// maxLineBytes bounds a single entry, so a corrupt file can't exhaust memory
+const maxLineBytes = 1 << 20
+
+// Filter selects entries of the audit log; zero fields match everything
+type Filter struct {
+	Since  time.Time
+	Until  time.Time
+	Action string // Action name or "prefix.*", matched against the action and every result
+	FromID int
+	ToID   int
+}
+
+// Match returns true if the entry passes the filter
+func (f Filter) Match(e Entry) bool {
+	switch {
+	case !f.Since.IsZero() && e.Time.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && e.Time.After(f.Until):
+		return false
+	case f.FromID > 0 && e.ID < f.FromID:
+		return false
+	case f.ToID > 0 && e.ID > f.ToID:
+		return false
+	}
+
+	if f.Action == "" {
+		return true
+	}
+	if e.Action != nil && matchAction(f.Action, e.Action.Action) {
+		return true
+	}
+	for _, r := range e.Results {
+		if matchAction(f.Action, r.Action) {
+			return true
+		}
+	}
+	return false
+}
+
+// Read returns the entries of the audit log that match the filter, oldest
+// first. A missing log has no entries; unreadable lines are skipped.
+func Read(path string, f Filter) ([]Entry, error) {
+	var entries []Entry
+	err := scan(path, func(e Entry) {
+		if f.Match(e) {
+			entries = append(entries, e)
+		}
+	})
+	return entries, err
+}
+
+// lastID returns the highest entry ID in the log
+func lastID(path string) (int, error) {
+	last := 0
+	err := scan(path, func(e Entry) {
+		if e.ID > last {
+			last = e.ID
+		}
+	})
+	return last, err
+}
+
+// scan calls fn for every valid entry of the log
+func scan(path string, fn func(Entry)) error {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64<<10), maxLineBytes)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		fn(e)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return nil
+}
+
+// matchAction matches an action against a name or a "prefix.*" pattern
+func matchAction(pattern, action string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(action, prefix)
+	}
+	return pattern == action
+}
@@ -0,0 +1,100 @@
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarvisstreamer/jarvis/internal/events"
+)
+
+func TestFilterMatch(t *testing.T) {
+	at := time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC)
+	entry := Entry{
+		ID:     7,
+		Time:   at,
+		Action: &events.Action{Action: "macro.run"},
+		Results: []events.Result{
+			{Action: "obs.scene"},
+			{Action: "music.pause"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"empty", Filter{}, true},
+		{"since before", Filter{Since: at.Add(-time.Minute)}, true},
+		{"since after", Filter{Since: at.Add(time.Minute)}, false},
+		{"since exact", Filter{Since: at}, true},
+		{"until after", Filter{Until: at.Add(time.Minute)}, true},
+		{"until before", Filter{Until: at.Add(-time.Minute)}, false},
+		{"ids around", Filter{FromID: 7, ToID: 7}, true},
+		{"from later id", Filter{FromID: 8}, false},
+		{"to earlier id", Filter{ToID: 6}, false},
+		{"action", Filter{Action: "macro.run"}, true},
+		{"result action", Filter{Action: "music.pause"}, true},
+		{"prefix", Filter{Action: "obs.*"}, true},
+		{"other action", Filter{Action: "twitch.clip"}, false},
+		{"other prefix", Filter{Action: "twitch.*"}, false},
+		{"name is not a prefix", Filter{Action: "obs"}, false},
+		{"action out of range", Filter{Action: "obs.*", ToID: 6}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Match(entry); got != tt.want {
+			t.Errorf("%s: Match = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Entries without an action only match action filters through results
+	if (Filter{Action: "obs.*"}).Match(Entry{ID: 1, Time: at}) {
+		t.Error("entry without action or results matched an action filter")
+	}
+}
+
+func TestRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+
+	// A missing log has no entries
+	entries, err := Read(path, Filter{})
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Read(missing) = %v, %v", entries, err)
+	}
+
+	at := time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC)
+	var lines []string
+	for i, action := range []string{"obs.scene", "music.pause", "obs.mute"} {
+		line, err := json.Marshal(Entry{ID: i + 1, Time: at.Add(time.Duration(i) * time.Minute), Action: &events.Action{Action: action}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, string(line))
+	}
+	// A line cut short by a crash is skipped
+	lines = append(lines[:2], `{"id": 9, "time": "2024-05`, lines[2])
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err = Read(path, Filter{Action: "obs.*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].ID != 1 || entries[1].ID != 3 {
+		t.Errorf("Read(obs.*) = %+v, want entries 1 and 3", entries)
+	}
+
+	entries, err = Read(path, Filter{Since: at.Add(30 * time.Second)})
+	if err != nil || len(entries) != 2 || entries[0].ID != 2 {
+		t.Errorf("Read(since) = %+v, %v; want entries 2 and 3", entries, err)
+	}
+
+	if id, err := lastID(path); err != nil || id != 3 {
+		t.Errorf("lastID = %d, %v; want 3", id, err)
+	}
+}
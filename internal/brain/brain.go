@@ -3,34 +3,69 @@ package brain
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"regexp"
-	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/jarvisstreamer/jarvis/internal/config"
+	"github.com/jarvisstreamer/jarvis/internal/events"
 	"github.com/jarvisstreamer/jarvis/internal/executor"
+	"github.com/jarvisstreamer/jarvis/internal/intent"
 	"github.com/jarvisstreamer/jarvis/internal/llm"
 	"github.com/jarvisstreamer/jarvis/internal/tts"
 	"github.com/jarvisstreamer/jarvis/pkg/logger"
 	"github.com/rs/zerolog"
 )
 
+// liveContextTimeout bounds how long gathering OBS/Twitch state may delay a command
+const liveContextTimeout = 2 * time.Second
+
 // Brain is the central orchestrator that processes commands
 type Brain struct {
+	cfg         *config.Config
 	llmProvider llm.Provider
 	ttsProvider tts.Provider
 	registry    *executor.Registry
+	memory      *memory
+	confirm     *confirmations
+	intents     *intent.Matcher
+	replies     *replies
+	undo        undoStack
 	log         zerolog.Logger
+
+	speechMu   sync.Mutex
+	speeches   map[int]context.CancelFunc
+	nextSpeech int
 }
 
 // New creates a new Brain instance
-func New(llmProvider llm.Provider, ttsProvider tts.Provider) *Brain {
-	return &Brain{
+func New(cfg *config.Config, llmProvider llm.Provider, ttsProvider tts.Provider) *Brain {
+	b := &Brain{
+		cfg:         cfg,
 		llmProvider: llmProvider,
 		ttsProvider: ttsProvider,
 		registry:    executor.NewRegistry(),
+		memory:      newMemory(cfg.Brain.Memory),
+		confirm:     newConfirmations(cfg.Brain.Confirm, cfg.Macros),
+		replies:     newReplies(cfg.Brain.Replies),
+		speeches:    make(map[int]context.CancelFunc),
 		log:         logger.Component("brain"),
 	}
+	b.registry.SetPolicy(executor.NewPolicy(cfg.Policy))
+
+	// Macro phrases are matched like user rules
+	intentsCfg := cfg.Brain.Intents
+	intentsCfg.Rules = append(intent.MacroRules(cfg.Macros), intentsCfg.Rules...)
+
+	intents, err := intent.NewMatcher(intentsCfg)
+	if err != nil {
+		b.log.Warn().Err(err).Msg("Some intent rules are invalid and were skipped")
+	}
+	b.intents = intents
+
+	return b
 }
 
 // RegisterExecutor registers an action executor
@@ -42,21 +77,83 @@ func (b *Brain) RegisterExecutor(exec executor.Executor) {
 		Msg("Registered executor")
 }
 
-// ProcessCommand processes a voice command and returns the response
-func (b *Brain) ProcessCommand(ctx context.Context, text string) (string, error) {
+// Registry returns the executor registry, for executors that run other actions
+func (b *Brain) Registry() *executor.Registry {
+	return b.registry
+}
+
+// ProcessCommand interprets a command, executes the resulting action once
+// and returns the outcome; speak it with SpeakResponse
+func (b *Brain) ProcessCommand(ctx context.Context, text string) (*Response, error) {
 	b.log.Info().Str("input", text).Msg("Processing command")
 
+	start := time.Now()
+	ctx, resp := withResponse(ctx)
+	reply, err := b.processCommand(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	return b.finish(resp, reply, start), nil
+}
+
+// processCommand interprets a command and executes the resulting action
+func (b *Brain) processCommand(ctx context.Context, text string) (string, error) {
+	session := SessionFromContext(ctx)
+	start := time.Now()
+	resp := responseFrom(ctx)
+
+	// Answer a pending confirmation before anything else. Anything but yes
+	// or no asks again: running it as a new command would drop the pending
+	// action without the user noticing.
+	switch answer := parseConfirmation(text); answer {
+	case answerYes, answerNo:
+		pending, ok := b.confirm.take(session)
+		if !ok {
+			break
+		}
+		if answer == answerNo {
+			b.log.Info().Str("action", pending.Action).Msg("Action cancelled by user")
+			return "Vale, cancelado.", nil
+		}
+		b.log.Info().Str("action", pending.Action).Msg("Action confirmed")
+		interpreted(ctx, pending, "confirmation")
+		return b.execute(ctx, pending)
+	default:
+		if pending, ok := b.confirm.peek(session); ok {
+			b.log.Info().Str("action", pending.Action).Msg("Confirmation not answered, asking again")
+			return fmt.Sprintf("No te he entendido. %s Responde sí o no.", b.confirm.prompt(pending)), nil
+		}
+	}
+
+	// Simple commands skip the LLM round trip
+	if action, ok := b.intents.Match(text); ok {
+		b.log.Debug().
+			Str("action", action.Action).
+			Interface("params", action.Params).
+			Msg("Matched intent rule")
+		resp.Timings.Interpret = time.Since(start)
+		b.memory.add(session, text, action)
+		return b.dispatch(ctx, session, action, "intent")
+	}
+
 	if b.llmProvider == nil || !b.llmProvider.IsAvailable(ctx) {
 		b.log.Warn().Msg("LLM provider is not available, skipping command")
 		return "No hay ningún proveedor de IA disponible. Revisa tu configuración o prueba más tarde.", nil
 	}
 
-	// Get action from LLM
-	action, err := b.llmProvider.Complete(ctx, text)
+	// Get action from LLM, including the recent turns of this session
+	messages := []llm.Message{{Role: llm.RoleSystem, Content: b.systemPrompt(ctx)}}
+	messages = append(messages, b.memory.history(session)...)
+	messages = append(messages, llm.Message{Role: llm.RoleUser, Content: text})
+
+	action, err := b.complete(ctx, messages)
 	if err != nil {
 		b.log.Error().Err(err).Msg("LLM completion failed")
+		events.Publish(events.Error{Stage: "llm", Error: err.Error()})
 		return "", fmt.Errorf("failed to interpret command: %w", err)
 	}
+	resp.Timings.Interpret = time.Since(start)
+	b.memory.add(session, text, action)
 
 	b.log.Debug().
 		Str("action", action.Action).
@@ -64,38 +161,221 @@ func (b *Brain) ProcessCommand(ctx context.Context, text string) (string, error)
 		Str("reply", action.Reply).
 		Msg("LLM response")
 
-	// Handle special actions
-	if action.Action == "none" || action.Action == "" {
-		// Just respond without executing
-		return action.Reply, nil
+	return b.dispatch(ctx, session, action, "llm")
+}
+
+// ExecuteAction runs an already interpreted action, as sent by the control API
+// or replayed from the audit log; source tags its events ("api", "replay").
+// Params are validated and the policy applies, but no confirmation is asked: the caller chose the action explicitly.
+func (b *Brain) ExecuteAction(ctx context.Context, action llm.Action, source string) (*Response, error) {
+	b.log.Info().Str("action", action.Action).Str("source", source).Msg("Executing external action")
+
+	start := time.Now()
+	ctx, resp := withResponse(ctx)
+
+	validated, err := b.validate(action)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.checkPolicy(ctx, validated); err != nil {
+		return nil, err
 	}
 
-	if action.Action == "system.status" {
-		return b.handleStatus(ctx, action)
+	interpreted(ctx, validated, source)
+	reply, err := b.execute(ctx, validated)
+	if err != nil {
+		return nil, err
 	}
+	return b.finish(resp, reply, start), nil
+}
 
-	if action.Action == "system.help" {
-		return b.handleHelp(ctx, action)
+// systemPrompt builds the prompt from the available executors and their live state
+func (b *Brain) systemPrompt(ctx context.Context) string {
+	liveCtx, cancel := context.WithTimeout(ctx, liveContextTimeout)
+	defer cancel()
+
+	return llm.BuildSystemPrompt(b.cfg.General.Language, b.registry.PromptActions(), b.registry.LiveContext(liveCtx))
+}
+
+// dispatch executes an interpreted action, or holds it when it needs
+// confirmation. Only an action that is going to run is announced, so the
+// audit log and the overlay never show one that was refused or held.
+func (b *Brain) dispatch(ctx context.Context, session string, action llm.Action, source string) (string, error) {
+	responseFrom(ctx).setAction(action, source)
+
+	// Reject bad params before asking for confirmation or running any step
+	validated, err := b.validate(action)
+	var verr *executor.ValidationError
+	if errors.As(err, &verr) {
+		b.log.Warn().
+			Str("action", verr.Action).
+			Str("param", verr.Param).
+			Msg("Invalid action params")
+		return verr.Reason, nil
+	}
+	action = validated
+
+	// Don't ask to confirm something the policy will block anyway
+	if err := b.checkPolicy(ctx, action); err != nil {
+		b.log.Warn().Err(err).Str("action", action.Action).Msg("Action blocked by policy")
+		return err.Error(), nil
+	}
+
+	if b.confirm.required(action) {
+		b.log.Info().Str("action", action.Action).Msg("Action requires confirmation")
+		b.confirm.hold(session, action)
+		return b.confirm.prompt(action), nil
+	}
+
+	interpreted(ctx, action, source)
+	return b.execute(ctx, action)
+}
+
+// validate checks the params of an action, or of every step of a plan,
+// against the executor schemas and returns it with coerced params
+func (b *Brain) validate(action llm.Action) (llm.Action, error) {
+	if action.IsPlan() {
+		steps := make([]llm.Action, len(action.Steps))
+		for i, step := range action.Steps {
+			validated, err := b.validate(step)
+			if err != nil {
+				return action, err
+			}
+			steps[i] = validated
+		}
+		action.Steps = steps
+		return action, nil
 	}
 
-	if action.Action == "calc" {
+	validated, err := b.registry.Validate(action)
+	var verr *executor.ValidationError
+	if errors.As(err, &verr) {
+		return action, err
+	}
+	if err != nil {
+		// Built-in or unknown actions have no schema; execution reports them
+		return action, nil
+	}
+	return validated, nil
+}
+
+// checkPolicy returns the *executor.PolicyError of the first blocked step
+func (b *Brain) checkPolicy(ctx context.Context, action llm.Action) error {
+	if !action.IsPlan() {
+		return b.registry.CheckPolicy(ctx, action)
+	}
+	for _, step := range action.Steps {
+		if err := b.registry.CheckPolicy(ctx, step); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execute runs a single action or a plan and returns the reply to speak.
+// Whatever it changes can be reverted as a whole by system.undo.
+func (b *Brain) execute(ctx context.Context, action llm.Action) (string, error) {
+	ctx, undo := withUndoCollector(ctx)
+	defer b.undo.push(undo)
+
+	resp := responseFrom(ctx)
+	resp.setAction(action, "")
+	start := time.Now()
+	defer func() {
+		if resp != nil {
+			resp.Timings.Execute = time.Since(start)
+		}
+	}()
+
+	if action.IsPlan() {
+		return b.executePlan(ctx, action)
+	}
+
+	reply, err := b.executeAction(ctx, action)
+	var perr *executor.PolicyError
+	if errors.As(err, &perr) {
+		// The LLM's reply would announce something that didn't happen
+		return perr.Reason, nil
+	}
+	if err != nil {
+		return b.FailureReply(action, err), nil
+	}
+
+	return reply, nil
+}
+
+// FailureReply returns what to say about a failed action: the spoken reason
+// of a policy or validation error, or the action's failure template.
+// Executor errors are technical and in English; they only go to the log.
+func (b *Brain) FailureReply(action llm.Action, err error) string {
+	var perr *executor.PolicyError
+	if errors.As(err, &perr) {
+		return perr.Reason
+	}
+	var verr *executor.ValidationError
+	if errors.As(err, &verr) {
+		return verr.Reason
+	}
+	if reply, ok := b.replies.failure(action, err); ok {
+		return reply
+	}
+	return genericFailure
+}
+
+// actionLabel names an action for speech, from its spec's description
+func (b *Brain) actionLabel(action llm.Action) string {
+	spec, ok := executor.FindSpec(b.registry.GetAllSpecs(), action.Action)
+	if !ok || spec.Description == "" {
+		return action.Action
+	}
+	r := []rune(spec.Description)
+	return strings.ToLower(string(r[:1])) + string(r[1:])
+}
+
+// failedStep describes a failed action and why, for replies that list several
+func (b *Brain) failedStep(label string, action llm.Action, err error) string {
+	reason := []rune(strings.TrimRight(b.FailureReply(action, err), ".!"))
+	return fmt.Sprintf("%s (%s%s)", strings.TrimRight(label, ".!"), strings.ToLower(string(reason[:1])), string(reason[1:]))
+}
+
+// executeAction runs a single action and returns the reply to speak.
+// A non-nil error means the action did not complete.
+func (b *Brain) executeAction(ctx context.Context, action llm.Action) (string, error) {
+	// Handle special actions
+	switch action.Action {
+	case "none", "":
+		// Just respond without executing
+		return action.Reply, nil
+	case "system.status":
+		return b.handleStatus(ctx, action)
+	case "system.help":
+		return b.handleHelp(ctx, action)
+	case "system.undo":
+		return b.handleUndo(ctx, action)
+	case "calc":
 		return b.handleCalc(ctx, action)
 	}
 
 	// Execute the action
 	result, err := b.registry.Execute(ctx, action)
+
+	// A macro that failed halfway still reverts the steps it ran, even
+	// when a step was blocked by the policy
+	collectUndo(ctx, result.Undo)
+
 	if err != nil {
 		b.log.Error().Err(err).Str("action", action.Action).Msg("Action execution failed")
-		// Return the LLM's reply anyway, plus error info
-		return fmt.Sprintf("%s. Sin embargo, hubo un error: %s", action.Reply, err.Error()), nil
+		responseFrom(ctx).addResult(executor.NewErrorResult(err))
+		return "", err
 	}
+	responseFrom(ctx).addResult(result)
 
 	if !result.Success {
 		b.log.Warn().
 			Str("action", action.Action).
 			Str("error", result.Error).
 			Msg("Action failed")
-		return fmt.Sprintf("%s. Error: %s", action.Reply, result.Error), nil
+		return "", errors.New(result.Error)
 	}
 
 	b.log.Info().
@@ -103,61 +383,214 @@ func (b *Brain) ProcessCommand(ctx context.Context, text string) (string, error)
 		Str("result", result.Message).
 		Msg("Action executed successfully")
 
+	// The reply written before the action ran can't mention what it produced
+	if reply, ok := b.replies.success(action, result); ok {
+		return reply, nil
+	}
+
+	// Actions that report information (lists, lookups) answer themselves
+	if result.Reply != "" {
+		return result.Reply, nil
+	}
+
 	// Return the LLM's reply (which should be natural language)
 	return action.Reply, nil
 }
 
-// ProcessAndSpeak processes a command and speaks the response
-func (b *Brain) ProcessAndSpeak(ctx context.Context, text string) error {
-	response, err := b.ProcessCommand(ctx, text)
-	if err != nil {
-		response = "Lo siento, ocurrió un error procesando tu solicitud."
+// planStep holds the outcome of one step of a plan
+type planStep struct {
+	action  llm.Action
+	reply   string
+	failure string // Spoken description of the failure, empty if the step succeeded
+}
+
+// executePlan runs the steps of a plan in order, honoring its error policy,
+// and builds a single combined reply
+func (b *Brain) executePlan(ctx context.Context, plan llm.Action) (string, error) {
+	b.log.Info().
+		Int("steps", len(plan.Steps)).
+		Str("on_error", plan.OnError).
+		Msg("Executing plan")
+
+	var steps []planStep
+	for i, step := range plan.Steps {
+		if ctx.Err() != nil {
+			break
+		}
+
+		reply, err := b.executeAction(ctx, step)
+		outcome := planStep{action: step, reply: reply}
+		if err != nil {
+			label := step.Reply
+			if label == "" {
+				label = b.actionLabel(step)
+			}
+			outcome.failure = b.failedStep(label, step, err)
+		}
+		steps = append(steps, outcome)
+
+		if err != nil && !plan.ContinueOnError() {
+			b.log.Warn().
+				Int("step", i+1).
+				Str("action", step.Action).
+				Msg("Plan step failed, stopping plan")
+			break
+		}
+	}
+
+	return planReply(plan, steps), nil
+}
+
+// planReply combines the outcome of every executed step into one spoken reply
+func planReply(plan llm.Action, steps []planStep) string {
+	var done, failed []string
+	for _, step := range steps {
+		if step.failure != "" {
+			failed = append(failed, step.failure)
+		} else if step.reply != "" {
+			done = append(done, strings.TrimRight(step.reply, ".!"))
+		}
+	}
+	skipped := len(plan.Steps) - len(steps)
+
+	if len(failed) == 0 && skipped == 0 {
+		if plan.Reply != "" {
+			return plan.Reply
+		}
+		return strings.Join(done, ". ")
 	}
 
-	if response == "" {
+	var parts []string
+	if len(done) > 0 {
+		parts = append(parts, fmt.Sprintf("Hice %d de %d: %s.", len(steps)-len(failed), len(plan.Steps), strings.Join(done, ", ")))
+	}
+	parts = append(parts, fmt.Sprintf("Falló: %s.", strings.Join(failed, "; ")))
+	switch {
+	case skipped == 1:
+		parts = append(parts, "No ejecuté la acción restante.")
+	case skipped > 1:
+		parts = append(parts, fmt.Sprintf("No ejecuté las %d acciones restantes.", skipped))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// Speak says a response through the TTS provider, if one is available
+func (b *Brain) Speak(ctx context.Context, text string) error {
+	if b.ttsProvider == nil || text == "" {
+		return nil
+	}
+	if !b.ttsProvider.IsAvailable(ctx) {
+		b.log.Debug().Msg("TTS provider is not available, skipping speech")
 		return nil
 	}
 
-	// Speak the response (but don't fail if TTS has issues)
+	speechCtx, done := b.speechContext(ctx)
+	defer done()
+	err := b.ttsProvider.Speak(speechCtx, text)
+	if err != nil && speechCtx.Err() != nil && ctx.Err() == nil {
+		// Cut short by StopSpeaking, not a failure
+		return nil
+	}
+	return err
+}
+
+// StopSpeaking interrupts the current speech and drops the rest of the
+// reply, including sentences of a streamed reply not spoken yet
+func (b *Brain) StopSpeaking() {
+	b.speechMu.Lock()
+	for id, cancel := range b.speeches {
+		cancel()
+		delete(b.speeches, id)
+	}
+	b.speechMu.Unlock()
+
 	if b.ttsProvider != nil {
-		if b.ttsProvider.IsAvailable(ctx) {
-			if err := b.ttsProvider.Speak(ctx, response); err != nil {
-				b.log.Warn().Err(err).Msg("TTS failed, but continuing without audio")
-				// Don't return error, just warn and continue
-			}
-		} else {
-			b.log.Debug().Msg("TTS provider is not available, skipping speech")
-		}
+		b.ttsProvider.Stop()
 	}
+}
 
-	return nil
+// speechContext returns a context for playback that StopSpeaking cancels;
+// done must be called once playback ends
+func (b *Brain) speechContext(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	b.speechMu.Lock()
+	id := b.nextSpeech
+	b.nextSpeech++
+	b.speeches[id] = cancel
+	b.speechMu.Unlock()
+
+	return ctx, func() {
+		b.speechMu.Lock()
+		delete(b.speeches, id)
+		b.speechMu.Unlock()
+		cancel()
+	}
+}
+
+// ComponentStatus reports whether a provider or executor can be used
+type ComponentStatus struct {
+	Name      string   `json:"name"`
+	Available bool     `json:"available"`
+	Actions   []string `json:"actions,omitempty"`
+}
+
+// Status is a health snapshot of the brain's providers and executors
+type Status struct {
+	LLM       *ComponentStatus  `json:"llm"`
+	TTS       *ComponentStatus  `json:"tts"`
+	Executors []ComponentStatus `json:"executors"`
+}
+
+// Status checks the providers and executors
+func (b *Brain) Status(ctx context.Context) Status {
+	var status Status
+
+	if b.llmProvider != nil {
+		status.LLM = &ComponentStatus{Name: b.llmProvider.Name(), Available: b.llmProvider.IsAvailable(ctx)}
+	}
+	if b.ttsProvider != nil {
+		status.TTS = &ComponentStatus{Name: b.ttsProvider.Name(), Available: b.ttsProvider.IsAvailable(ctx)}
+	}
+	for _, exec := range b.registry.Executors() {
+		status.Executors = append(status.Executors, ComponentStatus{
+			Name:      exec.Name(),
+			Available: exec.IsAvailable(),
+			Actions:   exec.SupportedActions(),
+		})
+	}
+
+	return status
 }
 
 // handleStatus returns the system status
 func (b *Brain) handleStatus(ctx context.Context, action llm.Action) (string, error) {
+	health := b.Status(ctx)
 	var status []string
 
 	// Check LLM
-	if b.llmProvider.IsAvailable(ctx) {
-		status = append(status, fmt.Sprintf("LLM %s: activo", b.llmProvider.Name()))
+	if health.LLM == nil {
+		status = append(status, "LLM: no disponible")
+	} else if health.LLM.Available {
+		status = append(status, fmt.Sprintf("LLM %s: activo", health.LLM.Name))
 	} else {
-		status = append(status, fmt.Sprintf("LLM %s: no disponible", b.llmProvider.Name()))
+		status = append(status, fmt.Sprintf("LLM %s: no disponible", health.LLM.Name))
 	}
 
 	// Check TTS
-	if b.ttsProvider != nil && b.ttsProvider.IsAvailable(ctx) {
-		status = append(status, fmt.Sprintf("TTS %s: activo", b.ttsProvider.Name()))
+	if health.TTS != nil && health.TTS.Available {
+		status = append(status, fmt.Sprintf("TTS %s: activo", health.TTS.Name))
 	} else {
 		status = append(status, "TTS: no disponible")
 	}
 
 	// Check executors
-	for _, actionName := range []string{"twitch", "obs", "music"} {
-		exec, ok := b.registry.Get(actionName)
-		if ok && exec.IsAvailable() {
-			status = append(status, fmt.Sprintf("%s: conectado", actionName))
-		} else if ok {
-			status = append(status, fmt.Sprintf("%s: desconectado", actionName))
+	for _, exec := range health.Executors {
+		if exec.Available {
+			status = append(status, fmt.Sprintf("%s: conectado", exec.Name))
+		} else {
+			status = append(status, fmt.Sprintf("%s: desconectado", exec.Name))
 		}
 	}
 
@@ -208,135 +641,50 @@ func (b *Brain) handleCalc(ctx context.Context, action llm.Action) (string, erro
 	}
 
 	b.log.Debug().Str("expression", expression).Float64("result", result).Msg("Calculation successful")
-	return action.Reply, nil
-}
-
-// evaluateExpression safely evaluates a mathematical expression
-func evaluateExpression(expr string) (float64, error) {
-	// Remove spaces
-	expr = strings.ReplaceAll(expr, " ", "")
 
-	// Validate: only allow numbers, operators, and parentheses
-	validChars := regexp.MustCompile(`^[0-9+\-*/.()^]+$`)
-	if !validChars.MatchString(expr) {
-		return 0, fmt.Errorf("expresión inválida")
+	// Build the reply from the computed value; the LLM's arithmetic is not trusted
+	spoken := formatSpokenNumber(result, b.cfg.General.Language)
+	if b.cfg.General.Language == "en" {
+		return fmt.Sprintf("The result is %s.", spoken), nil
 	}
-
-	// Simple evaluation - supports +, -, *, /, ^
-	// Replace ^ with ** for Go's math notation (not needed, we'll use basic eval)
-	// For safety, we'll implement a basic evaluator
-
-	return simpleEval(expr)
+	return fmt.Sprintf("El resultado es %s.", spoken), nil
 }
 
-// simpleEval performs basic arithmetic evaluation
-func simpleEval(expr string) (float64, error) {
-	// Handle parentheses recursively
-	for {
-		// Find innermost parentheses
-		openIdx := strings.LastIndex(expr, "(")
-		if openIdx == -1 {
-			break
-		}
-
-		closeIdx := strings.Index(expr[openIdx:], ")")
-		if closeIdx == -1 {
-			return 0, fmt.Errorf("paréntesis no balanceados")
-		}
-		closeIdx += openIdx
-
-		inner := expr[openIdx+1 : closeIdx]
-		result, err := simpleEval(inner)
-		if err != nil {
-			return 0, err
-		}
-
-		expr = expr[:openIdx] + fmt.Sprintf("%g", result) + expr[closeIdx+1:]
-	}
-
-	// Split by + and - (lowest precedence)
-	parts := regexp.MustCompile(`([+\-])`).Split(expr, -1)
-	if len(parts) == 0 {
-		return 0, fmt.Errorf("expresión vacía")
-	}
-
-	// Handle the first number
-	result, err := parseAndMultiplyDivide(parts[0])
-	if err != nil {
-		return 0, err
-	}
-
-	// Process remaining parts
-	for i := 1; i < len(parts); i += 2 {
-		if i >= len(parts) {
-			break
-		}
-
-		operator := parts[i-1][len(parts[i-1])-1:] // Get last char
-		if operator != "+" && operator != "-" {
-			operator = "+"
-			i--
-		}
-
-		nextVal, err := parseAndMultiplyDivide(parts[i])
-		if err != nil {
-			return 0, err
-		}
-
-		if operator == "+" {
-			result += nextVal
-		} else {
-			result -= nextVal
-		}
-	}
-
-	return result, nil
+// GetAvailableActions returns all available actions
+func (b *Brain) GetAvailableActions() []string {
+	return b.registry.GetAllActions()
 }
 
-// parseAndMultiplyDivide handles multiplication and division
-func parseAndMultiplyDivide(expr string) (float64, error) {
-	expr = strings.TrimSpace(expr)
-	if expr == "" {
-		return 0, fmt.Errorf("expresión vacía")
-	}
-
-	// Split by * and /
-	parts := regexp.MustCompile(`([*/])`).Split(expr, -1)
+// HasPendingConfirmation returns true if the context's session is waiting for a yes/no answer
+func (b *Brain) HasPendingConfirmation(ctx context.Context) bool {
+	return b.confirm.has(SessionFromContext(ctx))
+}
 
-	result, err := strconv.ParseFloat(parts[0], 64)
-	if err != nil {
-		return 0, fmt.Errorf("número inválido: %s", parts[0])
+// ConfirmationPrompt returns the question asked before running an action on
+// the confirm list; ok is false when the action needs no confirmation
+func (b *Brain) ConfirmationPrompt(action llm.Action) (prompt string, ok bool) {
+	if !b.confirm.required(action) {
+		return "", false
 	}
+	return b.confirm.prompt(action), true
+}
 
-	for i := 1; i < len(parts); i += 2 {
-		if i >= len(parts) {
-			break
-		}
-
-		operator := parts[i-1][len(parts[i-1])-1:]
-		nextStr := parts[i]
-
-		nextVal, err := strconv.ParseFloat(nextStr, 64)
-		if err != nil {
-			return 0, fmt.Errorf("número inválido: %s", nextStr)
-		}
-
-		if operator == "*" {
-			result *= nextVal
-		} else if operator == "/" {
-			if nextVal == 0 {
-				return 0, fmt.Errorf("división entre cero")
-			}
-			result /= nextVal
-		}
-	}
+// IsAffirmative returns true if text answers a confirmation prompt with yes
+func IsAffirmative(text string) bool {
+	return parseConfirmation(text) == answerYes
+}
 
-	return result, nil
+// OnConfirmationChange registers a callback fired when a session starts or stops
+// waiting for a confirmation, including when the confirmation times out
+func (b *Brain) OnConfirmationChange(fn func(session string, pending bool)) {
+	b.confirm.mu.Lock()
+	defer b.confirm.mu.Unlock()
+	b.confirm.onChange = fn
 }
 
-// GetAvailableActions returns all available actions
-func (b *Brain) GetAvailableActions() []string {
-	return b.registry.GetAllActions()
+// ResetConversation forgets the conversation history of the context's session
+func (b *Brain) ResetConversation(ctx context.Context) {
+	b.memory.reset(SessionFromContext(ctx))
 }
 
 // SetLLM sets the LLM provider
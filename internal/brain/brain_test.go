@@ -0,0 +1,259 @@
+package brain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jarvisstreamer/jarvis/internal/config"
+	"github.com/jarvisstreamer/jarvis/internal/events"
+	"github.com/jarvisstreamer/jarvis/internal/executor"
+	"github.com/jarvisstreamer/jarvis/internal/llm"
+)
+
+// fakeLLM answers each command with a fixed action
+type fakeLLM struct {
+	actions map[string]llm.Action
+}
+
+func (f *fakeLLM) CompleteChat(_ context.Context, messages []llm.Message) (llm.Action, error) {
+	text := messages[len(messages)-1].Content
+	if action, ok := f.actions[text]; ok {
+		return action, nil
+	}
+	return llm.Action{Action: "none", Reply: "No te he entendido."}, nil
+}
+
+func (f *fakeLLM) Name() string                                         { return "fake" }
+func (f *fakeLLM) Complete(context.Context, string) (llm.Action, error) { return llm.Action{}, nil }
+func (f *fakeLLM) CompleteRaw(context.Context, string) (string, error)  { return "", nil }
+func (f *fakeLLM) IsAvailable(context.Context) bool                     { return true }
+func (f *fakeLLM) Close() error                                         { return nil }
+
+// fakeTwitch records the actions it runs; actions listed in fail return
+// that error
+type fakeTwitch struct {
+	fail map[string]error
+
+	mu  sync.Mutex
+	ran []llm.Action
+}
+
+func (f *fakeTwitch) Name() string               { return "twitch" }
+func (f *fakeTwitch) SupportedActions() []string { return []string{"twitch.ban", "twitch.title"} }
+func (f *fakeTwitch) CanHandle(action string) bool {
+	return action == "twitch.ban" || action == "twitch.title"
+}
+func (f *fakeTwitch) IsAvailable() bool { return true }
+func (f *fakeTwitch) Close() error      { return nil }
+
+func (f *fakeTwitch) Schema() []executor.ActionSpec {
+	return []executor.ActionSpec{
+		{Name: "twitch.ban", Params: []executor.ParamSpec{{Name: "user", Type: executor.ParamString, Required: true}}},
+		{Name: "twitch.title", Description: "Cambiar el título del stream", Params: []executor.ParamSpec{{Name: "title", Type: executor.ParamString, Required: true}}},
+	}
+}
+
+func (f *fakeTwitch) Execute(_ context.Context, action llm.Action) (executor.Result, error) {
+	f.mu.Lock()
+	f.ran = append(f.ran, action)
+	f.mu.Unlock()
+	if err := f.fail[action.Action]; err != nil {
+		return executor.NewErrorResult(err), nil
+	}
+	return executor.NewResult("done"), nil
+}
+
+func (f *fakeTwitch) executed() []llm.Action {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]llm.Action(nil), f.ran...)
+}
+
+var (
+	banTroll = llm.Action{Action: "twitch.ban", Params: map[string]interface{}{"user": "troll"}, Reply: "Baneado."}
+	setTitle = llm.Action{Action: "twitch.title", Params: map[string]interface{}{"title": "Vuelvo en 5"}, Reply: "Título cambiado."}
+)
+
+// newTestBrain creates a brain with the fake LLM and Twitch executor, and
+// twitch.ban on the confirm list
+func newTestBrain(actions map[string]llm.Action) (*Brain, *fakeTwitch) {
+	cfg := &config.Config{}
+	cfg.Brain.Confirm = config.ConfirmConfig{Enabled: true, Actions: []string{"twitch.ban"}, TimeoutSeconds: 30}
+	cfg.Brain.Replies.Enabled = true
+
+	b := New(cfg, &fakeLLM{actions: actions}, nil)
+	twitch := &fakeTwitch{}
+	b.RegisterExecutor(twitch)
+	return b, twitch
+}
+
+// recordActions collects the Action events published until the returned
+// function is called
+func recordActions() func() []events.Action {
+	var mu sync.Mutex
+	var got []events.Action
+	unsubscribe := events.Subscribe(func(env events.Envelope) {
+		mu.Lock()
+		got = append(got, env.Event.(events.Action))
+		mu.Unlock()
+	}, events.KindAction)
+
+	return func() []events.Action {
+		unsubscribe()
+		mu.Lock()
+		defer mu.Unlock()
+		return got
+	}
+}
+
+func TestHeldActionIsAnnouncedOnlyWhenItRuns(t *testing.T) {
+	b, twitch := newTestBrain(map[string]llm.Action{"banea a troll": banTroll})
+	ctx := context.Background()
+	stop := recordActions()
+
+	resp, err := b.ProcessCommand(ctx, "banea a troll")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !b.HasPendingConfirmation(ctx) || len(twitch.executed()) != 0 {
+		t.Fatalf("ban not held for confirmation: reply %q", resp.Text)
+	}
+
+	if _, err := b.ProcessCommand(ctx, "sí"); err != nil {
+		t.Fatal(err)
+	}
+	got := stop()
+	if len(twitch.executed()) != 1 {
+		t.Fatalf("ban ran %d times, want once", len(twitch.executed()))
+	}
+	if len(got) != 1 || got[0].Action != "twitch.ban" || got[0].Source != "confirmation" {
+		t.Errorf("action events = %+v, want one twitch.ban from the confirmation", got)
+	}
+}
+
+func TestRefusedActionIsNotAnnounced(t *testing.T) {
+	b, twitch := newTestBrain(map[string]llm.Action{
+		"banea":         {Action: "twitch.ban", Reply: "Baneado."},
+		"banea a troll": banTroll,
+	})
+	ctx := context.Background()
+	stop := recordActions()
+
+	// Fails validation
+	if _, err := b.ProcessCommand(ctx, "banea"); err != nil {
+		t.Fatal(err)
+	}
+	// Held, then refused
+	if _, err := b.ProcessCommand(ctx, "banea a troll"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.ProcessCommand(ctx, "no"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := stop(); len(got) != 0 || len(twitch.executed()) != 0 {
+		t.Errorf("action events %+v, executed %v; want none", got, twitch.executed())
+	}
+}
+
+func TestExecutedActionIsAnnounced(t *testing.T) {
+	b, _ := newTestBrain(map[string]llm.Action{"cambia el título": setTitle})
+	stop := recordActions()
+
+	if _, err := b.ProcessCommand(context.Background(), "cambia el título"); err != nil {
+		t.Fatal(err)
+	}
+	if got := stop(); len(got) != 1 || got[0].Action != "twitch.title" || got[0].Source != "llm" {
+		t.Errorf("action events = %+v, want one twitch.title from the LLM", got)
+	}
+}
+
+func TestConfirmationPromptForReplay(t *testing.T) {
+	b, _ := newTestBrain(nil)
+	if prompt, ok := b.ConfirmationPrompt(banTroll); !ok || prompt == "" {
+		t.Errorf("ConfirmationPrompt(ban) = %q, %v", prompt, ok)
+	}
+	if _, ok := b.ConfirmationPrompt(setTitle); ok {
+		t.Error("ConfirmationPrompt asks for an unlisted action")
+	}
+	if !IsAffirmative("sí, hazlo") || IsAffirmative("no") || IsAffirmative("quizá") {
+		t.Error("IsAffirmative misread an answer")
+	}
+}
+
+func TestFailuresAreSpokenWithoutErrorDetails(t *testing.T) {
+	plan := llm.Action{Action: llm.ActionPlan, OnError: llm.PlanContinueOnError, Steps: []llm.Action{
+		{Action: "twitch.title", Params: map[string]interface{}{"title": "Vuelvo en 5"}},
+	}}
+	b, twitch := newTestBrain(map[string]llm.Action{"cambia el título": setTitle, "plan": plan})
+	twitch.fail = map[string]error{"twitch.title": errors.New("dial tcp: connection refused")}
+	ctx := context.Background()
+
+	resp, err := b.ProcessCommand(ctx, "cambia el título")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Text != genericFailure {
+		t.Errorf("failed action reply = %q, want %q", resp.Text, genericFailure)
+	}
+
+	resp, err = b.ProcessCommand(ctx, "plan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Falló: cambiar el título del stream (no he podido hacerlo, algo ha fallado)."; resp.Text != want {
+		t.Errorf("failed plan reply = %q, want %q", resp.Text, want)
+	}
+}
+
+func TestFailureReplyUsesSpokenReasons(t *testing.T) {
+	b, _ := newTestBrain(nil)
+	policy := &executor.PolicyError{Action: "twitch.ban", Reason: "Ahora no puedo banear a nadie."}
+	validation := &executor.ValidationError{Action: "twitch.ban", Param: "user", Reason: "Falta el parámetro user."}
+
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{policy, policy.Reason},
+		{fmt.Errorf("macro x stopped at step 1: %w", policy), policy.Reason},
+		{validation, validation.Reason},
+		{errors.New("executor twitch is not available"), genericFailure},
+	}
+	for _, tt := range tests {
+		got := b.FailureReply(banTroll, tt.err)
+		if got != tt.want || strings.Contains(got, "executor") {
+			t.Errorf("FailureReply(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestUnclearAnswerKeepsActionPending(t *testing.T) {
+	b, twitch := newTestBrain(map[string]llm.Action{"banea a troll": banTroll, "cambia el título": setTitle})
+	ctx := context.Background()
+
+	if _, err := b.ProcessCommand(ctx, "banea a troll"); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := b.ProcessCommand(ctx, "cambia el título")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(resp.Text, "¿Seguro que quieres banear a troll?") || !b.HasPendingConfirmation(ctx) {
+		t.Fatalf("unclear answer reply = %q, want the question again", resp.Text)
+	}
+	if got := twitch.executed(); len(got) != 0 {
+		t.Fatalf("executed %v before the answer", got)
+	}
+
+	if _, err := b.ProcessCommand(ctx, "sí"); err != nil {
+		t.Fatal(err)
+	}
+	if got := twitch.executed(); len(got) != 1 || got[0].Action != "twitch.ban" {
+		t.Errorf("executed %v, want the confirmed ban only", got)
+	}
+}
@@ -0,0 +1,354 @@
+package brain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// calcFunctions are the functions accepted in calc expressions
+var calcFunctions = map[string]func(args []float64) (float64, error){
+	"sqrt":  unaryFunc(math.Sqrt),
+	"raiz":  unaryFunc(math.Sqrt),
+	"cbrt":  unaryFunc(math.Cbrt),
+	"abs":   unaryFunc(math.Abs),
+	"floor": unaryFunc(math.Floor),
+	"ceil":  unaryFunc(math.Ceil),
+	"ln":    unaryFunc(math.Log),
+	"log":   unaryFunc(math.Log10),
+	"exp":   unaryFunc(math.Exp),
+	"round": roundFunc,
+}
+
+// calcConstants are the named constants accepted in calc expressions
+var calcConstants = map[string]float64{
+	"pi": math.Pi,
+	"e":  math.E,
+}
+
+// unaryFunc adapts a one-argument math function
+func unaryFunc(fn func(float64) float64) func([]float64) (float64, error) {
+	return func(args []float64) (float64, error) {
+		if len(args) != 1 {
+			return 0, fmt.Errorf("la función espera un argumento")
+		}
+		return fn(args[0]), nil
+	}
+}
+
+// roundFunc rounds to the nearest integer, or to round(x, decimals)
+func roundFunc(args []float64) (float64, error) {
+	switch len(args) {
+	case 1:
+		return math.Round(args[0]), nil
+	case 2:
+		scale := math.Pow(10, math.Round(args[1]))
+		return math.Round(args[0]*scale) / scale, nil
+	default:
+		return 0, fmt.Errorf("round espera uno o dos argumentos")
+	}
+}
+
+// calcParser is a recursive-descent parser for arithmetic expressions.
+//
+//	expr    = term { ("+" | "-") term }
+//	term    = unary { ("*" | "/") unary }
+//	unary   = ("+" | "-") unary | power
+//	power   = postfix [ "^" unary ]
+//	postfix = primary { "%" }
+//	primary = number | constant | function "(" expr { "," expr } ")" | "(" expr ")"
+type calcParser struct {
+	input  []rune
+	pos    int
+	inCall int // Nesting depth of function calls, where "," separates arguments
+}
+
+// operand is an intermediate value; percent marks a trailing "%" so that
+// "200 + 10%" means 220 like on a pocket calculator
+type operand struct {
+	value   float64
+	percent bool
+}
+
+// evaluateExpression safely evaluates a mathematical expression
+func evaluateExpression(expr string) (float64, error) {
+	p := &calcParser{input: []rune(normalizeExpression(expr))}
+
+	result, err := p.parseExpr()
+	if err != nil {
+		return 0, err
+	}
+
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		if p.input[p.pos] == ')' {
+			return 0, fmt.Errorf("paréntesis no balanceados")
+		}
+		return 0, fmt.Errorf("símbolo inesperado: %c", p.input[p.pos])
+	}
+
+	if math.IsNaN(result.value) || math.IsInf(result.value, 0) {
+		return 0, fmt.Errorf("el resultado no está definido")
+	}
+	return result.value, nil
+}
+
+// normalizeExpression maps alternative operator spellings to the parser's ones
+func normalizeExpression(expr string) string {
+	return strings.NewReplacer(
+		"**", "^",
+		"×", "*",
+		"·", "*",
+		"÷", "/",
+		"√", "sqrt",
+		"raíz", "raiz",
+	).Replace(strings.ToLower(expr))
+}
+
+func (p *calcParser) skipSpaces() {
+	for p.pos < len(p.input) && unicode.IsSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
+
+// peek returns the next non-space rune, or 0 at the end of input
+func (p *calcParser) peek() rune {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *calcParser) parseExpr() (operand, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return operand{}, err
+	}
+
+	for {
+		op := p.peek()
+		if op != '+' && op != '-' {
+			return left, nil
+		}
+		p.pos++
+
+		right, err := p.parseTerm()
+		if err != nil {
+			return operand{}, err
+		}
+
+		// A percentage is relative to the left-hand side: 200 - 10% = 180
+		delta := right.value
+		if right.percent && !left.percent {
+			delta = left.value * right.value
+		}
+		if op == '+' {
+			left = operand{value: left.value + delta}
+		} else {
+			left = operand{value: left.value - delta}
+		}
+	}
+}
+
+func (p *calcParser) parseTerm() (operand, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return operand{}, err
+	}
+
+	for {
+		op := p.peek()
+		if op != '*' && op != '/' {
+			return left, nil
+		}
+		p.pos++
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return operand{}, err
+		}
+
+		if op == '*' {
+			left = operand{value: left.value * right.value}
+		} else {
+			if right.value == 0 {
+				return operand{}, fmt.Errorf("división entre cero")
+			}
+			left = operand{value: left.value / right.value}
+		}
+	}
+}
+
+func (p *calcParser) parseUnary() (operand, error) {
+	switch p.peek() {
+	case '-':
+		p.pos++
+		v, err := p.parseUnary()
+		v.value = -v.value
+		return v, err
+	case '+':
+		p.pos++
+		return p.parseUnary()
+	}
+	return p.parsePower()
+}
+
+func (p *calcParser) parsePower() (operand, error) {
+	base, err := p.parsePostfix()
+	if err != nil {
+		return operand{}, err
+	}
+
+	if p.peek() != '^' {
+		return base, nil
+	}
+	p.pos++
+
+	// Right associative: 2^3^2 = 2^9
+	exp, err := p.parseUnary()
+	if err != nil {
+		return operand{}, err
+	}
+	return operand{value: math.Pow(base.value, exp.value)}, nil
+}
+
+func (p *calcParser) parsePostfix() (operand, error) {
+	v, err := p.parsePrimary()
+	if err != nil {
+		return operand{}, err
+	}
+
+	for p.peek() == '%' {
+		p.pos++
+		v = operand{value: v.value / 100, percent: true}
+	}
+	return v, nil
+}
+
+func (p *calcParser) parsePrimary() (operand, error) {
+	r := p.peek()
+	switch {
+	case r == 0:
+		return operand{}, fmt.Errorf("expresión incompleta")
+
+	case r == '(':
+		p.pos++
+		v, err := p.parseExpr()
+		if err != nil {
+			return operand{}, err
+		}
+		if p.peek() != ')' {
+			return operand{}, fmt.Errorf("paréntesis no balanceados")
+		}
+		p.pos++
+		return operand{value: v.value}, nil
+
+	case unicode.IsDigit(r) || r == '.':
+		return p.parseNumber()
+
+	case unicode.IsLetter(r):
+		return p.parseIdentifier()
+
+	default:
+		return operand{}, fmt.Errorf("símbolo inesperado: %c", r)
+	}
+}
+
+// parseNumber reads a decimal number; outside function calls a comma is
+// accepted as decimal separator
+func (p *calcParser) parseNumber() (operand, error) {
+	start := p.pos
+	for p.pos < len(p.input) {
+		r := p.input[p.pos]
+		if !unicode.IsDigit(r) && r != '.' && (r != ',' || p.inCall > 0) {
+			break
+		}
+		p.pos++
+	}
+
+	text := strings.Replace(string(p.input[start:p.pos]), ",", ".", 1)
+	v, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return operand{}, fmt.Errorf("número inválido: %s", string(p.input[start:p.pos]))
+	}
+	return operand{value: v}, nil
+}
+
+// parseIdentifier reads a constant or a function call
+func (p *calcParser) parseIdentifier() (operand, error) {
+	start := p.pos
+	for p.pos < len(p.input) && unicode.IsLetter(p.input[p.pos]) {
+		p.pos++
+	}
+	name := string(p.input[start:p.pos])
+
+	if v, ok := calcConstants[name]; ok && p.peek() != '(' {
+		return operand{value: v}, nil
+	}
+
+	fn, ok := calcFunctions[name]
+	if !ok {
+		return operand{}, fmt.Errorf("función desconocida: %s", name)
+	}
+
+	if p.peek() != '(' {
+		// Allow "sqrt 16"
+		arg, err := p.parsePower()
+		if err != nil {
+			return operand{}, err
+		}
+		v, err := fn([]float64{arg.value})
+		return operand{value: v}, err
+	}
+	p.pos++
+	p.inCall++
+	defer func() { p.inCall-- }()
+
+	var args []float64
+	for {
+		arg, err := p.parseExpr()
+		if err != nil {
+			return operand{}, err
+		}
+		args = append(args, arg.value)
+
+		switch p.peek() {
+		case ',', ';':
+			p.pos++
+			continue
+		case ')':
+			p.pos++
+			v, err := fn(args)
+			return operand{value: v}, err
+		default:
+			return operand{}, fmt.Errorf("paréntesis no balanceados")
+		}
+	}
+}
+
+// formatSpokenNumber formats a result so TTS reads it naturally:
+// at most 4 decimals, decimal comma and "menos" in Spanish
+func formatSpokenNumber(v float64, language string) string {
+	v = math.Round(v*1e4) / 1e4
+	if v == 0 {
+		v = 0 // Avoid "-0"
+	}
+
+	text := strconv.FormatFloat(math.Abs(v), 'f', -1, 64)
+
+	if language == "en" {
+		if v < 0 {
+			return "minus " + text
+		}
+		return text
+	}
+
+	text = strings.Replace(text, ".", ",", 1)
+	if v < 0 {
+		return "menos " + text
+	}
+	return text
+}
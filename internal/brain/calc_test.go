@@ -0,0 +1,84 @@
+package brain
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEvaluateExpression(t *testing.T) {
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		{"2 + 3 * 4", 14},
+		{"(2 + 3) * 4", 20},
+		{"10 / 4", 2.5},
+		{"2 ^ 3 ^ 2", 512},
+		{"2 ** 10", 1024},
+		{"-2 ^ 2", -4},
+		{"--3", 3},
+		{"3 × 4 ÷ 2", 6},
+		{"200 + 10%", 220},
+		{"200 - 10%", 180},
+		{"50% * 80", 40},
+		{"2,5 * 2", 5},
+		{"sqrt(16)", 4},
+		{"√16 + 1", 5},
+		{"raíz 81", 9},
+		{"round(3.14159, 2)", 3.14},
+		{"round(2.5)", 3},
+		{"abs(-7) + floor(2.9) + ceil(2.1)", 12},
+		{"log(1000)", 3},
+		{"2 * pi", 2 * math.Pi},
+		{"e", math.E},
+	}
+	for _, tt := range tests {
+		got, err := evaluateExpression(tt.expr)
+		if err != nil {
+			t.Errorf("evaluateExpression(%q): %v", tt.expr, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("evaluateExpression(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluateExpressionErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"1 / 0",
+		"(1 + 2",
+		"1 + 2)",
+		"2 +",
+		"foo(2)",
+		"2 $ 3",
+		"sqrt(-1)",
+		"round(1, 2, 3)",
+		"1..2",
+	} {
+		if got, err := evaluateExpression(expr); err == nil {
+			t.Errorf("evaluateExpression(%q) = %v, want an error", expr, got)
+		}
+	}
+}
+
+func TestFormatSpokenNumber(t *testing.T) {
+	tests := []struct {
+		v        float64
+		language string
+		want     string
+	}{
+		{14, "es", "14"},
+		{2.5, "es", "2,5"},
+		{-3.25, "es", "menos 3,25"},
+		{-3.25, "en", "minus 3.25"},
+		{1.0 / 3, "es", "0,3333"},
+		{-0.00001, "es", "0"},
+	}
+	for _, tt := range tests {
+		if got := formatSpokenNumber(tt.v, tt.language); got != tt.want {
+			t.Errorf("formatSpokenNumber(%v, %s) = %q, want %q", tt.v, tt.language, got, tt.want)
+		}
+	}
+}
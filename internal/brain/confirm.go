@@ -0,0 +1,272 @@
+package brain
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jarvisstreamer/jarvis/internal/config"
+	"github.com/jarvisstreamer/jarvis/internal/executor/macro"
+	"github.com/jarvisstreamer/jarvis/internal/llm"
+	"github.com/jarvisstreamer/jarvis/pkg/utils"
+)
+
+// confirmationAnswer is the interpretation of a reply to a confirmation prompt
+type confirmationAnswer int
+
+const (
+	answerUnknown confirmationAnswer = iota
+	answerYes
+	answerNo
+)
+
+// Words accepted as the first word(s) of a confirmation reply (already normalized)
+var (
+	yesWords = []string{
+		"si", "claro", "dale", "confirmo", "confirmado", "hazlo", "adelante",
+		"correcto", "afirmativo", "por supuesto", "ok", "okay", "vale", "yes", "yeah", "sure", "do it",
+	}
+	noWords = []string{
+		"no", "cancela", "cancelalo", "cancelar", "olvidalo", "negativo", "mejor no",
+		"detente", "para", "nope", "cancel", "stop",
+	}
+)
+
+// parseConfirmation interprets an utterance as a yes/no answer
+func parseConfirmation(text string) confirmationAnswer {
+	normalized := utils.NormalizeText(text)
+	normalized = strings.TrimSpace(strings.TrimPrefix(normalized, "jarvis"))
+
+	if hasWordPrefix(normalized, noWords) {
+		return answerNo
+	}
+	if hasWordPrefix(normalized, yesWords) {
+		return answerYes
+	}
+	return answerUnknown
+}
+
+// hasWordPrefix returns true if text starts with one of the given words
+func hasWordPrefix(text string, words []string) bool {
+	for _, w := range words {
+		if text == w || strings.HasPrefix(text, w+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// pendingAction is an action waiting for the user's confirmation
+type pendingAction struct {
+	action llm.Action
+	timer  *time.Timer
+}
+
+// confirmations tracks pending confirmations per session
+type confirmations struct {
+	enabled bool
+	actions map[string]bool
+	macros  map[string]config.MacroConfig
+	timeout time.Duration
+
+	mu       sync.Mutex
+	pending  map[string]*pendingAction
+	onChange func(session string, pending bool)
+}
+
+// newConfirmations creates the confirmation tracker from configuration;
+// macros are looked into for steps that need confirmation
+func newConfirmations(cfg config.ConfirmConfig, macros []config.MacroConfig) *confirmations {
+	actions := make(map[string]bool, len(cfg.Actions))
+	for _, a := range cfg.Actions {
+		actions[a] = true
+	}
+	byName := make(map[string]config.MacroConfig, len(macros))
+	for _, m := range macros {
+		byName[m.Name] = m
+	}
+	return &confirmations{
+		enabled: cfg.Enabled,
+		actions: actions,
+		macros:  byName,
+		timeout: cfg.Timeout(),
+		pending: make(map[string]*pendingAction),
+	}
+}
+
+// required returns true if the action, or any action it runs, needs confirmation
+func (c *confirmations) required(action llm.Action) bool {
+	if !c.enabled {
+		return false
+	}
+	_, ok := c.find(action, 0)
+	return ok
+}
+
+// find returns the first action that needs confirmation among an action
+// and the ones it runs in turn
+func (c *confirmations) find(action llm.Action, depth int) (llm.Action, bool) {
+	if c.actions[action.Action] {
+		return action, true
+	}
+	if depth > macro.MaxDepth {
+		return llm.Action{}, false
+	}
+	for _, inner := range c.inner(action) {
+		if found, ok := c.find(inner, depth+1); ok {
+			return found, true
+		}
+	}
+	return llm.Action{}, false
+}
+
+// inner returns the actions an action runs: the steps of a plan or a
+// macro, or the action a schedule job runs when it fires
+func (c *confirmations) inner(action llm.Action) []llm.Action {
+	if action.IsPlan() {
+		return action.Steps
+	}
+	if target, ok := scheduledAction(action); ok {
+		return []llm.Action{target}
+	}
+	if name, ok := strings.CutPrefix(action.Action, macro.ActionPrefix); ok {
+		if m, ok := c.macros[name]; ok {
+			return macro.Steps(m, action.Params)
+		}
+	}
+	return nil
+}
+
+// scheduledAction returns the action a schedule.once or schedule.every job
+// will run
+func scheduledAction(action llm.Action) (llm.Action, bool) {
+	if action.Action != "schedule.once" && action.Action != "schedule.every" {
+		return llm.Action{}, false
+	}
+	target := strings.TrimSpace(action.GetStringParam("action"))
+	if target == "" {
+		return llm.Action{}, false
+	}
+	params, _ := action.Params["action_params"].(map[string]interface{})
+	return llm.Action{Action: target, Params: params}, true
+}
+
+// hold stores an action until the session answers or the timeout expires
+func (c *confirmations) hold(session string, action llm.Action) {
+	c.mu.Lock()
+	if old, ok := c.pending[session]; ok {
+		old.timer.Stop()
+	}
+	p := &pendingAction{action: action}
+	p.timer = time.AfterFunc(c.timeout, func() {
+		c.expire(session, p)
+	})
+	c.pending[session] = p
+	onChange := c.onChange
+	c.mu.Unlock()
+
+	if onChange != nil {
+		onChange(session, true)
+	}
+}
+
+// take removes and returns the pending action of a session
+func (c *confirmations) take(session string) (llm.Action, bool) {
+	c.mu.Lock()
+	p, ok := c.pending[session]
+	if ok {
+		p.timer.Stop()
+		delete(c.pending, session)
+	}
+	onChange := c.onChange
+	c.mu.Unlock()
+
+	if !ok {
+		return llm.Action{}, false
+	}
+	if onChange != nil {
+		onChange(session, false)
+	}
+	return p.action, true
+}
+
+// peek returns the pending action of a session without removing it
+func (c *confirmations) peek(session string) (llm.Action, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.pending[session]
+	if !ok {
+		return llm.Action{}, false
+	}
+	return p.action, true
+}
+
+// has returns true if the session has an action waiting for confirmation
+func (c *confirmations) has(session string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.pending[session]
+	return ok
+}
+
+// expire drops a pending action whose timeout fired
+func (c *confirmations) expire(session string, p *pendingAction) {
+	c.mu.Lock()
+	current, ok := c.pending[session]
+	if !ok || current != p {
+		c.mu.Unlock()
+		return
+	}
+	delete(c.pending, session)
+	onChange := c.onChange
+	c.mu.Unlock()
+
+	if onChange != nil {
+		onChange(session, false)
+	}
+}
+
+// prompt builds the question asked before running an action, about the
+// step that needs confirmation when the action runs several
+func (c *confirmations) prompt(action llm.Action) string {
+	target, ok := c.find(action, 0)
+	if !ok || target.Action == action.Action {
+		return actionPrompt(action)
+	}
+
+	text := actionPrompt(target)
+	switch {
+	case action.IsPlan():
+		return fmt.Sprintf("%s Son %d acciones en total.", text, len(action.Steps))
+	case strings.HasPrefix(action.Action, macro.ActionPrefix):
+		name := strings.ReplaceAll(strings.TrimPrefix(action.Action, macro.ActionPrefix), "_", " ")
+		return fmt.Sprintf("%s Es parte de la rutina %s.", text, name)
+	case action.Action == "schedule.once":
+		return fmt.Sprintf("%s Se hará dentro de %s minutos.", text, formatSpokenNumber(action.GetFloatParam("minutes"), "es"))
+	case action.Action == "schedule.every":
+		return fmt.Sprintf("%s Se repetirá cada %s minutos.", text, formatSpokenNumber(action.GetFloatParam("minutes"), "es"))
+	default:
+		return text
+	}
+}
+
+// actionPrompt builds the question asked before running a single action
+func actionPrompt(action llm.Action) string {
+	switch action.Action {
+	case "twitch.ban":
+		return fmt.Sprintf("¿Seguro que quieres banear a %s?", action.GetStringParam("user"))
+	case "twitch.timeout":
+		return fmt.Sprintf("¿Seguro que quieres darle timeout a %s?", action.GetStringParam("user"))
+	case "twitch.unban":
+		return fmt.Sprintf("¿Seguro que quieres desbanear a %s?", action.GetStringParam("user"))
+	case "obs.scene":
+		return fmt.Sprintf("¿Seguro que quieres cambiar a la escena %s?", action.GetStringParam("scene"))
+	case "twitch.title":
+		return fmt.Sprintf("¿Seguro que quieres cambiar el título a %s?", action.GetStringParam("title"))
+	case "twitch.category":
+		return fmt.Sprintf("¿Seguro que quieres cambiar la categoría a %s?", action.GetStringParam("category"))
+	default:
+		return fmt.Sprintf("¿Seguro que quieres ejecutar %s?", action.Action)
+	}
+}
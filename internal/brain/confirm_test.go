@@ -0,0 +1,108 @@
+package brain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jarvisstreamer/jarvis/internal/config"
+	"github.com/jarvisstreamer/jarvis/internal/llm"
+)
+
+func newTestConfirmations() *confirmations {
+	macros := []config.MacroConfig{
+		{Name: "limpiar_chat", Steps: []config.MacroStepConfig{
+			{Action: "obs.scene", Params: map[string]interface{}{"scene": "BRB"}},
+			{Action: "twitch.ban", Params: map[string]interface{}{"user": "${user}"}},
+		}},
+		{Name: "descanso", Steps: []config.MacroStepConfig{{Action: "music.pause"}}},
+		{Name: "anidada", Steps: []config.MacroStepConfig{{Action: "macro.limpiar_chat"}}},
+		{Name: "ciclo", Steps: []config.MacroStepConfig{{Action: "macro.ciclo"}}},
+	}
+	return newConfirmations(config.ConfirmConfig{
+		Enabled: true,
+		Actions: []string{"twitch.ban"},
+	}, macros)
+}
+
+func scheduled(kind, target string) llm.Action {
+	return llm.Action{Action: kind, Params: map[string]interface{}{
+		"minutes":       5.0,
+		"action":        target,
+		"action_params": map[string]interface{}{"user": "troll"},
+	}}
+}
+
+func TestConfirmationRequired(t *testing.T) {
+	c := newTestConfirmations()
+
+	tests := []struct {
+		name   string
+		action llm.Action
+		want   bool
+	}{
+		{"listed action", llm.Action{Action: "twitch.ban"}, true},
+		{"unlisted action", llm.Action{Action: "music.pause"}, false},
+		{"plan step", llm.Action{Action: llm.ActionPlan, Steps: []llm.Action{{Action: "music.pause"}, {Action: "twitch.ban"}}}, true},
+		{"macro step", llm.Action{Action: "macro.limpiar_chat"}, true},
+		{"nested macro step", llm.Action{Action: "macro.anidada"}, true},
+		{"macro in a plan", llm.Action{Action: llm.ActionPlan, Steps: []llm.Action{{Action: "macro.limpiar_chat"}}}, true},
+		{"harmless macro", llm.Action{Action: "macro.descanso"}, false},
+		{"macro cycle", llm.Action{Action: "macro.ciclo"}, false},
+		{"unknown macro", llm.Action{Action: "macro.nada"}, false},
+		{"scheduled action", scheduled("schedule.once", "twitch.ban"), true},
+		{"recurring action", scheduled("schedule.every", "twitch.ban"), true},
+		{"scheduled macro", scheduled("schedule.once", "macro.limpiar_chat"), true},
+		{"harmless scheduled action", scheduled("schedule.every", "music.pause"), false},
+		{"reminder", llm.Action{Action: "schedule.once", Params: map[string]interface{}{"minutes": 5.0, "reminder": "beber agua"}}, false},
+	}
+	for _, tt := range tests {
+		if got := c.required(tt.action); got != tt.want {
+			t.Errorf("%s: required = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	c.enabled = false
+	if c.required(llm.Action{Action: "twitch.ban"}) {
+		t.Error("required with confirmations disabled")
+	}
+}
+
+func TestConfirmationPrompt(t *testing.T) {
+	c := newTestConfirmations()
+
+	prompt := c.prompt(llm.Action{Action: "macro.limpiar_chat", Params: map[string]interface{}{"user": "troll"}})
+	if !strings.Contains(prompt, "banear a troll") || !strings.Contains(prompt, "rutina limpiar chat") {
+		t.Errorf("macro prompt = %q", prompt)
+	}
+
+	prompt = c.prompt(scheduled("schedule.once", "twitch.ban"))
+	if !strings.Contains(prompt, "banear a troll") || !strings.Contains(prompt, "dentro de 5 minutos") {
+		t.Errorf("schedule prompt = %q", prompt)
+	}
+
+	prompt = c.prompt(llm.Action{Action: llm.ActionPlan, Steps: []llm.Action{
+		{Action: "music.pause"},
+		{Action: "twitch.ban", Params: map[string]interface{}{"user": "troll"}},
+	}})
+	if !strings.Contains(prompt, "banear a troll") || !strings.Contains(prompt, "Son 2 acciones") {
+		t.Errorf("plan prompt = %q", prompt)
+	}
+}
+
+func TestParseConfirmation(t *testing.T) {
+	tests := map[string]confirmationAnswer{
+		"Sí, hazlo":          answerYes,
+		"Jarvis, adelante":   answerYes,
+		"no":                 answerNo,
+		"mejor no":           answerNo,
+		"cancélalo":          answerNo,
+		"qué hora es":        answerUnknown,
+		"sin música":         answerUnknown,
+		"nombre del usuario": answerUnknown,
+	}
+	for text, want := range tests {
+		if got := parseConfirmation(text); got != want {
+			t.Errorf("parseConfirmation(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package brain
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/jarvisstreamer/jarvis/internal/config"
+	"github.com/jarvisstreamer/jarvis/internal/llm"
+)
+
+// DefaultSession is the session used when the context carries none
+const DefaultSession = "default"
+
+type sessionKey struct{}
+
+// WithSession returns a context whose commands share the given conversation
+func WithSession(ctx context.Context, session string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, session)
+}
+
+// SessionFromContext returns the conversation session of a context
+func SessionFromContext(ctx context.Context) string {
+	if session, ok := ctx.Value(sessionKey{}).(string); ok && session != "" {
+		return session
+	}
+	return DefaultSession
+}
+
+// conversation is the recent history of one session
+type conversation struct {
+	messages []llm.Message
+	updated  time.Time
+}
+
+// memory keeps a bounded, expiring conversation history per session
+type memory struct {
+	enabled  bool
+	maxTurns int
+	ttl      time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]*conversation
+}
+
+// newMemory creates the conversation memory from configuration
+func newMemory(cfg config.MemoryConfig) *memory {
+	return &memory{
+		enabled:  cfg.Enabled && cfg.MaxTurns > 0,
+		maxTurns: cfg.MaxTurns,
+		ttl:      cfg.TTL(),
+		sessions: make(map[string]*conversation),
+	}
+}
+
+// history returns a copy of the session's messages, dropping expired ones
+func (m *memory) history(session string) []llm.Message {
+	if !m.enabled {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	conv, ok := m.sessions[session]
+	if !ok {
+		return nil
+	}
+	if m.ttl > 0 && time.Since(conv.updated) > m.ttl {
+		delete(m.sessions, session)
+		return nil
+	}
+
+	history := make([]llm.Message, len(conv.messages))
+	copy(history, conv.messages)
+	return history
+}
+
+// add records a user utterance and the action the LLM answered with
+func (m *memory) add(session, text string, action llm.Action) {
+	if !m.enabled {
+		return
+	}
+
+	// Store the structured answer so the model keeps following the JSON format
+	reply, err := json.Marshal(action)
+	if err != nil {
+		reply = []byte(action.Reply)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	conv, ok := m.sessions[session]
+	if !ok || (m.ttl > 0 && time.Since(conv.updated) > m.ttl) {
+		conv = &conversation{}
+		m.sessions[session] = conv
+	}
+
+	conv.messages = append(conv.messages,
+		llm.Message{Role: llm.RoleUser, Content: text},
+		llm.Message{Role: llm.RoleAssistant, Content: string(reply)},
+	)
+	if max := m.maxTurns * 2; len(conv.messages) > max {
+		conv.messages = conv.messages[len(conv.messages)-max:]
+	}
+	conv.updated = time.Now()
+}
+
+// reset forgets the conversation of a session
+func (m *memory) reset(session string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, session)
+}
@@ -0,0 +1,143 @@
+package brain
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jarvisstreamer/jarvis/internal/config"
+	"github.com/jarvisstreamer/jarvis/internal/executor"
+	"github.com/jarvisstreamer/jarvis/internal/llm"
+)
+
+// anyAction is the template action that applies to actions without their own
+const anyAction = "*"
+
+// genericFailure is said about a failed action with no better explanation
+const genericFailure = "No he podido hacerlo, algo ha fallado."
+
+// builtinReplies are the templates used unless the configuration overrides them
+var builtinReplies = []config.ReplyTemplate{
+	// The edit URL stays in the result data and the printed text; read out
+	// loud it's just noise
+	{Action: "twitch.clip", Success: "Clip creado.", Error: "No he podido crear el clip."},
+	{Action: "music.play", Success: "Poniendo ${track}, hay ${total} canciones en la lista."},
+	{Action: "music.next", Success: "Siguiente canción: ${track}."},
+	{Action: "music.previous", Success: "Volviendo a ${track}."},
+	// Executor errors are technical and in English; they go to the log
+	{Action: anyAction, Error: genericFailure},
+}
+
+// placeholderRe matches the ${name} placeholders of a template
+var placeholderRe = regexp.MustCompile(`\$\{([a-zA-Z0-9_]+)\}`)
+
+// replies builds the spoken reply from what an action actually did
+type replies struct {
+	enabled   bool
+	templates map[string]config.ReplyTemplate
+}
+
+// newReplies creates the reply templates, configured ones replacing the
+// built-in template of the same action
+func newReplies(cfg config.RepliesConfig) *replies {
+	r := &replies{
+		enabled:   cfg.Enabled,
+		templates: make(map[string]config.ReplyTemplate),
+	}
+	for _, t := range builtinReplies {
+		r.templates[t.Action] = t
+	}
+	for _, t := range cfg.Templates {
+		r.templates[t.Action] = t
+	}
+	return r
+}
+
+// template returns the template of an action, or the catch-all one
+func (r *replies) template(action string) (config.ReplyTemplate, bool) {
+	if t, ok := r.templates[action]; ok {
+		return t, true
+	}
+	t, ok := r.templates[anyAction]
+	return t, ok
+}
+
+// success returns the reply to a successful action, if a template applies
+// and every value it mentions is known
+func (r *replies) success(action llm.Action, result executor.Result) (string, bool) {
+	if !r.enabled {
+		return "", false
+	}
+	t, ok := r.template(action.Action)
+	if !ok {
+		return "", false
+	}
+	return render(t.Success, replyValues(action, result.Data))
+}
+
+// failure returns the reply to a failed action, if a template applies
+func (r *replies) failure(action llm.Action, err error) (string, bool) {
+	if !r.enabled {
+		return "", false
+	}
+	t, ok := r.template(action.Action)
+	if !ok || t.Error == "" {
+		// An action's own template may only cover success
+		if t, ok = r.templates[anyAction]; !ok {
+			return "", false
+		}
+	}
+
+	values := replyValues(action, nil)
+	values["error"] = strings.TrimRight(err.Error(), ".")
+	return render(t.Error, values)
+}
+
+// replyValues collects the values a template can mention: the action
+// params, overridden by the result data
+func replyValues(action llm.Action, data map[string]interface{}) map[string]string {
+	values := map[string]string{"action": action.Action}
+	for k, v := range action.Params {
+		values[k] = formatValue(v)
+	}
+	for k, v := range data {
+		values[k] = formatValue(v)
+	}
+	return values
+}
+
+// render fills the placeholders of a template; it fails if the template is
+// empty or a placeholder has no value, so a half-filled sentence is never spoken
+func render(tmpl string, values map[string]string) (string, bool) {
+	if tmpl == "" {
+		return "", false
+	}
+
+	complete := true
+	reply := placeholderRe.ReplaceAllStringFunc(tmpl, func(m string) string {
+		value := values[m[2:len(m)-1]]
+		if value == "" {
+			complete = false
+		}
+		return value
+	})
+	return reply, complete
+}
+
+// formatValue formats a result or param value for speech
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Local().Format("15:04")
+	default:
+		return fmt.Sprint(v)
+	}
+}
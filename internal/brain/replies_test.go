@@ -0,0 +1,59 @@
+package brain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jarvisstreamer/jarvis/internal/config"
+	"github.com/jarvisstreamer/jarvis/internal/executor"
+	"github.com/jarvisstreamer/jarvis/internal/llm"
+)
+
+func TestRepliesSuccess(t *testing.T) {
+	r := newReplies(config.RepliesConfig{Enabled: true})
+
+	clip := executor.NewResultWithData("Clip created", map[string]interface{}{
+		"clip_id":  "AbC",
+		"edit_url": "https://clips.twitch.tv/AbC/edit",
+	})
+	reply, ok := r.success(llm.Action{Action: "twitch.clip"}, clip)
+	if !ok || strings.Contains(reply, "http") {
+		t.Errorf("clip reply = %q, %v; want it without the URL", reply, ok)
+	}
+
+	next := executor.NewResultWithData("Next", map[string]interface{}{"track": "Bohemian Rhapsody"})
+	if reply, ok := r.success(llm.Action{Action: "music.next"}, next); !ok || reply != "Siguiente canción: Bohemian Rhapsody." {
+		t.Errorf("music.next reply = %q, %v", reply, ok)
+	}
+
+	// A missing value never leaves a half-filled sentence
+	if reply, ok := r.success(llm.Action{Action: "music.next"}, executor.NewResult("Next")); ok {
+		t.Errorf("music.next reply without track = %q", reply)
+	}
+}
+
+func TestRepliesFailure(t *testing.T) {
+	r := newReplies(config.RepliesConfig{Enabled: true})
+	err := errors.New("failed to connect to OBS: dial tcp 127.0.0.1:4455: connection refused")
+
+	for _, action := range []string{"obs.scene", "twitch.clip", "music.next"} {
+		reply, ok := r.failure(llm.Action{Action: action}, err)
+		if !ok || strings.Contains(reply, "dial tcp") || strings.Contains(reply, "failed") {
+			t.Errorf("%s failure reply = %q, %v; want a Spanish line without the error", action, reply, ok)
+		}
+	}
+
+	// Configured templates may still quote the error
+	r = newReplies(config.RepliesConfig{Enabled: true, Templates: []config.ReplyTemplate{
+		{Action: "obs.scene", Error: "Error: ${error}."},
+	}})
+	if reply, _ := r.failure(llm.Action{Action: "obs.scene"}, err); !strings.Contains(reply, "connection refused") {
+		t.Errorf("configured failure reply = %q", reply)
+	}
+
+	r = newReplies(config.RepliesConfig{})
+	if _, ok := r.failure(llm.Action{Action: "obs.scene"}, err); ok {
+		t.Error("failure reply with replies disabled")
+	}
+}
@@ -0,0 +1,143 @@
+package brain
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jarvisstreamer/jarvis/internal/events"
+	"github.com/jarvisstreamer/jarvis/internal/executor"
+	"github.com/jarvisstreamer/jarvis/internal/llm"
+	"github.com/jarvisstreamer/jarvis/internal/tts"
+)
+
+// Response is the outcome of one command: what was understood, what ran
+// and what to say. It is built by a single execution; speaking it is a
+// separate step (SpeakResponse).
+type Response struct {
+	Text    string            // Reply to speak, empty if there is nothing to say
+	Source  string            // Where the action came from: "intent", "llm", "confirmation", "api"...
+	Action  *llm.Action       // Interpreted action, nil if the command didn't get that far
+	Results []executor.Result // Outcome of every executed action, plan steps in order
+	Timings Timings
+
+	speech     *tts.Stream // Reply already being spoken while the LLM wrote it
+	speechCtx  context.Context
+	speechDone func()
+}
+
+// Timings measures the stages of one command
+type Timings struct {
+	Interpret time.Duration // Intent match or LLM completion
+	Execute   time.Duration
+	Total     time.Duration
+}
+
+type responseKey struct{}
+
+// withResponse returns a context that records the command's outcome into a new Response
+func withResponse(ctx context.Context) (context.Context, *Response) {
+	resp := &Response{}
+	return context.WithValue(ctx, responseKey{}, resp), resp
+}
+
+// responseFrom returns the Response being built for this command, or nil
+func responseFrom(ctx context.Context) *Response {
+	resp, _ := ctx.Value(responseKey{}).(*Response)
+	return resp
+}
+
+// setAction records the action the command resolved to
+func (r *Response) setAction(action llm.Action, source string) {
+	if r == nil {
+		return
+	}
+	r.Action = &action
+	if source != "" {
+		r.Source = source
+	}
+}
+
+// addResult records the outcome of one executed action
+func (r *Response) addResult(result executor.Result) {
+	if r == nil {
+		return
+	}
+	r.Results = append(r.Results, result)
+}
+
+// interpreted records the action a command is about to run and announces it
+// on the event bus
+func interpreted(ctx context.Context, action llm.Action, source string) {
+	responseFrom(ctx).setAction(action, source)
+	events.Publish(executor.ActionEvent(action, source))
+}
+
+// finish completes a Response and publishes its reply
+func (b *Brain) finish(resp *Response, text string, start time.Time) *Response {
+	resp.Text = text
+	resp.Timings.Total = time.Since(start)
+	if text != "" {
+		events.Publish(events.Reply{Text: text})
+	}
+	return resp
+}
+
+// SpeakResponse says the reply of a Response through the TTS provider. It
+// never executes anything: the command already ran in ProcessCommand. A
+// reply streamed while the LLM wrote it is not repeated; this waits for it
+// to finish instead.
+func (b *Brain) SpeakResponse(ctx context.Context, resp *Response) error {
+	if resp == nil {
+		return nil
+	}
+	if resp.speech != nil {
+		err := resp.speech.Close()
+		interrupted := resp.speechCtx.Err() != nil && ctx.Err() == nil
+		resp.speechDone()
+		if interrupted {
+			// Cut short by StopSpeaking, not a failure
+			return nil
+		}
+		if err != nil || strings.TrimSpace(resp.speech.Text()) == strings.TrimSpace(resp.Text) {
+			return err
+		}
+		// The final reply isn't what was streamed (unparseable answer): say it too
+	}
+	return b.Speak(ctx, resp.Text)
+}
+
+// complete asks the LLM for the action. When both providers can, the reply
+// of a conversational answer is spoken sentence by sentence as it arrives.
+func (b *Brain) complete(ctx context.Context, messages []llm.Message) (llm.Action, error) {
+	streaming, ok := b.llmProvider.(llm.StreamingProvider)
+	if !ok || !b.cfg.LLM.Stream || b.ttsProvider == nil {
+		return b.llmProvider.CompleteChat(ctx, messages)
+	}
+
+	var speech *tts.Stream
+	var speechCtx context.Context
+	var speechDone func()
+	speakable := true
+	action, err := streaming.CompleteChatStream(ctx, messages, func(delta string) {
+		if speech == nil {
+			if !speakable || !b.ttsProvider.IsAvailable(ctx) {
+				speakable = false
+				return
+			}
+			speechCtx, speechDone = b.speechContext(ctx)
+			speech = tts.NewStream(speechCtx, b.ttsProvider)
+		}
+		speech.Write(delta)
+	})
+
+	if speech != nil {
+		if resp := responseFrom(ctx); err == nil && resp != nil {
+			resp.speech, resp.speechCtx, resp.speechDone = speech, speechCtx, speechDone
+		} else {
+			speech.Close()
+			speechDone()
+		}
+	}
+	return action, err
+}
@@ -0,0 +1,154 @@
+package brain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jarvisstreamer/jarvis/internal/executor"
+	"github.com/jarvisstreamer/jarvis/internal/llm"
+)
+
+// maxUndo bounds how many commands can be undone
+const maxUndo = 10
+
+type undoKey struct{}
+
+// undoCollector gathers the inverse actions of the actions run by one command
+type undoCollector struct {
+	mu      sync.Mutex
+	actions []llm.Action
+}
+
+// withUndoCollector returns a context whose executed actions record their inverse
+func withUndoCollector(ctx context.Context) (context.Context, *undoCollector) {
+	c := &undoCollector{}
+	return context.WithValue(ctx, undoKey{}, c), c
+}
+
+// collectUndo records the inverse of an executed action, if the command collects them
+func collectUndo(ctx context.Context, undo *llm.Action) {
+	c, ok := ctx.Value(undoKey{}).(*undoCollector)
+	if !ok || undo == nil {
+		return
+	}
+	c.mu.Lock()
+	c.actions = append(c.actions, *undo)
+	c.mu.Unlock()
+}
+
+// undoStack keeps the inverse actions of the last commands; each entry
+// reverts one whole command, plans included
+type undoStack struct {
+	mu      sync.Mutex
+	entries [][]llm.Action
+}
+
+// push records the inverses gathered while running one command
+func (s *undoStack) push(c *undoCollector) {
+	c.mu.Lock()
+	actions := c.actions
+	c.mu.Unlock()
+	if len(actions) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, actions)
+	if len(s.entries) > maxUndo {
+		s.entries = s.entries[len(s.entries)-maxUndo:]
+	}
+}
+
+// pop removes and returns the inverses of the most recent command
+func (s *undoStack) pop() ([]llm.Action, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.entries) == 0 {
+		return nil, false
+	}
+	last := s.entries[len(s.entries)-1]
+	s.entries = s.entries[:len(s.entries)-1]
+	return last, true
+}
+
+// handleUndo reverts the last command that changed something, newest action first
+func (b *Brain) handleUndo(ctx context.Context, action llm.Action) (string, error) {
+	inverses, ok := b.undo.pop()
+	if !ok {
+		return "No hay nada que deshacer.", nil
+	}
+
+	ctx = executor.Undoing(ctx)
+	var done, failed []string
+	for i := len(inverses) - 1; i >= 0; i-- {
+		d, f := b.runUndo(ctx, inverses[i])
+		done = append(done, d...)
+		failed = append(failed, f...)
+	}
+
+	b.log.Info().Int("actions", len(done)).Int("failed", len(failed)).Msg("Undid last command")
+
+	if len(failed) > 0 {
+		return fmt.Sprintf("No pude deshacerlo del todo: falló %s.", strings.Join(failed, "; ")), nil
+	}
+	return "Deshecho: " + strings.Join(done, ", ") + ".", nil
+}
+
+// runUndo executes an inverse action, or the steps of an inverse plan in
+// order, and describes what was restored and what failed
+func (b *Brain) runUndo(ctx context.Context, action llm.Action) (done, failed []string) {
+	if action.IsPlan() {
+		for _, step := range action.Steps {
+			d, f := b.runUndo(ctx, step)
+			done = append(done, d...)
+			failed = append(failed, f...)
+		}
+		return done, failed
+	}
+
+	result, err := b.registry.Execute(ctx, action)
+	if err == nil && !result.Success {
+		err = errors.New(result.Error)
+	}
+	if err != nil {
+		b.log.Warn().Err(err).Str("action", action.Action).Msg("Undo action failed")
+		return nil, []string{b.failedStep(b.actionLabel(action), action, err)}
+	}
+	return []string{undoDescription(action)}, nil
+}
+
+// undoDescription says what an inverse action restored
+func undoDescription(action llm.Action) string {
+	source := action.GetStringParam("source")
+	switch action.Action {
+	case "obs.scene":
+		return "de vuelta en la escena " + action.GetStringParam("scene")
+	case "obs.mute":
+		return source + " silenciado otra vez"
+	case "obs.unmute":
+		return source + " vuelve a sonar"
+	case "obs.volume":
+		if _, ok := action.Params["volume_db"]; ok {
+			return "volumen de " + source + " restaurado"
+		}
+		return fmt.Sprintf("volumen de %s al %.0f%%", source, action.GetFloatParam("volume")*100)
+	case "obs.source.show":
+		return source + " visible otra vez"
+	case "obs.source.hide":
+		return source + " oculto otra vez"
+	case "obs.text":
+		return "texto de " + source + " restaurado"
+	case "twitch.title":
+		return "título restaurado"
+	case "twitch.category":
+		return "categoría restaurada a " + action.GetStringParam("category")
+	case "twitch.unban":
+		return action.GetStringParam("user") + " desbaneado"
+	default:
+		return action.Action
+	}
+}
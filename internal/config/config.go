@@ -15,6 +15,11 @@ type Config struct {
 	OBS     OBSConfig     `yaml:"obs" mapstructure:"obs"`
 	Music   MusicConfig   `yaml:"music" mapstructure:"music"`
 	Sounds  SoundsConfig  `yaml:"sounds" mapstructure:"sounds"`
+	Brain   BrainConfig   `yaml:"brain" mapstructure:"brain"`
+	Server  ServerConfig  `yaml:"server" mapstructure:"server"`
+	Macros  []MacroConfig `yaml:"macros" mapstructure:"macros"`
+	Policy  PolicyConfig  `yaml:"policy" mapstructure:"policy"`
+	Audit   AuditConfig   `yaml:"audit" mapstructure:"audit"`
 }
 
 // GeneralConfig contains general application settings
@@ -26,17 +31,21 @@ type GeneralConfig struct {
 
 // AudioConfig contains audio capture settings
 type AudioConfig struct {
-	Device     string       `yaml:"device" mapstructure:"device"`
-	SampleRate int          `yaml:"sample_rate" mapstructure:"sample_rate"`
-	Channels   int          `yaml:"channels" mapstructure:"channels"`
-	ChunkSize  int          `yaml:"chunk_size" mapstructure:"chunk_size"`
-	VAD        VADConfig    `yaml:"vad" mapstructure:"vad"`
+	Device     string         `yaml:"device" mapstructure:"device"`
+	SampleRate int            `yaml:"sample_rate" mapstructure:"sample_rate"`
+	Channels   int            `yaml:"channels" mapstructure:"channels"`
+	ChunkSize  int            `yaml:"chunk_size" mapstructure:"chunk_size"`
+	PreRollMs  int            `yaml:"pre_roll_ms" mapstructure:"pre_roll_ms"`   // Audio before a recording starts that is kept in it
+	PostRollMs int            `yaml:"post_roll_ms" mapstructure:"post_roll_ms"` // Audio after the last speech kept when silence ends a recording
+	VAD        VADConfig      `yaml:"vad" mapstructure:"vad"`
 	WakeWord   WakeWordConfig `yaml:"wake_word" mapstructure:"wake_word"`
+	BargeIn    BargeInConfig  `yaml:"barge_in" mapstructure:"barge_in"`
 }
 
 // VADConfig contains Voice Activity Detection settings
 type VADConfig struct {
 	Enabled            bool    `yaml:"enabled" mapstructure:"enabled"`
+	Detector           string  `yaml:"detector" mapstructure:"detector"` // "adaptive" or "energy"
 	Sensitivity        float64 `yaml:"sensitivity" mapstructure:"sensitivity"`
 	SilenceThresholdMs int     `yaml:"silence_threshold_ms" mapstructure:"silence_threshold_ms"`
 	MinSpeechMs        int     `yaml:"min_speech_ms" mapstructure:"min_speech_ms"`
@@ -49,6 +58,13 @@ type WakeWordConfig struct {
 	Threshold float64 `yaml:"threshold" mapstructure:"threshold"`
 }
 
+// BargeInConfig contains the settings to interrupt Jarvis by talking over it
+type BargeInConfig struct {
+	Enabled     bool    `yaml:"enabled" mapstructure:"enabled"`
+	Sensitivity float64 `yaml:"sensitivity" mapstructure:"sensitivity"`     // 0.0 - 1.0; lower ignores more of Jarvis's own voice in the mic
+	MinSpeechMs int     `yaml:"min_speech_ms" mapstructure:"min_speech_ms"` // Continuous speech needed to interrupt
+}
+
 // HotkeyConfig contains hotkey settings
 type HotkeyConfig struct {
 	Enabled bool   `yaml:"enabled" mapstructure:"enabled"`
@@ -58,8 +74,8 @@ type HotkeyConfig struct {
 
 // STTConfig contains Speech-to-Text settings
 type STTConfig struct {
-	Provider string        `yaml:"provider" mapstructure:"provider"` // "whisper" or "openai"
-	Whisper  WhisperConfig `yaml:"whisper" mapstructure:"whisper"`
+	Provider string          `yaml:"provider" mapstructure:"provider"` // "whisper" or "openai"
+	Whisper  WhisperConfig   `yaml:"whisper" mapstructure:"whisper"`
 	OpenAI   OpenAISTTConfig `yaml:"openai" mapstructure:"openai"`
 }
 
@@ -78,8 +94,9 @@ type OpenAISTTConfig struct {
 
 // LLMConfig contains Language Model settings
 type LLMConfig struct {
-	Provider string       `yaml:"provider" mapstructure:"provider"` // "ollama" or "openai"
-	Ollama   OllamaConfig `yaml:"ollama" mapstructure:"ollama"`
+	Provider string          `yaml:"provider" mapstructure:"provider"` // "ollama" or "openai"
+	Stream   bool            `yaml:"stream" mapstructure:"stream"`     // Speak conversational replies while they are generated
+	Ollama   OllamaConfig    `yaml:"ollama" mapstructure:"ollama"`
 	OpenAI   OpenAILLMConfig `yaml:"openai" mapstructure:"openai"`
 }
 
@@ -104,8 +121,8 @@ type OpenAILLMConfig struct {
 
 // TTSConfig contains Text-to-Speech settings
 type TTSConfig struct {
-	Provider string        `yaml:"provider" mapstructure:"provider"` // "piper" or "openai"
-	Piper    PiperConfig   `yaml:"piper" mapstructure:"piper"`
+	Provider string          `yaml:"provider" mapstructure:"provider"` // "piper" or "openai"
+	Piper    PiperConfig     `yaml:"piper" mapstructure:"piper"`
 	OpenAI   OpenAITTSConfig `yaml:"openai" mapstructure:"openai"`
 }
 
@@ -158,3 +175,153 @@ type SoundsConfig struct {
 	StartRecording string `yaml:"start_recording" mapstructure:"start_recording"`
 	StopRecording  string `yaml:"stop_recording" mapstructure:"stop_recording"`
 }
+
+// BrainConfig contains command orchestration settings
+type BrainConfig struct {
+	Memory  MemoryConfig  `yaml:"memory" mapstructure:"memory"`
+	Confirm ConfirmConfig `yaml:"confirm" mapstructure:"confirm"`
+	Intents IntentsConfig `yaml:"intents" mapstructure:"intents"`
+	Replies RepliesConfig `yaml:"replies" mapstructure:"replies"`
+}
+
+// MemoryConfig contains conversation history settings
+type MemoryConfig struct {
+	Enabled    bool `yaml:"enabled" mapstructure:"enabled"`
+	MaxTurns   int  `yaml:"max_turns" mapstructure:"max_turns"`     // User/assistant exchanges kept per session
+	TTLSeconds int  `yaml:"ttl_seconds" mapstructure:"ttl_seconds"` // Idle time before a conversation is forgotten
+}
+
+// TTL returns the conversation expiry as a time.Duration
+func (c MemoryConfig) TTL() time.Duration {
+	return time.Duration(c.TTLSeconds) * time.Second
+}
+
+// ConfirmConfig contains voice confirmation settings for destructive actions
+type ConfirmConfig struct {
+	Enabled        bool     `yaml:"enabled" mapstructure:"enabled"`
+	Actions        []string `yaml:"actions" mapstructure:"actions"`                 // Actions that require a spoken "sí"
+	TimeoutSeconds int      `yaml:"timeout_seconds" mapstructure:"timeout_seconds"` // Time to answer before the action is dropped
+}
+
+// Timeout returns the confirmation timeout as a time.Duration
+func (c ConfirmConfig) Timeout() time.Duration {
+	return time.Duration(c.TimeoutSeconds) * time.Second
+}
+
+// IntentsConfig contains the fast-path intent matcher settings
+type IntentsConfig struct {
+	Enabled        bool         `yaml:"enabled" mapstructure:"enabled"`
+	DisableBuiltin bool         `yaml:"disable_builtin" mapstructure:"disable_builtin"` // Only use the rules below
+	MicSource      string       `yaml:"mic_source" mapstructure:"mic_source"`           // OBS input of "silencia el micro"; empty leaves it to the LLM
+	Rules          []IntentRule `yaml:"rules" mapstructure:"rules"`
+}
+
+// IntentRule maps spoken phrasings straight to an action, skipping the LLM
+type IntentRule struct {
+	Action   string                 `yaml:"action" mapstructure:"action"`
+	Patterns []string               `yaml:"patterns" mapstructure:"patterns"` // e.g. "(pon|cambia a) [la] escena ${scene}"
+	Params   map[string]interface{} `yaml:"params" mapstructure:"params"`     // Fixed params, merged with captured ones
+	Reply    string                 `yaml:"reply" mapstructure:"reply"`       // May reference captures as ${name}
+}
+
+// RepliesConfig contains the templates that build the spoken reply from
+// what an action actually did, instead of the reply written before it ran
+type RepliesConfig struct {
+	Enabled   bool            `yaml:"enabled" mapstructure:"enabled"`
+	Templates []ReplyTemplate `yaml:"templates" mapstructure:"templates"` // Override the built-in templates of the same action
+}
+
+// ReplyTemplate is the reply to an action once it has run. ${name} is
+// replaced by the result data (clip_id, edit_url, track, total...) or the
+// action params; failures also get ${error}.
+type ReplyTemplate struct {
+	Action  string `yaml:"action" mapstructure:"action"` // Action name or "*" for every action without a template
+	Success string `yaml:"success" mapstructure:"success"`
+	Error   string `yaml:"error" mapstructure:"error"`
+}
+
+// ServerConfig contains the local HTTP/WebSocket control API settings
+type ServerConfig struct {
+	Enabled bool          `yaml:"enabled" mapstructure:"enabled"`
+	Address string        `yaml:"address" mapstructure:"address"` // e.g. "127.0.0.1:7878"
+	Token   string        `yaml:"token" mapstructure:"token"`     // Required in "Authorization: Bearer" or ?token=
+	Overlay OverlayConfig `yaml:"overlay" mapstructure:"overlay"`
+}
+
+// OverlayConfig contains the OBS browser-source overlay settings
+type OverlayConfig struct {
+	Enabled          bool   `yaml:"enabled" mapstructure:"enabled"`
+	Theme            string `yaml:"theme" mapstructure:"theme"`                           // "dark", "light", "minimal" or "neon"
+	HideAfterSeconds int    `yaml:"hide_after_seconds" mapstructure:"hide_after_seconds"` // Idle time before hiding; -1 never hides
+}
+
+// PolicyConfig contains the permissions and rate limits applied to every action.
+// Action names accept a trailing wildcard, e.g. "twitch.*".
+type PolicyConfig struct {
+	Allow    []string            `yaml:"allow" mapstructure:"allow"` // If set, only these actions may run
+	Deny     []string            `yaml:"deny" mapstructure:"deny"`   // Never run, even if allowed
+	Limits   []ActionLimitConfig `yaml:"limits" mapstructure:"limits"`
+	LiveSafe LiveSafeConfig      `yaml:"live_safe" mapstructure:"live_safe"`
+}
+
+// ActionLimitConfig limits how often an action may run
+type ActionLimitConfig struct {
+	Action          string `yaml:"action" mapstructure:"action"`
+	MaxCalls        int    `yaml:"max_calls" mapstructure:"max_calls"`               // Calls allowed per window; 0 disables
+	WindowSeconds   int    `yaml:"window_seconds" mapstructure:"window_seconds"`     // Length of the max_calls window
+	CooldownSeconds int    `yaml:"cooldown_seconds" mapstructure:"cooldown_seconds"` // Minimum time between two calls
+}
+
+// Window returns the rate-limit window as a time.Duration
+func (c ActionLimitConfig) Window() time.Duration {
+	return time.Duration(c.WindowSeconds) * time.Second
+}
+
+// Cooldown returns the cooldown as a time.Duration
+func (c ActionLimitConfig) Cooldown() time.Duration {
+	return time.Duration(c.CooldownSeconds) * time.Second
+}
+
+// LiveSafeConfig blocks actions while the stream is live
+type LiveSafeConfig struct {
+	Enabled    bool     `yaml:"enabled" mapstructure:"enabled"`
+	Actions    []string `yaml:"actions" mapstructure:"actions"`         // Actions blocked while live
+	AssumeLive bool     `yaml:"assume_live" mapstructure:"assume_live"` // Block them even when OBS can't tell if the stream is live
+}
+
+// AuditConfig contains the action audit log settings
+type AuditConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"` // Record every interaction in <data_dir>/audit.jsonl
+}
+
+// MacroConfig is a named routine of actions exposed as the action "macro.<name>"
+type MacroConfig struct {
+	Name        string             `yaml:"name" mapstructure:"name"`
+	Description string             `yaml:"description" mapstructure:"description"`
+	Phrases     []string           `yaml:"phrases" mapstructure:"phrases"` // Intent patterns that run the macro, e.g. "modo pausa"
+	Params      []MacroParamConfig `yaml:"params" mapstructure:"params"`
+	Steps       []MacroStepConfig  `yaml:"steps" mapstructure:"steps"`
+	OnError     string             `yaml:"on_error" mapstructure:"on_error"` // "stop" (default) or "continue"
+	Reply       string             `yaml:"reply" mapstructure:"reply"`       // Spoken when run by a phrase; may use ${param}
+}
+
+// MacroParamConfig declares a parameter that steps reference as ${name}
+type MacroParamConfig struct {
+	Name        string      `yaml:"name" mapstructure:"name"`
+	Type        string      `yaml:"type" mapstructure:"type"` // "string" (default), "number", "integer" or "boolean"
+	Description string      `yaml:"description" mapstructure:"description"`
+	Required    bool        `yaml:"required" mapstructure:"required"`
+	Default     interface{} `yaml:"default" mapstructure:"default"`
+}
+
+// MacroStepConfig is one action of a macro
+type MacroStepConfig struct {
+	Action       string                 `yaml:"action" mapstructure:"action"`
+	Params       map[string]interface{} `yaml:"params" mapstructure:"params"`
+	DelaySeconds float64                `yaml:"delay_seconds" mapstructure:"delay_seconds"` // Wait before running this step
+}
+
+// Delay returns the wait before the step as a time.Duration
+func (c MacroStepConfig) Delay() time.Duration {
+	return time.Duration(c.DelaySeconds * float64(time.Second))
+}
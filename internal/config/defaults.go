@@ -13,8 +13,11 @@ func DefaultConfig() *Config {
 			SampleRate: 16000,
 			Channels:   1,
 			ChunkSize:  1024,
+			PreRollMs:  500,
+			PostRollMs: 300,
 			VAD: VADConfig{
 				Enabled:            true,
+				Detector:           "adaptive",
 				Sensitivity:        0.5,
 				SilenceThresholdMs: 1500,
 				MinSpeechMs:        300,
@@ -24,6 +27,11 @@ func DefaultConfig() *Config {
 				Word:      "jarvis",
 				Threshold: 0.7,
 			},
+			BargeIn: BargeInConfig{
+				Enabled:     true,
+				Sensitivity: 0.3,
+				MinSpeechMs: 400,
+			},
 		},
 		Hotkey: HotkeyConfig{
 			Enabled: true,
@@ -43,6 +51,7 @@ func DefaultConfig() *Config {
 		},
 		LLM: LLMConfig{
 			Provider: "ollama",
+			Stream:   true,
 			Ollama: OllamaConfig{
 				URL:            "http://localhost:11434",
 				Model:          "llama3.2:3b",
@@ -92,6 +101,54 @@ func DefaultConfig() *Config {
 			StartRecording: "./assets/sounds/beep_start.wav",
 			StopRecording:  "./assets/sounds/beep_end.wav",
 		},
+		Brain: BrainConfig{
+			Memory: MemoryConfig{
+				Enabled:    true,
+				MaxTurns:   6,
+				TTLSeconds: 300,
+			},
+			Confirm: ConfirmConfig{
+				Enabled: true,
+				Actions: []string{
+					"twitch.ban",
+					"twitch.timeout",
+					"obs.scene",
+				},
+				TimeoutSeconds: 15,
+			},
+			Intents: IntentsConfig{
+				Enabled: true,
+			},
+			Replies: RepliesConfig{
+				Enabled: true,
+			},
+		},
+		Server: ServerConfig{
+			Enabled: false,
+			Address: "127.0.0.1:7878",
+			Overlay: OverlayConfig{
+				Enabled:          true,
+				Theme:            "dark",
+				HideAfterSeconds: 8,
+			},
+		},
+		Policy: PolicyConfig{
+			Limits: []ActionLimitConfig{
+				{Action: "twitch.ban", MaxCalls: 3, WindowSeconds: 60},
+				{Action: "twitch.timeout", MaxCalls: 5, WindowSeconds: 60},
+				{Action: "obs.scene", CooldownSeconds: 2},
+			},
+			LiveSafe: LiveSafeConfig{
+				Enabled: false,
+				Actions: []string{
+					"twitch.title",
+					"twitch.category",
+				},
+			},
+		},
+		Audit: AuditConfig{
+			Enabled: true,
+		},
 	}
 }
 
@@ -123,8 +180,17 @@ func ApplyDefaults(cfg *Config) {
 	if cfg.Audio.Device == "" {
 		cfg.Audio.Device = defaults.Audio.Device
 	}
+	if cfg.Audio.PreRollMs == 0 {
+		cfg.Audio.PreRollMs = defaults.Audio.PreRollMs
+	}
+	if cfg.Audio.PostRollMs == 0 {
+		cfg.Audio.PostRollMs = defaults.Audio.PostRollMs
+	}
 
 	// VAD
+	if cfg.Audio.VAD.Detector == "" {
+		cfg.Audio.VAD.Detector = defaults.Audio.VAD.Detector
+	}
 	if cfg.Audio.VAD.SilenceThresholdMs == 0 {
 		cfg.Audio.VAD.SilenceThresholdMs = defaults.Audio.VAD.SilenceThresholdMs
 	}
@@ -140,6 +206,14 @@ func ApplyDefaults(cfg *Config) {
 		cfg.Audio.WakeWord.Threshold = defaults.Audio.WakeWord.Threshold
 	}
 
+	// Barge-in
+	if cfg.Audio.BargeIn.Sensitivity == 0 {
+		cfg.Audio.BargeIn.Sensitivity = defaults.Audio.BargeIn.Sensitivity
+	}
+	if cfg.Audio.BargeIn.MinSpeechMs == 0 {
+		cfg.Audio.BargeIn.MinSpeechMs = defaults.Audio.BargeIn.MinSpeechMs
+	}
+
 	// Hotkey
 	if cfg.Hotkey.Key == "" {
 		cfg.Hotkey.Key = defaults.Hotkey.Key
@@ -233,4 +307,37 @@ func ApplyDefaults(cfg *Config) {
 	if cfg.Sounds.StopRecording == "" {
 		cfg.Sounds.StopRecording = defaults.Sounds.StopRecording
 	}
+
+	// Brain
+	if cfg.Brain.Memory.MaxTurns == 0 {
+		cfg.Brain.Memory.MaxTurns = defaults.Brain.Memory.MaxTurns
+	}
+	if cfg.Brain.Memory.TTLSeconds == 0 {
+		cfg.Brain.Memory.TTLSeconds = defaults.Brain.Memory.TTLSeconds
+	}
+	if len(cfg.Brain.Confirm.Actions) == 0 {
+		cfg.Brain.Confirm.Actions = defaults.Brain.Confirm.Actions
+	}
+	if cfg.Brain.Confirm.TimeoutSeconds == 0 {
+		cfg.Brain.Confirm.TimeoutSeconds = defaults.Brain.Confirm.TimeoutSeconds
+	}
+
+	// Server
+	if cfg.Server.Address == "" {
+		cfg.Server.Address = defaults.Server.Address
+	}
+	if cfg.Server.Overlay.Theme == "" {
+		cfg.Server.Overlay.Theme = defaults.Server.Overlay.Theme
+	}
+	if cfg.Server.Overlay.HideAfterSeconds == 0 {
+		cfg.Server.Overlay.HideAfterSeconds = defaults.Server.Overlay.HideAfterSeconds
+	}
+
+	// Policy
+	if cfg.Policy.Limits == nil {
+		cfg.Policy.Limits = defaults.Policy.Limits
+	}
+	if len(cfg.Policy.LiveSafe.Actions) == 0 {
+		cfg.Policy.LiveSafe.Actions = defaults.Policy.LiveSafe.Actions
+	}
 }
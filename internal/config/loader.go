@@ -89,6 +89,9 @@ func expandEnvVars(cfg *Config) {
 	cfg.TTS.Piper.BinaryPath = os.ExpandEnv(cfg.TTS.Piper.BinaryPath)
 	cfg.TTS.Piper.ModelPath = os.ExpandEnv(cfg.TTS.Piper.ModelPath)
 
+	// Server
+	cfg.Server.Token = os.ExpandEnv(cfg.Server.Token)
+
 	// Paths
 	cfg.General.DataDir = os.ExpandEnv(cfg.General.DataDir)
 
@@ -171,11 +174,20 @@ func Validate(cfg *Config) error {
 	if cfg.Audio.Channels <= 0 {
 		errors = append(errors, "audio channels must be positive")
 	}
+	if cfg.Audio.PreRollMs < 0 || cfg.Audio.PostRollMs < 0 {
+		errors = append(errors, "audio pre_roll_ms and post_roll_ms must not be negative")
+	}
 
 	// Validate VAD config
 	if cfg.Audio.VAD.Sensitivity < 0 || cfg.Audio.VAD.Sensitivity > 1 {
 		errors = append(errors, "VAD sensitivity must be between 0 and 1")
 	}
+	if cfg.Audio.VAD.Detector != "adaptive" && cfg.Audio.VAD.Detector != "energy" {
+		errors = append(errors, fmt.Sprintf("invalid VAD detector: %s (must be 'adaptive' or 'energy')", cfg.Audio.VAD.Detector))
+	}
+	if cfg.Audio.BargeIn.Sensitivity < 0 || cfg.Audio.BargeIn.Sensitivity > 1 {
+		errors = append(errors, "barge-in sensitivity must be between 0 and 1")
+	}
 
 	// Validate hotkey mode
 	if cfg.Hotkey.Enabled {
@@ -189,6 +201,64 @@ func Validate(cfg *Config) error {
 		errors = append(errors, "music default_volume must be between 0 and 1")
 	}
 
+	// Validate brain config
+	if cfg.Brain.Memory.MaxTurns < 0 {
+		errors = append(errors, "brain memory max_turns must not be negative")
+	}
+	for i, t := range cfg.Brain.Replies.Templates {
+		if t.Action == "" {
+			errors = append(errors, fmt.Sprintf("reply template %d has no action", i+1))
+		}
+	}
+
+	// Validate server config; the API can ban users and switch scenes, so it is never left open
+	if cfg.Server.Enabled && cfg.Server.Token == "" {
+		errors = append(errors, "server token required when the control API is enabled")
+	}
+	switch cfg.Server.Overlay.Theme {
+	case "dark", "light", "minimal", "neon":
+	default:
+		errors = append(errors, fmt.Sprintf("invalid overlay theme: %s (must be 'dark', 'light', 'minimal' or 'neon')", cfg.Server.Overlay.Theme))
+	}
+
+	// Validate macros
+	macroNames := make(map[string]bool)
+	for i, m := range cfg.Macros {
+		if m.Name == "" {
+			errors = append(errors, fmt.Sprintf("macro %d has no name", i+1))
+			continue
+		}
+		if macroNames[m.Name] {
+			errors = append(errors, fmt.Sprintf("macro %s is defined more than once", m.Name))
+		}
+		macroNames[m.Name] = true
+		if len(m.Steps) == 0 {
+			errors = append(errors, fmt.Sprintf("macro %s has no steps", m.Name))
+		}
+		for j, step := range m.Steps {
+			if step.Action == "" {
+				errors = append(errors, fmt.Sprintf("macro %s step %d has no action", m.Name, j+1))
+			}
+			if step.DelaySeconds < 0 {
+				errors = append(errors, fmt.Sprintf("macro %s step %d has a negative delay", m.Name, j+1))
+			}
+		}
+	}
+
+	// Validate policy
+	for i, l := range cfg.Policy.Limits {
+		switch {
+		case l.Action == "":
+			errors = append(errors, fmt.Sprintf("policy limit %d has no action", i+1))
+		case l.MaxCalls < 0 || l.WindowSeconds < 0 || l.CooldownSeconds < 0:
+			errors = append(errors, fmt.Sprintf("policy limit for %s must not be negative", l.Action))
+		case l.MaxCalls > 0 && l.WindowSeconds == 0:
+			errors = append(errors, fmt.Sprintf("policy limit for %s needs window_seconds with max_calls", l.Action))
+		case l.MaxCalls == 0 && l.CooldownSeconds == 0:
+			errors = append(errors, fmt.Sprintf("policy limit for %s needs max_calls or cooldown_seconds", l.Action))
+		}
+	}
+
 	if len(errors) > 0 {
 		return fmt.Errorf("validation errors:\n- %s", strings.Join(errors, "\n- "))
 	}
@@ -211,6 +281,11 @@ func Save(cfg *Config, path string) error {
 	v.Set("obs", cfg.OBS)
 	v.Set("music", cfg.Music)
 	v.Set("sounds", cfg.Sounds)
+	v.Set("brain", cfg.Brain)
+	v.Set("server", cfg.Server)
+	v.Set("macros", cfg.Macros)
+	v.Set("policy", cfg.Policy)
+	v.Set("audit", cfg.Audit)
 
 	// Ensure directory exists
 	dir := filepath.Dir(path)
@@ -0,0 +1,119 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jarvisstreamer/jarvis/pkg/logger"
+)
+
+// subscriberBuffer is how many events a slow subscriber may lag behind
+// before new events are dropped for it
+const subscriberBuffer = 256
+
+// Handler receives published events
+type Handler func(Envelope)
+
+// subscription is one subscriber with its own queue and goroutine
+type subscription struct {
+	kinds   map[Kind]bool
+	queue   chan Envelope
+	done    chan struct{}
+	dropped int
+}
+
+// Bus delivers events to subscribers. Publish never blocks: every
+// subscriber is served by its own goroutine and a full queue drops events.
+type Bus struct {
+	mu     sync.Mutex
+	subs   map[int]*subscription
+	nextID int
+}
+
+// NewBus creates an empty event bus
+func NewBus() *Bus {
+	return &Bus{
+		subs: make(map[int]*subscription),
+	}
+}
+
+// Subscribe registers a handler for the given kinds (all kinds if none are
+// given) and returns a function that unsubscribes it
+func (b *Bus) Subscribe(handler Handler, kinds ...Kind) func() {
+	sub := &subscription{
+		queue: make(chan Envelope, subscriberBuffer),
+		done:  make(chan struct{}),
+	}
+	if len(kinds) > 0 {
+		sub.kinds = make(map[Kind]bool, len(kinds))
+		for _, k := range kinds {
+			sub.kinds[k] = true
+		}
+	}
+
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.subs[id] = sub
+	b.mu.Unlock()
+
+	go func() {
+		defer close(sub.done)
+		for env := range sub.queue {
+			handler(env)
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, id)
+			close(sub.queue)
+			b.mu.Unlock()
+			<-sub.done
+		})
+	}
+}
+
+// Publish sends an event to every interested subscriber without blocking
+func (b *Bus) Publish(e Event) {
+	env := Envelope{Kind: e.Kind(), Time: time.Now(), Event: e}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, sub := range b.subs {
+		if sub.kinds != nil && !sub.kinds[env.Kind] {
+			continue
+		}
+		select {
+		case sub.queue <- env:
+		default:
+			sub.dropped++
+			if sub.dropped == 1 || sub.dropped%100 == 0 {
+				// The logger is looked up here because the default bus is created before logger.Init
+				log := logger.Component("events")
+				log.Warn().Int("dropped", sub.dropped).Msg("Event subscriber is too slow, dropping events")
+			}
+		}
+	}
+}
+
+// defaultBus is the process-wide bus used by the package-level functions
+var defaultBus = NewBus()
+
+// Default returns the process-wide bus
+func Default() *Bus {
+	return defaultBus
+}
+
+// Publish sends an event on the process-wide bus
+func Publish(e Event) {
+	defaultBus.Publish(e)
+}
+
+// Subscribe registers a handler on the process-wide bus
+func Subscribe(handler Handler, kinds ...Kind) func() {
+	return defaultBus.Subscribe(handler, kinds...)
+}
@@ -0,0 +1,133 @@
+// Package events provides the typed event bus that components publish to
+// and any number of observers (logs, overlay, API, metrics) subscribe to
+package events
+
+import (
+	"time"
+)
+
+// Kind identifies the type of an event
+type Kind string
+
+// Event kinds
+const (
+	KindStateChanged Kind = "state_changed"
+	KindTranscript   Kind = "transcript"
+	KindAction       Kind = "action"
+	KindResult       Kind = "result"
+	KindReply        Kind = "reply"
+	KindTTSStarted   Kind = "tts_started"
+	KindTTSStopped   Kind = "tts_stopped"
+	KindError        Kind = "error"
+	KindTiming       Kind = "timing"
+)
+
+// Event is implemented by every event payload
+type Event interface {
+	Kind() Kind
+}
+
+// Envelope wraps an event with its kind and publication time
+type Envelope struct {
+	Kind  Kind      `json:"type"`
+	Time  time.Time `json:"time"`
+	Event Event     `json:"data"`
+}
+
+// StateChanged is published when the pipeline changes state
+type StateChanged struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Kind implements Event
+func (StateChanged) Kind() Kind { return KindStateChanged }
+
+// Transcript is published when a command's text is known, from speech or typed
+type Transcript struct {
+	Text   string `json:"text"`
+	Source string `json:"source"` // "voice" or "text"
+}
+
+// Kind implements Event
+func (Transcript) Kind() Kind { return KindTranscript }
+
+// Action is published when a command has been interpreted into an action
+type Action struct {
+	Action  string                 `json:"action"`
+	Params  map[string]interface{} `json:"params,omitempty"`
+	Reply   string                 `json:"reply,omitempty"`
+	Steps   int                    `json:"steps,omitempty"` // Number of steps for a plan
+	Plan    []PlanStep             `json:"plan,omitempty"`
+	OnError string                 `json:"on_error,omitempty"`
+	Source  string                 `json:"source"` // "llm", "intent", "confirmation", "api", "schedule"...
+}
+
+// PlanStep is one step of a plan in an Action event
+type PlanStep struct {
+	Action string                 `json:"action"`
+	Params map[string]interface{} `json:"params,omitempty"`
+	Reply  string                 `json:"reply,omitempty"`
+}
+
+// Kind implements Event
+func (Action) Kind() Kind { return KindAction }
+
+// Result is published after an executor ran an action
+type Result struct {
+	Action   string                 `json:"action"`
+	Params   map[string]interface{} `json:"params,omitempty"`
+	Success  bool                   `json:"success"`
+	Message  string                 `json:"message,omitempty"`
+	Error    string                 `json:"error,omitempty"`
+	Data     map[string]interface{} `json:"data,omitempty"`
+	Duration time.Duration          `json:"duration"`
+}
+
+// Kind implements Event
+func (Result) Kind() Kind { return KindResult }
+
+// Reply is published with the text Jarvis answers with
+type Reply struct {
+	Text string `json:"text"`
+}
+
+// Kind implements Event
+func (Reply) Kind() Kind { return KindReply }
+
+// TTSStarted is published when a TTS provider starts speaking
+type TTSStarted struct {
+	Provider string `json:"provider"`
+	Text     string `json:"text"`
+}
+
+// Kind implements Event
+func (TTSStarted) Kind() Kind { return KindTTSStarted }
+
+// TTSStopped is published when a TTS provider finishes or is interrupted
+type TTSStopped struct {
+	Provider string `json:"provider"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Kind implements Event
+func (TTSStopped) Kind() Kind { return KindTTSStopped }
+
+// Error is published when a stage fails
+type Error struct {
+	Stage string `json:"stage"` // "stt", "llm", "executor", "tts"...
+	Error string `json:"error"`
+}
+
+// Kind implements Event
+func (Error) Kind() Kind { return KindError }
+
+// Timing is published with the duration of a processing stage
+type Timing struct {
+	Stage    string        `json:"stage"` // "stt", "llm", "intent", "execute"...
+	Provider string        `json:"provider,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Kind implements Event
+func (Timing) Kind() Kind { return KindTiming }
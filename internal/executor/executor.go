@@ -4,7 +4,10 @@ package executor
 import (
 	"context"
 	"fmt"
+	"sort"
+	"time"
 
+	"github.com/jarvisstreamer/jarvis/internal/events"
 	"github.com/jarvisstreamer/jarvis/internal/llm"
 )
 
@@ -14,6 +17,8 @@ type Result struct {
 	Message string                 `json:"message,omitempty"`
 	Data    map[string]interface{} `json:"data,omitempty"`
 	Error   string                 `json:"error,omitempty"`
+	Reply   string                 `json:"reply,omitempty"` // Spoken instead of the LLM's reply, for actions that report information
+	Undo    *llm.Action            `json:"-"`               // Reverts the action; set by the registry for Undoable executors
 }
 
 // NewResult creates a successful result
@@ -49,6 +54,9 @@ type Executor interface {
 	// SupportedActions returns the list of actions this executor handles
 	SupportedActions() []string
 
+	// Schema describes the parameters of every supported action
+	Schema() []ActionSpec
+
 	// CanHandle returns true if this executor can handle the given action
 	CanHandle(action string) bool
 
@@ -62,9 +70,60 @@ type Executor interface {
 	Close() error
 }
 
+// LiveContextProvider is implemented by executors that can describe their
+// current state (scene names, current track...) so the LLM uses real names
+type LiveContextProvider interface {
+	LiveContext(ctx context.Context) []llm.ContextItem
+}
+
+type undoingKey struct{}
+
+// Undoing marks a context as reverting earlier actions: the policy's rate
+// limits and cooldowns don't apply, so a mistake can be undone right away
+func Undoing(ctx context.Context) context.Context {
+	return context.WithValue(ctx, undoingKey{}, true)
+}
+
+// Undoable is implemented by executors that can revert their actions.
+// PrepareUndo runs right before Execute and captures the state the action
+// is about to change; it returns the action that restores it, or nil.
+type Undoable interface {
+	PrepareUndo(ctx context.Context, action llm.Action) (*llm.Action, error)
+}
+
+// LiveStatusProvider is implemented by executors that know whether the
+// stream is live, for the policy's live-safe mode
+type LiveStatusProvider interface {
+	IsLive(ctx context.Context) (bool, error)
+}
+
+// Runner executes actions on behalf of executors that run other actions
+// (macros, scheduled jobs); *Registry implements it
+type Runner interface {
+	Execute(ctx context.Context, action llm.Action) (Result, error)
+	Validate(action llm.Action) (llm.Action, error)
+}
+
+// ActionEvent builds the event announcing an action that is about to run
+func ActionEvent(action llm.Action, source string) events.Action {
+	ev := events.Action{
+		Action:  action.Action,
+		Params:  action.Params,
+		Reply:   action.Reply,
+		Steps:   len(action.Steps),
+		OnError: action.OnError,
+		Source:  source,
+	}
+	for _, step := range action.Steps {
+		ev.Plan = append(ev.Plan, events.PlanStep{Action: step.Action, Params: step.Params, Reply: step.Reply})
+	}
+	return ev
+}
+
 // Registry holds all registered executors
 type Registry struct {
 	executors map[string]Executor
+	policy    *Policy
 }
 
 // NewRegistry creates a new executor registry
@@ -79,6 +138,24 @@ func (r *Registry) Register(exec Executor) {
 	r.executors[exec.Name()] = exec
 }
 
+// SetPolicy sets the policy applied to every executed action
+func (r *Registry) SetPolicy(p *Policy) {
+	r.policy = p
+}
+
+// CheckPolicy returns a *PolicyError if the policy would block the action
+// now. Unlike Execute it does not count towards rate limits. Actions no
+// executor handles (built-ins like "none") are never blocked.
+func (r *Registry) CheckPolicy(ctx context.Context, action llm.Action) error {
+	if r.policy == nil {
+		return nil
+	}
+	if _, err := r.FindExecutor(action.Action); err != nil {
+		return nil
+	}
+	return r.policy.Check(action.Action, func() bool { return r.isLive(ctx) })
+}
+
 // Get returns an executor by name
 func (r *Registry) Get(name string) (Executor, bool) {
 	exec, ok := r.executors[name]
@@ -97,17 +174,145 @@ func (r *Registry) FindExecutor(action string) (Executor, error) {
 
 // Execute finds the appropriate executor and executes the action
 func (r *Registry) Execute(ctx context.Context, action llm.Action) (Result, error) {
+	start := time.Now()
+	result, err := r.execute(ctx, action)
+
+	ev := events.Result{
+		Action:   action.Action,
+		Params:   action.Params,
+		Success:  err == nil && result.Success,
+		Message:  result.Message,
+		Error:    result.Error,
+		Data:     result.Data,
+		Duration: time.Since(start),
+	}
+	if err != nil && ev.Error == "" {
+		ev.Error = err.Error()
+	}
+	events.Publish(ev)
+
+	return result, err
+}
+
+// execute validates an action and runs it on its executor
+func (r *Registry) execute(ctx context.Context, action llm.Action) (Result, error) {
 	exec, err := r.FindExecutor(action.Action)
 	if err != nil {
 		return NewErrorResult(err), err
 	}
 
+	action, err = validateAction(exec, action)
+	if err != nil {
+		return NewErrorResult(err), err
+	}
+
 	if !exec.IsAvailable() {
 		err := fmt.Errorf("executor %s is not available", exec.Name())
 		return NewErrorResult(err), err
 	}
 
-	return exec.Execute(ctx, action)
+	if r.policy != nil {
+		allow := r.policy.Allow
+		if undoing, _ := ctx.Value(undoingKey{}).(bool); undoing {
+			allow = r.policy.CheckAccess
+		}
+		if err := allow(action.Action, func() bool { return r.isLive(ctx) }); err != nil {
+			return NewErrorResult(err), err
+		}
+	}
+
+	// The prior state is best effort: without it the action still runs, it just can't be undone
+	var undo *llm.Action
+	if u, ok := exec.(Undoable); ok {
+		undo, _ = u.PrepareUndo(ctx, action)
+	}
+
+	result, err := exec.Execute(ctx, action)
+	if err == nil && result.Success && result.Undo == nil {
+		result.Undo = undo
+	}
+	return result, err
+}
+
+// isLive returns true if any available executor reports the stream as live
+func (r *Registry) isLive(ctx context.Context) bool {
+	for _, exec := range r.sorted() {
+		provider, ok := exec.(LiveStatusProvider)
+		if !ok || !exec.IsAvailable() {
+			continue
+		}
+		if live, err := provider.IsLive(ctx); err == nil && live {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks an action's params against its executor's schema and
+// returns the action with coerced params. Errors are *ValidationError
+// for bad params.
+func (r *Registry) Validate(action llm.Action) (llm.Action, error) {
+	exec, err := r.FindExecutor(action.Action)
+	if err != nil {
+		return action, err
+	}
+	return validateAction(exec, action)
+}
+
+// GetAllSpecs returns the action specs of all executors
+func (r *Registry) GetAllSpecs() []ActionSpec {
+	var specs []ActionSpec
+	for _, exec := range r.executors {
+		specs = append(specs, exec.Schema()...)
+	}
+	return specs
+}
+
+// PromptActions describes the actions of every available executor for the system prompt
+func (r *Registry) PromptActions() []llm.PromptAction {
+	var actions []llm.PromptAction
+	for _, exec := range r.sorted() {
+		if !exec.IsAvailable() {
+			continue
+		}
+		for _, spec := range exec.Schema() {
+			actions = append(actions, spec.PromptAction())
+		}
+	}
+	return actions
+}
+
+// LiveContext collects the live state of every available executor
+func (r *Registry) LiveContext(ctx context.Context) []llm.ContextItem {
+	var items []llm.ContextItem
+	for _, exec := range r.sorted() {
+		provider, ok := exec.(LiveContextProvider)
+		if !ok || !exec.IsAvailable() {
+			continue
+		}
+		items = append(items, provider.LiveContext(ctx)...)
+	}
+	return items
+}
+
+// Executors returns the registered executors ordered by name
+func (r *Registry) Executors() []Executor {
+	return r.sorted()
+}
+
+// sorted returns the executors ordered by name
+func (r *Registry) sorted() []Executor {
+	names := make([]string, 0, len(r.executors))
+	for name := range r.executors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	execs := make([]Executor, len(names))
+	for i, name := range names {
+		execs[i] = r.executors[name]
+	}
+	return execs
 }
 
 // GetAllActions returns all supported actions from all executors
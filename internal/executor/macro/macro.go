@@ -0,0 +1,361 @@
+// Package macro provides user-defined routines that run a list of actions
+package macro
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jarvisstreamer/jarvis/internal/config"
+	"github.com/jarvisstreamer/jarvis/internal/events"
+	"github.com/jarvisstreamer/jarvis/internal/executor"
+	"github.com/jarvisstreamer/jarvis/internal/llm"
+	"github.com/jarvisstreamer/jarvis/pkg/logger"
+	"github.com/rs/zerolog"
+)
+
+// ActionPrefix is the prefix of every macro action
+const ActionPrefix = "macro."
+
+// MaxDepth bounds macros that call other macros, so a cycle can't run forever
+const MaxDepth = 4
+
+type depthKey struct{}
+
+// placeholderRe matches ${name} placeholders left without a value
+var placeholderRe = regexp.MustCompile(`\$\{[^}]*\}`)
+
+// Executor runs the macros defined in the config
+type Executor struct {
+	macros map[string]config.MacroConfig
+	order  []string
+	runner executor.Runner
+	log    zerolog.Logger
+
+	// Delayed steps run in the background until Close
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewExecutor creates a macro executor whose steps run through runner
+func NewExecutor(cfgs []config.MacroConfig, runner executor.Runner) *Executor {
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &Executor{
+		macros: make(map[string]config.MacroConfig, len(cfgs)),
+		runner: runner,
+		log:    logger.Component("macro"),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	for _, m := range cfgs {
+		if _, dup := e.macros[m.Name]; !dup {
+			e.order = append(e.order, m.Name)
+		}
+		e.macros[m.Name] = m
+	}
+	return e
+}
+
+// Name returns the executor name
+func (e *Executor) Name() string {
+	return "macro"
+}
+
+// SupportedActions returns one action per macro
+func (e *Executor) SupportedActions() []string {
+	actions := make([]string, len(e.order))
+	for i, name := range e.order {
+		actions[i] = ActionPrefix + name
+	}
+	return actions
+}
+
+// Schema describes every macro and the parameters its steps reference
+func (e *Executor) Schema() []executor.ActionSpec {
+	specs := make([]executor.ActionSpec, 0, len(e.order))
+	for _, name := range e.order {
+		m := e.macros[name]
+
+		description := m.Description
+		if description == "" {
+			description = fmt.Sprintf("Ejecutar la rutina %s", strings.ReplaceAll(name, "_", " "))
+		}
+
+		params := make([]executor.ParamSpec, len(m.Params))
+		for i, p := range m.Params {
+			paramType := executor.ParamType(p.Type)
+			if paramType == "" {
+				paramType = executor.ParamString
+			}
+			params[i] = executor.ParamSpec{
+				Name:        p.Name,
+				Type:        paramType,
+				Description: p.Description,
+				Required:    p.Required,
+				Default:     p.Default,
+			}
+		}
+
+		specs = append(specs, executor.ActionSpec{Name: ActionPrefix + name, Description: description, Params: params})
+	}
+	return specs
+}
+
+// CanHandle returns true for the actions of configured macros
+func (e *Executor) CanHandle(action string) bool {
+	if !strings.HasPrefix(action, ActionPrefix) {
+		return false
+	}
+	_, ok := e.macros[strings.TrimPrefix(action, ActionPrefix)]
+	return ok
+}
+
+// Execute runs the steps of a macro in order. Steps up to the first delay
+// run now; the rest run in the background, so a routine with pauses doesn't
+// hold up the command until it ends.
+func (e *Executor) Execute(ctx context.Context, action llm.Action) (executor.Result, error) {
+	name := strings.TrimPrefix(action.Action, ActionPrefix)
+	m, ok := e.macros[name]
+	if !ok {
+		return executor.NewErrorResult(fmt.Errorf("unknown macro: %s", name)), nil
+	}
+
+	depth, _ := ctx.Value(depthKey{}).(int)
+	if depth >= MaxDepth {
+		return executor.NewErrorResult(fmt.Errorf("macro %s nests too deeply, check for macros calling each other", name)), nil
+	}
+	ctx = context.WithValue(ctx, depthKey{}, depth+1)
+
+	e.log.Info().Str("macro", name).Int("steps", len(m.Steps)).Msg("Running macro")
+
+	steps := Steps(m, action.Params)
+	split := len(steps)
+	for i, step := range m.Steps {
+		if step.Delay() > 0 {
+			split = i
+			break
+		}
+	}
+
+	run := e.runSteps(ctx, m, steps[:split], 0)
+	if run.stopped {
+		result := executor.NewErrorResult(fmt.Errorf("macro %s stopped at %s", name, run.failures[0]))
+		result.Undo = undoPlan(run.undo)
+		return result, run.blockedError(name)
+	}
+	if split < len(steps) {
+		e.runLater(ctx, m, steps[split:], split)
+	}
+	if len(run.failures) > 0 {
+		result := executor.NewErrorResult(fmt.Errorf("macro %s finished with errors: %s", name, strings.Join(run.failures, "; ")))
+		result.Undo = undoPlan(run.undo)
+		return result, run.blockedError(name)
+	}
+
+	message := fmt.Sprintf("Macro %s completed", name)
+	if split < len(steps) {
+		message = fmt.Sprintf("Macro %s started, %d delayed steps pending", name, len(steps)-split)
+	}
+	result := executor.NewResultWithData(message, map[string]interface{}{
+		"steps":   len(steps),
+		"pending": len(steps) - split,
+	})
+	result.Undo = undoPlan(run.undo)
+	return result, nil
+}
+
+// stepsRun is the outcome of running some steps of a macro
+type stepsRun struct {
+	undo     []llm.Action // Inverses of the steps that ran
+	failures []string
+	stopped  bool  // A step failed and the macro stops on errors
+	blocked  error // The first step blocked by the policy, wrapping its *executor.PolicyError
+}
+
+// blockedError returns the error of a step blocked by the policy, or nil.
+// It is returned besides the failed result so the caller can speak the
+// policy's reason instead of a generic failure.
+func (r stepsRun) blockedError(name string) error {
+	if r.blocked == nil {
+		return nil
+	}
+	return fmt.Errorf("macro %s stopped at %w", name, r.blocked)
+}
+
+// runSteps runs steps of a macro in order, waiting each one's delay;
+// first is the position of steps[0] in the macro
+func (e *Executor) runSteps(ctx context.Context, m config.MacroConfig, steps []llm.Action, first int) stepsRun {
+	continueOnError := strings.EqualFold(m.OnError, llm.PlanContinueOnError)
+
+	var run stepsRun
+	for i, step := range steps {
+		n := first + i + 1
+		if err := wait(ctx, m.Steps[first+i].Delay()); err != nil {
+			run.failures = append(run.failures, fmt.Sprintf("step %d: cancelled", n))
+			run.stopped = true
+			return run
+		}
+
+		result, err := e.runner.Execute(ctx, step)
+		if err == nil && !result.Success {
+			err = errors.New(result.Error)
+		}
+		// A failed nested macro still reverts the steps it ran
+		if result.Undo != nil {
+			run.undo = append(run.undo, *result.Undo)
+		}
+		if err == nil {
+			continue
+		}
+
+		e.log.Warn().Err(err).Str("macro", m.Name).Int("step", n).Str("action", step.Action).Msg("Macro step failed")
+		err = fmt.Errorf("step %d (%s): %w", n, step.Action, err)
+		run.failures = append(run.failures, err.Error())
+		var perr *executor.PolicyError
+		if run.blocked == nil && errors.As(err, &perr) {
+			run.blocked = err
+		}
+		if !continueOnError {
+			run.stopped = true
+			return run
+		}
+	}
+	return run
+}
+
+// runLater runs the delayed steps of a macro in the background. They
+// outlive the command that started the macro, but not the executor; since
+// that command has already returned, they can't be undone.
+func (e *Executor) runLater(ctx context.Context, m config.MacroConfig, steps []llm.Action, first int) {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(e.ctx, cancel)
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		defer stop()
+		defer cancel()
+
+		run := e.runSteps(ctx, m, steps, first)
+		if len(run.failures) > 0 {
+			err := fmt.Errorf("macro %s: %s", m.Name, strings.Join(run.failures, "; "))
+			events.Publish(events.Error{Stage: "macro", Error: err.Error()})
+			return
+		}
+		e.log.Info().Str("macro", m.Name).Msg("Macro completed")
+	}()
+}
+
+// Steps returns the actions a macro runs for the given params
+func Steps(m config.MacroConfig, params map[string]interface{}) []llm.Action {
+	steps := make([]llm.Action, len(m.Steps))
+	for i, step := range m.Steps {
+		steps[i] = llm.Action{
+			Action: step.Action,
+			Params: expandParams(step.Params, params),
+		}
+	}
+	return steps
+}
+
+// IsAvailable returns true; each step checks its own executor
+func (e *Executor) IsAvailable() bool {
+	return true
+}
+
+// Close cancels the delayed steps still pending
+func (e *Executor) Close() error {
+	e.cancel()
+	e.wg.Wait()
+	return nil
+}
+
+// undoPlan reverts the steps of a macro, last step first
+func undoPlan(steps []llm.Action) *llm.Action {
+	switch len(steps) {
+	case 0:
+		return nil
+	case 1:
+		return &steps[0]
+	}
+
+	plan := &llm.Action{Action: llm.ActionPlan, OnError: llm.PlanContinueOnError}
+	for i := len(steps) - 1; i >= 0; i-- {
+		plan.Steps = append(plan.Steps, steps[i])
+	}
+	return plan
+}
+
+// wait sleeps for d unless the context is cancelled first
+func wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// expandParams replaces ${name} placeholders in step params with the macro's params
+func expandParams(params map[string]interface{}, values map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		if expanded := expandValue(v, values); expanded != nil {
+			out[k] = expanded
+		}
+	}
+	return out
+}
+
+// expandValue expands placeholders in strings, recursing into maps and lists.
+// A string that is exactly one placeholder takes the param's value and type,
+// so "${volume}" stays a number; a missing param yields nil.
+func expandValue(v interface{}, values map[string]interface{}) interface{} {
+	switch val := v.(type) {
+	case string:
+		if strings.HasPrefix(val, "${") && strings.HasSuffix(val, "}") && strings.Count(val, "${") == 1 {
+			return values[val[2:len(val)-1]]
+		}
+		for name, value := range values {
+			val = strings.ReplaceAll(val, "${"+name+"}", formatValue(value))
+		}
+		return placeholderRe.ReplaceAllString(val, "")
+	case map[string]interface{}:
+		return expandParams(val, values)
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = expandValue(item, values)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// formatValue formats a param value for use inside a string
+func formatValue(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(val)
+	}
+}
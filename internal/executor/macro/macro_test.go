@@ -0,0 +1,206 @@
+package macro
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jarvisstreamer/jarvis/internal/config"
+	"github.com/jarvisstreamer/jarvis/internal/executor"
+	"github.com/jarvisstreamer/jarvis/internal/llm"
+)
+
+// fakeRunner records the actions it runs; "fail" fails, "blocked" is
+// refused by the policy and every other action is undone by "undo.<action>"
+type fakeRunner struct {
+	mu   sync.Mutex
+	ran  []string
+	done chan string
+}
+
+func (r *fakeRunner) Execute(ctx context.Context, action llm.Action) (executor.Result, error) {
+	r.mu.Lock()
+	r.ran = append(r.ran, action.Action)
+	r.mu.Unlock()
+	if r.done != nil {
+		defer func() { r.done <- action.Action }()
+	}
+
+	switch action.Action {
+	case "fail":
+		return executor.NewErrorResult(errors.New("boom")), nil
+	case "blocked":
+		err := &executor.PolicyError{Action: action.Action, Reason: "No puedo hacer blocked en directo."}
+		return executor.NewErrorResult(err), err
+	}
+	result := executor.NewResult("ok")
+	result.Undo = &llm.Action{Action: "undo." + action.Action}
+	return result, nil
+}
+
+func (r *fakeRunner) Validate(action llm.Action) (llm.Action, error) {
+	return action, nil
+}
+
+func (r *fakeRunner) actions() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.ran...)
+}
+
+func steps(actions ...string) []config.MacroStepConfig {
+	out := make([]config.MacroStepConfig, len(actions))
+	for i, a := range actions {
+		out[i] = config.MacroStepConfig{Action: a}
+	}
+	return out
+}
+
+func undoActions(undo *llm.Action) []string {
+	if undo == nil {
+		return nil
+	}
+	if !undo.IsPlan() {
+		return []string{undo.Action}
+	}
+	var out []string
+	for _, s := range undo.Steps {
+		out = append(out, s.Action)
+	}
+	return out
+}
+
+func TestExecuteUndoesStepsInReverse(t *testing.T) {
+	runner := &fakeRunner{}
+	e := NewExecutor([]config.MacroConfig{{Name: "pausa", Steps: steps("a", "b")}}, runner)
+	defer e.Close()
+
+	result, err := e.Execute(context.Background(), llm.Action{Action: "macro.pausa"})
+	if err != nil || !result.Success {
+		t.Fatalf("Execute = %+v, %v", result, err)
+	}
+	if got, want := undoActions(result.Undo), []string{"undo.b", "undo.a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("undo = %v, want %v", got, want)
+	}
+}
+
+func TestExecuteFailureKeepsPartialUndo(t *testing.T) {
+	runner := &fakeRunner{}
+	e := NewExecutor([]config.MacroConfig{{Name: "pausa", Steps: steps("a", "b", "fail", "c")}}, runner)
+	defer e.Close()
+
+	result, _ := e.Execute(context.Background(), llm.Action{Action: "macro.pausa"})
+	if result.Success {
+		t.Fatal("macro with a failing step succeeded")
+	}
+	if got, want := runner.actions(), []string{"a", "b", "fail"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ran %v, want %v", got, want)
+	}
+	if got, want := undoActions(result.Undo), []string{"undo.b", "undo.a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("undo = %v, want %v", got, want)
+	}
+}
+
+func TestExecuteSurfacesPolicyError(t *testing.T) {
+	for _, onError := range []string{llm.PlanStopOnError, llm.PlanContinueOnError} {
+		runner := &fakeRunner{}
+		m := config.MacroConfig{Name: "pausa", Steps: steps("a", "blocked", "fail"), OnError: onError}
+		e := NewExecutor([]config.MacroConfig{m}, runner)
+
+		result, err := e.Execute(context.Background(), llm.Action{Action: "macro.pausa"})
+		e.Close()
+
+		var perr *executor.PolicyError
+		if !errors.As(err, &perr) || perr.Reason != "No puedo hacer blocked en directo." {
+			t.Errorf("%s: Execute error = %v, want the step's policy error", onError, err)
+		}
+		if result.Success || !reflect.DeepEqual(undoActions(result.Undo), []string{"undo.a"}) {
+			t.Errorf("%s: result = %+v, want a failure that undoes the first step", onError, result)
+		}
+	}
+}
+
+func TestExecuteContinueOnError(t *testing.T) {
+	runner := &fakeRunner{}
+	m := config.MacroConfig{Name: "pausa", Steps: steps("a", "fail", "c"), OnError: llm.PlanContinueOnError}
+	e := NewExecutor([]config.MacroConfig{m}, runner)
+	defer e.Close()
+
+	result, _ := e.Execute(context.Background(), llm.Action{Action: "macro.pausa"})
+	if result.Success {
+		t.Fatal("macro with a failing step succeeded")
+	}
+	if got, want := runner.actions(), []string{"a", "fail", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ran %v, want %v", got, want)
+	}
+	if got, want := undoActions(result.Undo), []string{"undo.c", "undo.a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("undo = %v, want %v", got, want)
+	}
+}
+
+func TestExecuteDelayedStepsRunInBackground(t *testing.T) {
+	runner := &fakeRunner{done: make(chan string, 3)}
+	m := config.MacroConfig{Name: "raid", Steps: steps("a", "b", "c")}
+	m.Steps[1].DelaySeconds = 0.05
+	e := NewExecutor([]config.MacroConfig{m}, runner)
+	defer e.Close()
+
+	start := time.Now()
+	result, err := e.Execute(context.Background(), llm.Action{Action: "macro.raid"})
+	if err != nil || !result.Success {
+		t.Fatalf("Execute = %+v, %v", result, err)
+	}
+	if elapsed := time.Since(start); elapsed >= 50*time.Millisecond {
+		t.Errorf("Execute waited the delay (%v)", elapsed)
+	}
+	if got := undoActions(result.Undo); !reflect.DeepEqual(got, []string{"undo.a"}) {
+		t.Errorf("undo = %v, want only the steps that ran", got)
+	}
+
+	for _, want := range []string{"a", "b", "c"} {
+		select {
+		case got := <-runner.done:
+			if got != want {
+				t.Fatalf("ran %s, want %s", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("step %s never ran", want)
+		}
+	}
+}
+
+func TestCloseCancelsDelayedSteps(t *testing.T) {
+	runner := &fakeRunner{}
+	m := config.MacroConfig{Name: "raid", Steps: steps("a", "b")}
+	m.Steps[1].DelaySeconds = 60
+	e := NewExecutor([]config.MacroConfig{m}, runner)
+
+	if _, err := e.Execute(context.Background(), llm.Action{Action: "macro.raid"}); err != nil {
+		t.Fatal(err)
+	}
+	e.Close()
+	if got := runner.actions(); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("ran %v, want only the step before the delay", got)
+	}
+}
+
+func TestSteps(t *testing.T) {
+	m := config.MacroConfig{Steps: []config.MacroStepConfig{{
+		Action: "obs.volume",
+		Params: map[string]interface{}{
+			"source": "${source}",
+			"volume": "${volume}",
+			"label":  "Volumen de ${source} al ${volume}",
+			"extra":  "${missing}",
+		},
+	}}}
+
+	got := Steps(m, map[string]interface{}{"source": "Mic", "volume": 0.5})
+	want := map[string]interface{}{"source": "Mic", "volume": 0.5, "label": "Volumen de Mic al 0.5"}
+	if len(got) != 1 || !reflect.DeepEqual(got[0].Params, want) {
+		t.Errorf("Steps = %+v, want params %v", got, want)
+	}
+}
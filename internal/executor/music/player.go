@@ -69,6 +69,38 @@ func (e *Executor) SupportedActions() []string {
 	}
 }
 
+// Schema describes the parameters of every music action
+func (e *Executor) Schema() []executor.ActionSpec {
+	return []executor.ActionSpec{
+		{
+			Name:        "music.play",
+			Description: "Reproducir música",
+			Params: []executor.ParamSpec{
+				{Name: "query", Type: executor.ParamString, Description: "artista, canción o género a buscar"},
+			},
+		},
+		{Name: "music.pause", Description: "Pausar la música"},
+		{Name: "music.resume", Description: "Reanudar la música"},
+		{
+			Name:        "music.next",
+			Description: "Pasar a la siguiente canción",
+			Examples:    []llm.PromptExample{{Phrase: "siguiente", Reply: "Vamos con la siguiente"}},
+		},
+		{Name: "music.previous", Description: "Volver a la canción anterior"},
+		{
+			Name:        "music.volume",
+			Description: "Cambiar el volumen de la música",
+			Params: []executor.ParamSpec{
+				{Name: "volume", Type: executor.ParamNumber, Required: true, Min: executor.Limit(0), Max: executor.Limit(1), Description: "nivel de volumen"},
+			},
+			Examples: []llm.PromptExample{
+				{Phrase: "sube el volumen de la música", Params: map[string]interface{}{"volume": 0.8}, Reply: "Volumen subido al 80%"},
+			},
+		},
+		{Name: "music.stop", Description: "Detener la música"},
+	}
+}
+
 // CanHandle returns true if this executor can handle the action
 func (e *Executor) CanHandle(action string) bool {
 	return strings.HasPrefix(action, "music.")
@@ -445,6 +477,25 @@ func (e *Executor) GetCurrentTrack() string {
 	return filepath.Base(e.playlist[e.currentIdx])
 }
 
+// LiveContext reports the current track and playback state
+func (e *Executor) LiveContext(ctx context.Context) []llm.ContextItem {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.isPlaying || e.currentIdx >= len(e.playlist) {
+		return []llm.ContextItem{{Label: "Música", Value: "detenida"}}
+	}
+
+	state := "sonando"
+	if e.isPaused {
+		state = "en pausa"
+	}
+	return []llm.ContextItem{
+		{Label: "Canción actual", Value: filepath.Base(e.playlist[e.currentIdx])},
+		{Label: "Música", Value: fmt.Sprintf("%s, volumen %.0f%%", state, e.volume*100)},
+	}
+}
+
 // IsPlaying returns whether music is currently playing
 func (e *Executor) IsPlaying() bool {
 	e.mu.Lock()
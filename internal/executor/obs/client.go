@@ -7,6 +7,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"math"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -123,6 +124,52 @@ func (e *Executor) SupportedActions() []string {
 	}
 }
 
+// Schema describes the parameters of every OBS action
+func (e *Executor) Schema() []executor.ActionSpec {
+	source := executor.ParamSpec{Name: "source", Type: executor.ParamString, Required: true, Description: "nombre de la fuente"}
+	return []executor.ActionSpec{
+		{
+			Name:        "obs.scene",
+			Description: "Cambiar de escena",
+			Params: []executor.ParamSpec{
+				{Name: "scene", Type: executor.ParamString, Required: true, Description: "nombre de la escena"},
+			},
+			Examples: []llm.PromptExample{
+				{Phrase: "pon la escena de solo charlando", Params: map[string]interface{}{"scene": "Solo charlando"}, Reply: "Ya está, poniendo 'solo charlando'"},
+			},
+		},
+		{Name: "obs.source.show", Description: "Mostrar una fuente", Params: []executor.ParamSpec{source}},
+		{Name: "obs.source.hide", Description: "Ocultar una fuente", Params: []executor.ParamSpec{source}},
+		{
+			Name:        "obs.volume",
+			Description: "Cambiar el volumen de una fuente",
+			Params: []executor.ParamSpec{
+				source,
+				{Name: "volume", Type: executor.ParamNumber, Min: executor.Limit(0), Max: executor.Limit(1), Description: "nivel de volumen"},
+				{Name: "volume_db", Type: executor.ParamNumber, Min: executor.Limit(minVolumeDb), Max: executor.Limit(maxVolumeDb), Description: "volumen exacto en dB, en lugar de volume"},
+			},
+			OneOf: []string{"volume", "volume_db"},
+		},
+		{
+			Name:        "obs.mute",
+			Description: "Silenciar una fuente de audio",
+			Params:      []executor.ParamSpec{source},
+			Examples: []llm.PromptExample{
+				{Phrase: "silencia el audio del escritorio", Params: map[string]interface{}{"source": "Audio del escritorio"}, Reply: "Dale, sin audio del escritorio"},
+			},
+		},
+		{Name: "obs.unmute", Description: "Activar el audio de una fuente", Params: []executor.ParamSpec{source}},
+		{
+			Name:        "obs.text",
+			Description: "Cambiar el texto de una fuente de texto",
+			Params: []executor.ParamSpec{
+				source,
+				{Name: "text", Type: executor.ParamString, Required: true, Description: "texto a mostrar"},
+			},
+		},
+	}
+}
+
 // CanHandle returns true if this executor can handle the action
 func (e *Executor) CanHandle(action string) bool {
 	return strings.HasPrefix(action, "obs.")
@@ -379,33 +426,9 @@ func (e *Executor) setSourceVisibility(ctx context.Context, action llm.Action, v
 		return executor.NewErrorResult(fmt.Errorf("source name is required")), nil
 	}
 
-	// Get current scene
-	sceneResp, err := e.sendRequest(ctx, "GetCurrentProgramScene", nil)
-	if err != nil {
-		return executor.NewErrorResult(err), err
-	}
-
-	sceneName, ok := sceneResp.ResponseData["currentProgramSceneName"].(string)
-	if !ok {
-		return executor.NewErrorResult(fmt.Errorf("could not get current scene")), nil
-	}
-
-	// Get scene item ID
-	itemResp, err := e.sendRequest(ctx, "GetSceneItemId", map[string]interface{}{
-		"sceneName":  sceneName,
-		"sourceName": source,
-	})
+	sceneName, sceneItemId, err := e.sceneItem(ctx, source)
 	if err != nil {
-		return executor.NewErrorResult(err), err
-	}
-
-	if !itemResp.RequestStatus.Result {
-		return executor.NewErrorResult(fmt.Errorf("source not found: %s", source)), nil
-	}
-
-	sceneItemId, ok := itemResp.ResponseData["sceneItemId"].(float64)
-	if !ok {
-		return executor.NewErrorResult(fmt.Errorf("could not get scene item ID")), nil
+		return executor.NewErrorResult(err), nil
 	}
 
 	// Set visibility
@@ -413,7 +436,7 @@ func (e *Executor) setSourceVisibility(ctx context.Context, action llm.Action, v
 
 	resp, err := e.sendRequest(ctx, "SetSceneItemEnabled", map[string]interface{}{
 		"sceneName":        sceneName,
-		"sceneItemId":      int(sceneItemId),
+		"sceneItemId":      sceneItemId,
 		"sceneItemEnabled": visible,
 	})
 	if err != nil {
@@ -431,31 +454,63 @@ func (e *Executor) setSourceVisibility(ctx context.Context, action llm.Action, v
 	return executor.NewResult(fmt.Sprintf("Source %s %s", source, action_str)), nil
 }
 
-// setVolume changes the volume of a source
+// sceneItem finds a source in the current program scene
+func (e *Executor) sceneItem(ctx context.Context, source string) (string, int, error) {
+	sceneResp, err := e.sendRequest(ctx, "GetCurrentProgramScene", nil)
+	if err != nil {
+		return "", 0, err
+	}
+
+	sceneName, ok := sceneResp.ResponseData["currentProgramSceneName"].(string)
+	if !ok {
+		return "", 0, fmt.Errorf("could not get current scene")
+	}
+
+	itemResp, err := e.sendRequest(ctx, "GetSceneItemId", map[string]interface{}{
+		"sceneName":  sceneName,
+		"sourceName": source,
+	})
+	if err != nil {
+		return "", 0, err
+	}
+
+	if !itemResp.RequestStatus.Result {
+		return "", 0, fmt.Errorf("source not found: %s", source)
+	}
+
+	sceneItemId, ok := itemResp.ResponseData["sceneItemId"].(float64)
+	if !ok {
+		return "", 0, fmt.Errorf("could not get scene item ID")
+	}
+
+	return sceneName, int(sceneItemId), nil
+}
+
+// setVolume changes the volume of a source, from a 0-1 level or exactly in dB
 func (e *Executor) setVolume(ctx context.Context, action llm.Action) (executor.Result, error) {
 	source := action.GetStringParam("source")
 	if source == "" {
 		return executor.NewErrorResult(fmt.Errorf("source name is required")), nil
 	}
 
-	volume := action.GetFloatParam("volume")
-	if volume < 0 {
-		volume = 0
-	}
-	if volume > 1 {
-		volume = 1
-	}
-
-	// Convert to dB (0-1 linear to dB scale)
-	// OBS uses dB, roughly: dB = 20 * log10(linear)
 	var volumeDb float64
-	if volume <= 0 {
-		volumeDb = -100 // Effectively muted
+	var message string
+	if _, ok := action.Params["volume_db"]; ok {
+		volumeDb = math.Max(minVolumeDb, math.Min(maxVolumeDb, action.GetFloatParam("volume_db")))
+		message = fmt.Sprintf("Volume of %s set to %.1f dB", source, volumeDb)
 	} else {
-		volumeDb = 20 * (volume - 1) * 2 // Simplified mapping
+		volume := action.GetFloatParam("volume")
+		if volume < 0 {
+			volume = 0
+		}
+		if volume > 1 {
+			volume = 1
+		}
+		volumeDb = volumeToDb(volume)
+		message = fmt.Sprintf("Volume of %s set to %.0f%%", source, volume*100)
 	}
 
-	e.log.Info().Str("source", source).Float64("volume", volume).Msg("Setting volume")
+	e.log.Info().Str("source", source).Float64("volume_db", volumeDb).Msg("Setting volume")
 
 	resp, err := e.sendRequest(ctx, "SetInputVolume", map[string]interface{}{
 		"inputName":     source,
@@ -469,7 +524,22 @@ func (e *Executor) setVolume(ctx context.Context, action llm.Action) (executor.R
 		return executor.NewErrorResult(fmt.Errorf("failed to set volume: %s", resp.RequestStatus.Comment)), nil
 	}
 
-	return executor.NewResult(fmt.Sprintf("Volume of %s set to %.0f%%", source, volume*100)), nil
+	return executor.NewResult(message), nil
+}
+
+// OBS input volume range, in dB
+const (
+	minVolumeDb = -100.0
+	maxVolumeDb = 26.0
+)
+
+// volumeToDb converts a 0-1 volume to OBS dB.
+// OBS uses dB, roughly: dB = 20 * log10(linear); this is a simplified mapping.
+func volumeToDb(volume float64) float64 {
+	if volume <= 0 {
+		return minVolumeDb // Effectively muted
+	}
+	return 20 * (volume - 1) * 2
 }
 
 // setMute mutes or unmutes a source
@@ -528,6 +598,136 @@ func (e *Executor) setText(ctx context.Context, action llm.Action) (executor.Res
 	return executor.NewResult("Text updated"), nil
 }
 
+// LiveContext reports the current scene and the names of scenes and inputs
+func (e *Executor) LiveContext(ctx context.Context) []llm.ContextItem {
+	var items []llm.ContextItem
+
+	if resp, err := e.sendRequest(ctx, "GetSceneList", nil); err == nil && resp.RequestStatus.Result {
+		if current, ok := resp.ResponseData["currentProgramSceneName"].(string); ok && current != "" {
+			items = append(items, llm.ContextItem{Label: "Escena actual de OBS", Value: current})
+		}
+		if scenes := namesOf(resp.ResponseData["scenes"], "sceneName"); len(scenes) > 0 {
+			items = append(items, llm.ContextItem{Label: "Escenas de OBS", Value: strings.Join(scenes, ", ")})
+		}
+	}
+
+	if resp, err := e.sendRequest(ctx, "GetInputList", nil); err == nil && resp.RequestStatus.Result {
+		if inputs := namesOf(resp.ResponseData["inputs"], "inputName"); len(inputs) > 0 {
+			items = append(items, llm.ContextItem{Label: "Fuentes de OBS", Value: strings.Join(inputs, ", ")})
+		}
+	}
+
+	return items
+}
+
+// PrepareUndo captures the scene or input state an action is about to change
+// and returns the action that restores it
+func (e *Executor) PrepareUndo(ctx context.Context, action llm.Action) (*llm.Action, error) {
+	source := action.GetStringParam("source")
+
+	switch action.Action {
+	case "obs.scene":
+		data, err := e.query(ctx, "GetCurrentProgramScene", nil)
+		if err != nil {
+			return nil, err
+		}
+		scene, _ := data["currentProgramSceneName"].(string)
+		if scene == "" || scene == action.GetStringParam("scene") {
+			return nil, nil
+		}
+		return &llm.Action{Action: "obs.scene", Params: map[string]interface{}{"scene": scene}}, nil
+
+	case "obs.mute", "obs.unmute":
+		data, err := e.query(ctx, "GetInputMute", map[string]interface{}{"inputName": source})
+		if err != nil {
+			return nil, err
+		}
+		inverse := "obs.unmute"
+		if muted, _ := data["inputMuted"].(bool); muted {
+			inverse = "obs.mute"
+		}
+		return &llm.Action{Action: inverse, Params: map[string]interface{}{"source": source}}, nil
+
+	case "obs.volume":
+		data, err := e.query(ctx, "GetInputVolume", map[string]interface{}{"inputName": source})
+		if err != nil {
+			return nil, err
+		}
+		db, ok := data["inputVolumeDb"].(float64)
+		if !ok {
+			return nil, fmt.Errorf("could not get volume of %s", source)
+		}
+		// Restore the exact level; the 0-1 scale can't represent all of them
+		return &llm.Action{Action: "obs.volume", Params: map[string]interface{}{"source": source, "volume_db": db}}, nil
+
+	case "obs.source.show", "obs.source.hide":
+		sceneName, itemID, err := e.sceneItem(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+		data, err := e.query(ctx, "GetSceneItemEnabled", map[string]interface{}{"sceneName": sceneName, "sceneItemId": itemID})
+		if err != nil {
+			return nil, err
+		}
+		inverse := "obs.source.hide"
+		if enabled, _ := data["sceneItemEnabled"].(bool); enabled {
+			inverse = "obs.source.show"
+		}
+		return &llm.Action{Action: inverse, Params: map[string]interface{}{"source": source}}, nil
+
+	case "obs.text":
+		data, err := e.query(ctx, "GetInputSettings", map[string]interface{}{"inputName": source})
+		if err != nil {
+			return nil, err
+		}
+		settings, _ := data["inputSettings"].(map[string]interface{})
+		text, _ := settings["text"].(string)
+		return &llm.Action{Action: "obs.text", Params: map[string]interface{}{"source": source, "text": text}}, nil
+	}
+
+	return nil, nil
+}
+
+// query sends a request and returns its response data, failing if OBS rejected it
+func (e *Executor) query(ctx context.Context, requestType string, data map[string]interface{}) (map[string]interface{}, error) {
+	resp, err := e.sendRequest(ctx, requestType, data)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.RequestStatus.Result {
+		return nil, fmt.Errorf("OBS error: %s", resp.RequestStatus.Comment)
+	}
+	return resp.ResponseData, nil
+}
+
+// IsLive reports whether OBS is streaming
+func (e *Executor) IsLive(ctx context.Context) (bool, error) {
+	data, err := e.query(ctx, "GetStreamStatus", nil)
+	if err != nil {
+		return false, err
+	}
+	active, _ := data["outputActive"].(bool)
+	return active, nil
+}
+
+// namesOf extracts a string field from a list of objects in an OBS response
+func namesOf(list interface{}, field string) []string {
+	entries, ok := list.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if obj, ok := entry.(map[string]interface{}); ok {
+			if name, ok := obj[field].(string); ok && name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
 // IsAvailable checks if OBS is available
 func (e *Executor) IsAvailable() bool {
 	return e.enabled && e.connected
@@ -0,0 +1,170 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jarvisstreamer/jarvis/internal/config"
+)
+
+// PolicyError reports an action blocked by the policy
+type PolicyError struct {
+	Action string
+	Reason string // Spoken explanation, in Spanish
+}
+
+// Error implements error; the message is meant to be spoken to the user
+func (e *PolicyError) Error() string {
+	return e.Reason
+}
+
+// Policy decides whether an action may run: allow/deny lists, rate limits,
+// cooldowns and the live-safe mode
+type Policy struct {
+	allow      []string
+	deny       []string
+	liveSafe   []string
+	assumeLive bool
+
+	mu     sync.Mutex
+	limits []*limiter
+}
+
+// limiter tracks the recent calls of the actions matching one limit
+type limiter struct {
+	cfg   config.ActionLimitConfig
+	calls []time.Time
+}
+
+// NewPolicy creates a policy from configuration
+func NewPolicy(cfg config.PolicyConfig) *Policy {
+	p := &Policy{
+		allow: cfg.Allow,
+		deny:  cfg.Deny,
+	}
+	if cfg.LiveSafe.Enabled {
+		p.liveSafe = cfg.LiveSafe.Actions
+		p.assumeLive = cfg.LiveSafe.AssumeLive
+	}
+	for _, l := range cfg.Limits {
+		if l.Action != "" && (l.MaxCalls > 0 || l.CooldownSeconds > 0) {
+			p.limits = append(p.limits, &limiter{cfg: l})
+		}
+	}
+	return p
+}
+
+// Check returns a *PolicyError if the action may not run now, without
+// counting it as a call. isLive is only asked for live-safe actions.
+func (p *Policy) Check(action string, isLive func() bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.check(action, isLive, time.Now(), true)
+}
+
+// CheckAccess is like Check but ignores rate limits and cooldowns, for
+// actions that revert earlier ones
+func (p *Policy) CheckAccess(action string, isLive func() bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.check(action, isLive, time.Now(), false)
+}
+
+// Allow is like Check, but also counts the call against the rate limits
+func (p *Policy) Allow(action string, isLive func() bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	if err := p.check(action, isLive, now, true); err != nil {
+		return err
+	}
+	for _, l := range p.limits {
+		if matchAction(l.cfg.Action, action) {
+			l.calls = append(l.calls, now)
+		}
+	}
+	return nil
+}
+
+// check applies every rule in order, limits only if asked; p.mu must be held
+func (p *Policy) check(action string, isLive func() bool, now time.Time, limits bool) error {
+	if matchAny(p.deny, action) {
+		return &PolicyError{Action: action, Reason: fmt.Sprintf("La acción %s está bloqueada en la configuración.", action)}
+	}
+	if len(p.allow) > 0 && !matchAny(p.allow, action) {
+		return &PolicyError{Action: action, Reason: fmt.Sprintf("La acción %s no está en la lista de acciones permitidas.", action)}
+	}
+	if matchAny(p.liveSafe, action) && (p.assumeLive || (isLive != nil && isLive())) {
+		return &PolicyError{Action: action, Reason: fmt.Sprintf("No puedo hacer %s mientras estás en directo, el modo seguro lo bloquea.", action)}
+	}
+
+	if !limits {
+		return nil
+	}
+
+	for _, l := range p.limits {
+		if !matchAction(l.cfg.Action, action) {
+			continue
+		}
+		l.prune(now)
+
+		if cooldown := l.cfg.Cooldown(); cooldown > 0 && len(l.calls) > 0 {
+			if wait := l.calls[len(l.calls)-1].Add(cooldown).Sub(now); wait > 0 {
+				return &PolicyError{Action: action, Reason: fmt.Sprintf("Espera %s antes de volver a usar %s.", formatSeconds(wait), action)}
+			}
+		}
+		if l.cfg.MaxCalls > 0 && len(l.calls) >= l.cfg.MaxCalls {
+			wait := l.calls[len(l.calls)-l.cfg.MaxCalls].Add(l.cfg.Window()).Sub(now)
+			return &PolicyError{Action: action, Reason: fmt.Sprintf(
+				"Demasiadas veces seguidas: %s solo se permite %d veces en %s. Espera %s.",
+				action, l.cfg.MaxCalls, formatSeconds(l.cfg.Window()), formatSeconds(wait),
+			)}
+		}
+	}
+
+	return nil
+}
+
+// prune drops the calls that no longer count for the window or cooldown
+func (l *limiter) prune(now time.Time) {
+	keep := l.cfg.Window()
+	if cooldown := l.cfg.Cooldown(); cooldown > keep {
+		keep = cooldown
+	}
+
+	i := 0
+	for i < len(l.calls) && now.Sub(l.calls[i]) >= keep {
+		i++
+	}
+	l.calls = l.calls[i:]
+}
+
+// matchAny returns true if any pattern matches the action
+func matchAny(patterns []string, action string) bool {
+	for _, pattern := range patterns {
+		if matchAction(pattern, action) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAction matches an action against a name or a "prefix.*" / "*" pattern
+func matchAction(pattern, action string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(action, prefix)
+	}
+	return pattern == action
+}
+
+// formatSeconds formats a wait for speech, rounding up to whole seconds
+func formatSeconds(d time.Duration) string {
+	secs := int((d + time.Second - 1) / time.Second)
+	if secs <= 1 {
+		return "un segundo"
+	}
+	return fmt.Sprintf("%d segundos", secs)
+}
@@ -0,0 +1,520 @@
+// Package schedule provides delayed and recurring actions and spoken reminders
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jarvisstreamer/jarvis/internal/config"
+	"github.com/jarvisstreamer/jarvis/internal/events"
+	"github.com/jarvisstreamer/jarvis/internal/executor"
+	"github.com/jarvisstreamer/jarvis/internal/llm"
+	"github.com/jarvisstreamer/jarvis/pkg/logger"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// fileName is the job store inside General.DataDir
+	fileName = "schedule.json"
+
+	// maxJobs bounds the number of pending jobs
+	maxJobs = 50
+
+	// missedGrace is how late a one-shot job may still run after a restart;
+	// older ones are dropped instead of firing out of context
+	missedGrace = 5 * time.Minute
+
+	// jobTimeout bounds the execution of a single job
+	jobTimeout = time.Minute
+)
+
+// Speaker says reminders and job failures out loud; *brain.Brain implements it
+type Speaker interface {
+	Speak(ctx context.Context, text string) error
+	// FailureReply phrases why an action failed, without technical details
+	FailureReply(action llm.Action, err error) string
+}
+
+// Job is a pending scheduled action or reminder
+type Job struct {
+	ID              int         `json:"id"`
+	Action          *llm.Action `json:"action,omitempty"`
+	Reminder        string      `json:"reminder,omitempty"`
+	IntervalSeconds int         `json:"interval_seconds,omitempty"` // 0 for one-shot jobs
+	NextRun         time.Time   `json:"next_run"`
+	CreatedAt       time.Time   `json:"created_at"`
+}
+
+// Interval returns the repetition interval; zero for one-shot jobs
+func (j *Job) Interval() time.Duration {
+	return time.Duration(j.IntervalSeconds) * time.Second
+}
+
+// Executor schedules actions and reminders
+type Executor struct {
+	runner  executor.Runner
+	speaker Speaker
+	store   *store
+	log     zerolog.Logger
+
+	mu     sync.Mutex
+	jobs   []*Job
+	nextID int
+
+	wake   chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewExecutor loads the jobs saved under cfg.DataDir and starts the scheduler.
+// Jobs run through runner; reminders are said by speaker.
+func NewExecutor(cfg config.GeneralConfig, runner executor.Runner, speaker Speaker) *Executor {
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &Executor{
+		runner:  runner,
+		speaker: speaker,
+		store:   &store{path: filepath.Join(cfg.DataDir, fileName)},
+		log:     logger.Component("schedule"),
+		nextID:  1,
+		wake:    make(chan struct{}, 1),
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+
+	e.load()
+	go e.run()
+
+	return e
+}
+
+// Name returns the executor name
+func (e *Executor) Name() string {
+	return "schedule"
+}
+
+// SupportedActions returns the list of supported actions
+func (e *Executor) SupportedActions() []string {
+	return []string{
+		"schedule.once",
+		"schedule.every",
+		"schedule.list",
+		"schedule.cancel",
+	}
+}
+
+// Schema describes the parameters of every schedule action
+func (e *Executor) Schema() []executor.ActionSpec {
+	jobParams := func(minutes executor.ParamSpec) []executor.ParamSpec {
+		return []executor.ParamSpec{
+			minutes,
+			{Name: "action", Type: executor.ParamString, Description: "acción a ejecutar, una de esta lista"},
+			{Name: "action_params", Type: executor.ParamObject, Description: "params de esa acción"},
+			{Name: "reminder", Type: executor.ParamString, Description: "texto a recordar en voz alta, si no hay acción"},
+		}
+	}
+
+	return []executor.ActionSpec{
+		{
+			Name:        "schedule.once",
+			Description: "Programar una acción o un recordatorio dentro de unos minutos",
+			Params: jobParams(executor.ParamSpec{
+				Name: "minutes", Type: executor.ParamNumber, Required: true, Min: executor.Limit(0.1), Max: executor.Limit(10080), Description: "minutos hasta ejecutarla",
+			}),
+			Examples: []llm.PromptExample{
+				{Phrase: "en 10 minutos recuérdame sacar al perro", Params: map[string]interface{}{"minutes": 10, "reminder": "saca al perro"}, Reply: "Hecho, te aviso en 10 minutos"},
+			},
+		},
+		{
+			Name:        "schedule.every",
+			Description: "Repetir una acción o un recordatorio cada cierto tiempo",
+			Params: jobParams(executor.ParamSpec{
+				Name: "minutes", Type: executor.ParamNumber, Required: true, Min: executor.Limit(1), Max: executor.Limit(10080), Description: "minutos entre repeticiones",
+			}),
+			Examples: []llm.PromptExample{
+				{Phrase: "cada 20 minutos recuérdame hidratarme", Params: map[string]interface{}{"minutes": 20, "reminder": "hidrátate"}, Reply: "Te lo recuerdo cada 20 minutos"},
+			},
+		},
+		{Name: "schedule.list", Description: "Decir las tareas y recordatorios programados"},
+		{
+			Name:        "schedule.cancel",
+			Description: "Cancelar una tarea programada (la última si no se indica)",
+			Params: []executor.ParamSpec{
+				{Name: "id", Type: executor.ParamInt, Min: executor.Limit(1), Description: "número de la tarea"},
+				{Name: "all", Type: executor.ParamBool, Description: "cancelar todas"},
+			},
+		},
+	}
+}
+
+// CanHandle returns true if this executor can handle the action
+func (e *Executor) CanHandle(action string) bool {
+	return strings.HasPrefix(action, "schedule.")
+}
+
+// Execute executes a schedule action
+func (e *Executor) Execute(ctx context.Context, action llm.Action) (executor.Result, error) {
+	switch action.Action {
+	case "schedule.once":
+		return e.add(action, false)
+	case "schedule.every":
+		return e.add(action, true)
+	case "schedule.list":
+		return e.list()
+	case "schedule.cancel":
+		return e.cancelJobs(action)
+	default:
+		return executor.NewErrorResult(fmt.Errorf("unknown schedule action: %s", action.Action)), nil
+	}
+}
+
+// IsAvailable returns true; the scheduler has no external dependency
+func (e *Executor) IsAvailable() bool {
+	return true
+}
+
+// Close stops the scheduler; pending jobs stay saved for the next start
+func (e *Executor) Close() error {
+	e.cancel()
+	<-e.done
+	return nil
+}
+
+// add schedules a new job from a schedule.once or schedule.every action
+func (e *Executor) add(action llm.Action, recurring bool) (executor.Result, error) {
+	minutes := action.GetFloatParam("minutes")
+	reminder := strings.TrimSpace(action.GetStringParam("reminder"))
+	target := strings.TrimSpace(action.GetStringParam("action"))
+
+	job := &Job{
+		Reminder:  reminder,
+		NextRun:   time.Now().Add(time.Duration(minutes * float64(time.Minute))),
+		CreatedAt: time.Now(),
+	}
+	if recurring {
+		job.IntervalSeconds = int(minutes * 60)
+	}
+
+	switch {
+	case target != "":
+		if strings.HasPrefix(target, "schedule.") {
+			return executor.NewErrorResult(fmt.Errorf("cannot schedule %s", target)), nil
+		}
+		params, _ := action.Params["action_params"].(map[string]interface{})
+		validated, err := e.runner.Validate(llm.Action{Action: target, Params: params})
+		if err != nil {
+			return executor.NewErrorResult(fmt.Errorf("cannot schedule %s: %w", target, err)), nil
+		}
+		job.Action = &validated
+	case reminder == "":
+		return executor.NewErrorResult(fmt.Errorf("nothing to schedule: give an action or a reminder")), nil
+	}
+
+	e.mu.Lock()
+	if len(e.jobs) >= maxJobs {
+		e.mu.Unlock()
+		return executor.NewErrorResult(fmt.Errorf("too many scheduled jobs (%d)", maxJobs)), nil
+	}
+	job.ID = e.nextID
+	e.nextID++
+	e.jobs = append(e.jobs, job)
+	e.saveLocked()
+	e.mu.Unlock()
+
+	e.reschedule()
+
+	e.log.Info().
+		Int("id", job.ID).
+		Str("job", describe(job)).
+		Time("next_run", job.NextRun).
+		Dur("interval", job.Interval()).
+		Msg("Job scheduled")
+
+	return executor.NewResultWithData(fmt.Sprintf("Scheduled job %d", job.ID), map[string]interface{}{
+		"id":       job.ID,
+		"next_run": job.NextRun,
+	}), nil
+}
+
+// list describes the pending jobs
+func (e *Executor) list() (executor.Result, error) {
+	e.mu.Lock()
+	jobs := make([]*Job, len(e.jobs))
+	copy(jobs, e.jobs)
+	e.mu.Unlock()
+
+	if len(jobs) == 0 {
+		result := executor.NewResult("No scheduled jobs")
+		result.Reply = "No tienes nada programado."
+		return result, nil
+	}
+
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].NextRun.Before(jobs[j].NextRun) })
+
+	now := time.Now()
+	parts := make([]string, len(jobs))
+	for i, job := range jobs {
+		when := "en " + formatWait(job.NextRun.Sub(now))
+		if job.Interval() > 0 {
+			when = fmt.Sprintf("cada %s, la próxima %s", formatWait(job.Interval()), when)
+		}
+		parts[i] = fmt.Sprintf("%d, %s %s", job.ID, describe(job), when)
+	}
+
+	result := executor.NewResultWithData(fmt.Sprintf("%d scheduled jobs", len(jobs)), map[string]interface{}{
+		"count": len(jobs),
+	})
+	if len(jobs) == 1 {
+		result.Reply = "Tienes una tarea programada: " + parts[0] + "."
+	} else {
+		result.Reply = fmt.Sprintf("Tienes %d tareas programadas: %s.", len(jobs), strings.Join(parts, "; "))
+	}
+	return result, nil
+}
+
+// cancelJobs removes one job by id, every job, or the most recently created one
+func (e *Executor) cancelJobs(action llm.Action) (executor.Result, error) {
+	e.mu.Lock()
+
+	if len(e.jobs) == 0 {
+		e.mu.Unlock()
+		result := executor.NewResult("No scheduled jobs")
+		result.Reply = "No hay nada programado que cancelar."
+		return result, nil
+	}
+
+	var removed []*Job
+	switch {
+	case action.GetBoolParam("all"):
+		removed = e.jobs
+		e.jobs = nil
+
+	case action.GetIntParam("id") > 0:
+		id := action.GetIntParam("id")
+		for i, job := range e.jobs {
+			if job.ID == id {
+				removed = []*Job{job}
+				e.jobs = append(e.jobs[:i], e.jobs[i+1:]...)
+				break
+			}
+		}
+		if len(removed) == 0 {
+			e.mu.Unlock()
+			return executor.NewErrorResult(fmt.Errorf("no scheduled job with id %d", id)), nil
+		}
+
+	default:
+		last := 0
+		for i, job := range e.jobs {
+			if job.CreatedAt.After(e.jobs[last].CreatedAt) {
+				last = i
+			}
+		}
+		removed = []*Job{e.jobs[last]}
+		e.jobs = append(e.jobs[:last], e.jobs[last+1:]...)
+	}
+
+	e.saveLocked()
+	e.mu.Unlock()
+	e.reschedule()
+
+	e.log.Info().Int("count", len(removed)).Msg("Scheduled jobs cancelled")
+
+	result := executor.NewResultWithData(fmt.Sprintf("Cancelled %d jobs", len(removed)), map[string]interface{}{
+		"count": len(removed),
+	})
+	if len(removed) == 1 {
+		result.Reply = fmt.Sprintf("Cancelada la tarea %d: %s.", removed[0].ID, describe(removed[0]))
+	} else {
+		result.Reply = fmt.Sprintf("Canceladas %d tareas programadas.", len(removed))
+	}
+	return result, nil
+}
+
+// reschedule wakes the scheduler loop so it picks up added or removed jobs
+func (e *Executor) reschedule() {
+	select {
+	case e.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run waits for the earliest job and fires every due job
+func (e *Executor) run() {
+	defer close(e.done)
+
+	for {
+		var timer *time.Timer
+		var fire <-chan time.Time
+		if next, ok := e.nextRun(); ok {
+			timer = time.NewTimer(time.Until(next))
+			fire = timer.C
+		}
+
+		select {
+		case <-e.ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case <-e.wake:
+			if timer != nil {
+				timer.Stop()
+			}
+		case <-fire:
+			for _, job := range e.takeDue(time.Now()) {
+				go e.runJob(job)
+			}
+		}
+	}
+}
+
+// nextRun returns the time of the earliest pending job
+func (e *Executor) nextRun() (time.Time, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var next time.Time
+	for _, job := range e.jobs {
+		if next.IsZero() || job.NextRun.Before(next) {
+			next = job.NextRun
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// takeDue returns the jobs due at now, removing one-shot jobs and moving
+// recurring ones to their next run
+func (e *Executor) takeDue(now time.Time) []Job {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var due []Job
+	pending := e.jobs[:0]
+	for _, job := range e.jobs {
+		if job.NextRun.After(now) {
+			pending = append(pending, job)
+			continue
+		}
+
+		due = append(due, *job)
+		if job.Interval() > 0 {
+			for !job.NextRun.After(now) {
+				job.NextRun = job.NextRun.Add(job.Interval())
+			}
+			pending = append(pending, job)
+		}
+	}
+	e.jobs = pending
+
+	if len(due) > 0 {
+		e.saveLocked()
+	}
+	return due
+}
+
+// runJob executes a due job's action or says its reminder
+func (e *Executor) runJob(job Job) {
+	ctx, cancel := context.WithTimeout(e.ctx, jobTimeout)
+	defer cancel()
+
+	e.log.Info().Int("id", job.ID).Str("job", describe(&job)).Msg("Running scheduled job")
+
+	if job.Action == nil {
+		e.say(ctx, "Recordatorio: "+job.Reminder)
+		return
+	}
+
+	events.Publish(executor.ActionEvent(*job.Action, "schedule"))
+	result, err := e.runner.Execute(ctx, *job.Action)
+	if err == nil && !result.Success {
+		err = errors.New(result.Error)
+	}
+	if err != nil {
+		e.log.Warn().Err(err).Int("id", job.ID).Msg("Scheduled job failed")
+		e.say(ctx, fmt.Sprintf("No pude ejecutar la tarea programada %d. %s", job.ID, e.failureReply(*job.Action, err)))
+		return
+	}
+
+	if job.Reminder != "" {
+		e.say(ctx, job.Reminder)
+	}
+}
+
+// say publishes and speaks a message from a job
+func (e *Executor) say(ctx context.Context, text string) {
+	events.Publish(events.Reply{Text: text})
+	if e.speaker == nil {
+		return
+	}
+	if err := e.speaker.Speak(ctx, text); err != nil {
+		e.log.Warn().Err(err).Msg("Failed to speak scheduled message")
+	}
+}
+
+// failureReply phrases why a job's action failed; the error itself only
+// goes to the log
+func (e *Executor) failureReply(action llm.Action, err error) string {
+	if e.speaker == nil {
+		return "Algo ha fallado."
+	}
+	return e.speaker.FailureReply(action, err)
+}
+
+// describe returns a short spoken description of a job
+func describe(job *Job) string {
+	if job.Action == nil {
+		return fmt.Sprintf("recordarte %q", job.Reminder)
+	}
+
+	var values []string
+	for _, v := range job.Action.Params {
+		if s, ok := v.(string); ok && s != "" {
+			values = append(values, s)
+		}
+	}
+	sort.Strings(values)
+	if len(values) == 0 {
+		return job.Action.Action
+	}
+	return fmt.Sprintf("%s %s", job.Action.Action, strings.Join(values, " "))
+}
+
+// formatWait formats a duration the way it is said in Spanish
+func formatWait(d time.Duration) string {
+	if d < time.Minute {
+		secs := int(d.Round(time.Second) / time.Second)
+		if secs <= 1 {
+			return "un segundo"
+		}
+		return fmt.Sprintf("%d segundos", secs)
+	}
+
+	mins := int(d.Round(time.Minute) / time.Minute)
+	hours, mins := mins/60, mins%60
+
+	var parts []string
+	switch {
+	case hours == 1:
+		parts = append(parts, "una hora")
+	case hours > 1:
+		parts = append(parts, fmt.Sprintf("%d horas", hours))
+	}
+	switch {
+	case mins == 1:
+		parts = append(parts, "un minuto")
+	case mins > 1:
+		parts = append(parts, fmt.Sprintf("%d minutos", mins))
+	}
+	if len(parts) == 0 {
+		return "un minuto"
+	}
+	return strings.Join(parts, " y ")
+}
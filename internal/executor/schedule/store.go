@@ -0,0 +1,111 @@
+package schedule
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// store persists the pending jobs as a JSON file
+type store struct {
+	path string
+}
+
+// storeFile is the on-disk layout of the job store
+type storeFile struct {
+	NextID int    `json:"next_id"`
+	Jobs   []*Job `json:"jobs"`
+}
+
+// read loads the saved jobs; a missing file is an empty store
+func (s *store) read() (storeFile, error) {
+	var file storeFile
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return file, nil
+	}
+	if err != nil {
+		return file, fmt.Errorf("failed to read %s: %w", s.path, err)
+	}
+
+	if err := json.Unmarshal(data, &file); err != nil {
+		return file, fmt.Errorf("failed to parse %s: %w", s.path, err)
+	}
+	return file, nil
+}
+
+// write saves the jobs, replacing the file atomically
+func (s *store) write(file storeFile) error {
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode jobs: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", s.path, err)
+	}
+	return nil
+}
+
+// load restores the saved jobs. One-shot jobs missed by more than missedGrace
+// while Jarvis was off are dropped; recurring jobs skip to their next run.
+func (e *Executor) load() {
+	file, err := e.store.read()
+	if err != nil {
+		e.log.Warn().Err(err).Msg("Failed to load scheduled jobs")
+		return
+	}
+
+	now := time.Now()
+	dropped := 0
+	for _, job := range file.Jobs {
+		if job == nil || (job.Action == nil && job.Reminder == "") {
+			continue
+		}
+		if job.ID >= e.nextID {
+			e.nextID = job.ID + 1
+		}
+
+		if job.NextRun.Before(now) {
+			switch {
+			case job.Interval() > 0:
+				for job.NextRun.Before(now) {
+					job.NextRun = job.NextRun.Add(job.Interval())
+				}
+			case now.Sub(job.NextRun) > missedGrace:
+				dropped++
+				continue
+			}
+		}
+		e.jobs = append(e.jobs, job)
+	}
+	if file.NextID > e.nextID {
+		e.nextID = file.NextID
+	}
+
+	if len(e.jobs) > 0 || dropped > 0 {
+		e.log.Info().Int("jobs", len(e.jobs)).Int("dropped", dropped).Msg("Scheduled jobs restored")
+	}
+	if dropped > 0 {
+		e.saveLocked()
+	}
+}
+
+// saveLocked writes the jobs to disk; e.mu must be held
+func (e *Executor) saveLocked() {
+	if err := e.store.write(storeFile{NextID: e.nextID, Jobs: e.jobs}); err != nil {
+		e.log.Warn().Err(err).Msg("Failed to save scheduled jobs")
+	}
+}
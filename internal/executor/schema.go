@@ -0,0 +1,327 @@
+package executor
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jarvisstreamer/jarvis/internal/llm"
+)
+
+// ParamType is the expected type of an action parameter
+type ParamType string
+
+// Supported parameter types
+const (
+	ParamString ParamType = "string"
+	ParamNumber ParamType = "number"
+	ParamInt    ParamType = "integer"
+	ParamBool   ParamType = "boolean"
+	ParamObject ParamType = "object"
+)
+
+// ParamSpec describes one parameter of an action
+type ParamSpec struct {
+	Name        string
+	Type        ParamType
+	Description string
+	Required    bool
+	Min         *float64    // Inclusive lower bound for numbers
+	Max         *float64    // Inclusive upper bound for numbers
+	Enum        []string    // Allowed values for strings (case-insensitive)
+	Default     interface{} // Used when an optional parameter is missing
+}
+
+// ActionSpec describes an action and its parameters
+type ActionSpec struct {
+	Name        string
+	Description string
+	Params      []ParamSpec
+	OneOf       []string // Optional params of which at least one is needed; the first is the usual one
+	Examples    []llm.PromptExample
+}
+
+// Limit returns a pointer to v, for ParamSpec.Min and ParamSpec.Max
+func Limit(v float64) *float64 {
+	return &v
+}
+
+// ValidationError reports a missing or invalid action parameter
+type ValidationError struct {
+	Action string
+	Param  string
+	Reason string // Spoken explanation, in Spanish
+}
+
+// Error implements error; the message is meant to be spoken to the user
+func (e *ValidationError) Error() string {
+	return e.Reason
+}
+
+// typeName returns the spoken name of a parameter type
+func (t ParamType) typeName() string {
+	switch t {
+	case ParamNumber:
+		return "un número"
+	case ParamInt:
+		return "un número entero"
+	case ParamBool:
+		return "sí o no"
+	case ParamObject:
+		return "un objeto"
+	default:
+		return "un texto"
+	}
+}
+
+// label returns how a parameter is named when spoken
+func (p ParamSpec) label() string {
+	if p.Description != "" {
+		return fmt.Sprintf("%s (%s)", p.Name, p.Description)
+	}
+	return p.Name
+}
+
+// Validate checks params against the spec and returns a copy with values
+// coerced to their declared types and defaults filled in. Parameters not
+// declared in the spec are passed through untouched.
+func (s ActionSpec) Validate(params map[string]interface{}) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		out[k] = v
+	}
+
+	for _, p := range s.Params {
+		raw, ok := out[p.Name]
+		if ok && isBlank(raw) {
+			ok = false
+			delete(out, p.Name)
+		}
+
+		if !ok {
+			if p.Required {
+				return nil, &ValidationError{
+					Action: s.Name,
+					Param:  p.Name,
+					Reason: fmt.Sprintf("Falta el parámetro %s para %s.", p.label(), s.spokenName()),
+				}
+			}
+			if p.Default != nil {
+				out[p.Name] = p.Default
+			}
+			continue
+		}
+
+		value, err := p.coerce(raw)
+		if err != nil {
+			return nil, &ValidationError{Action: s.Name, Param: p.Name, Reason: err.Error()}
+		}
+		out[p.Name] = value
+	}
+
+	if len(s.OneOf) > 0 && !hasAny(out, s.OneOf) {
+		label := s.OneOf[0]
+		for _, p := range s.Params {
+			if p.Name == label {
+				label = p.label()
+			}
+		}
+		return nil, &ValidationError{
+			Action: s.Name,
+			Param:  s.OneOf[0],
+			Reason: fmt.Sprintf("Falta el parámetro %s para %s.", label, s.spokenName()),
+		}
+	}
+
+	return out, nil
+}
+
+// hasAny returns true if params has any of the names
+func hasAny(params map[string]interface{}, names []string) bool {
+	for _, name := range names {
+		if _, ok := params[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// spokenName returns how the action is named when spoken
+func (s ActionSpec) spokenName() string {
+	if s.Description == "" {
+		return s.Name
+	}
+	r := []rune(s.Description)
+	return strings.ToLower(string(r[0])) + string(r[1:])
+}
+
+// isBlank returns true for nil and whitespace-only strings
+func isBlank(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	s, ok := v.(string)
+	return ok && strings.TrimSpace(s) == ""
+}
+
+// coerce converts a raw value to the parameter's type and checks its constraints
+func (p ParamSpec) coerce(raw interface{}) (interface{}, error) {
+	switch p.Type {
+	case ParamNumber, ParamInt:
+		n, ok := toFloat(raw)
+		if !ok {
+			return nil, fmt.Errorf("El parámetro %s debe ser %s, recibí %q.", p.Name, p.Type.typeName(), fmt.Sprint(raw))
+		}
+		if p.Type == ParamInt {
+			if n != math.Trunc(n) {
+				return nil, fmt.Errorf("El parámetro %s debe ser %s, recibí %s.", p.Name, p.Type.typeName(), formatNumber(n))
+			}
+		}
+		if err := p.checkRange(n); err != nil {
+			return nil, err
+		}
+		if p.Type == ParamInt {
+			return int(n), nil
+		}
+		return n, nil
+
+	case ParamBool:
+		b, ok := toBool(raw)
+		if !ok {
+			return nil, fmt.Errorf("El parámetro %s debe ser %s, recibí %q.", p.Name, p.Type.typeName(), fmt.Sprint(raw))
+		}
+		return b, nil
+
+	case ParamObject:
+		m, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("El parámetro %s debe ser %s, recibí %q.", p.Name, p.Type.typeName(), fmt.Sprint(raw))
+		}
+		return m, nil
+
+	default:
+		s := strings.TrimSpace(toString(raw))
+		if len(p.Enum) > 0 {
+			for _, allowed := range p.Enum {
+				if strings.EqualFold(s, allowed) {
+					return allowed, nil
+				}
+			}
+			return nil, fmt.Errorf("El parámetro %s debe ser uno de: %s. Recibí %q.", p.Name, strings.Join(p.Enum, ", "), s)
+		}
+		return s, nil
+	}
+}
+
+// checkRange verifies the Min/Max bounds of a number
+func (p ParamSpec) checkRange(n float64) error {
+	switch {
+	case p.Min != nil && p.Max != nil && (n < *p.Min || n > *p.Max):
+		return fmt.Errorf("El parámetro %s debe estar entre %s y %s, recibí %s.", p.Name, formatNumber(*p.Min), formatNumber(*p.Max), formatNumber(n))
+	case p.Min != nil && n < *p.Min:
+		return fmt.Errorf("El parámetro %s debe ser al menos %s, recibí %s.", p.Name, formatNumber(*p.Min), formatNumber(n))
+	case p.Max != nil && n > *p.Max:
+		return fmt.Errorf("El parámetro %s debe ser como máximo %s, recibí %s.", p.Name, formatNumber(*p.Max), formatNumber(n))
+	}
+	return nil
+}
+
+// toFloat converts JSON numbers and numeric strings ("0.5", "0,5") to float64
+func toFloat(v interface{}) (float64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return val, true
+	case float32:
+		return float64(val), true
+	case int:
+		return float64(val), true
+	case int64:
+		return float64(val), true
+	case string:
+		s := strings.Replace(strings.TrimSpace(val), ",", ".", 1)
+		n, err := strconv.ParseFloat(s, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// toBool converts booleans and common yes/no strings
+func toBool(v interface{}) (bool, bool) {
+	switch val := v.(type) {
+	case bool:
+		return val, true
+	case float64:
+		return val != 0, true
+	case int:
+		return val != 0, true
+	case string:
+		switch strings.ToLower(strings.TrimSpace(val)) {
+		case "true", "si", "sí", "yes", "1", "on":
+			return true, true
+		case "false", "no", "0", "off":
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// toString converts scalars to their string form
+func toString(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case float64:
+		return formatNumber(val)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// formatNumber formats a number without trailing zeros
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+// PromptAction converts the spec to its system prompt description
+func (s ActionSpec) PromptAction() llm.PromptAction {
+	params := make([]llm.PromptParam, len(s.Params))
+	for i, p := range s.Params {
+		params[i] = llm.PromptParam{
+			Name:        p.Name,
+			Type:        string(p.Type),
+			Description: p.Description,
+			Required:    p.Required || (len(s.OneOf) > 0 && s.OneOf[0] == p.Name),
+			Min:         p.Min,
+			Max:         p.Max,
+			Enum:        p.Enum,
+			Default:     p.Default,
+		}
+	}
+	return llm.PromptAction{Name: s.Name, Description: s.Description, Params: params, Examples: s.Examples}
+}
+
+// FindSpec returns the spec of an action from a list of specs
+func FindSpec(specs []ActionSpec, action string) (ActionSpec, bool) {
+	for _, s := range specs {
+		if s.Name == action {
+			return s, true
+		}
+	}
+	return ActionSpec{}, false
+}
+
+// validateAction validates and coerces the params of an action against its executor's schema
+func validateAction(exec Executor, action llm.Action) (llm.Action, error) {
+	spec, ok := FindSpec(exec.Schema(), action.Action)
+	if !ok {
+		return action, nil
+	}
+
+	params, err := spec.Validate(action.Params)
+	if err != nil {
+		return action, err
+	}
+	action.Params = params
+	return action, nil
+}
@@ -0,0 +1,69 @@
+package executor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var volumeSpec = ActionSpec{
+	Name:        "obs.volume",
+	Description: "Cambiar el volumen de una fuente",
+	Params: []ParamSpec{
+		{Name: "source", Type: ParamString, Required: true, Description: "nombre de la fuente"},
+		{Name: "volume", Type: ParamNumber, Min: Limit(0), Max: Limit(1), Description: "nivel de volumen"},
+		{Name: "volume_db", Type: ParamNumber, Min: Limit(-100), Max: Limit(26)},
+	},
+	OneOf: []string{"volume", "volume_db"},
+}
+
+func TestValidateOneOf(t *testing.T) {
+	for _, params := range []map[string]interface{}{
+		{"source": "Mic", "volume": "0,5"},
+		{"source": "Mic", "volume_db": -12.5},
+	} {
+		if _, err := volumeSpec.Validate(params); err != nil {
+			t.Errorf("Validate(%v): %v", params, err)
+		}
+	}
+
+	_, err := volumeSpec.Validate(map[string]interface{}{"source": "Mic", "volume": " "})
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Validate without a level = %v, want a ValidationError", err)
+	}
+	if verr.Param != "volume" || verr.Reason != "Falta el parámetro volume (nivel de volumen) para cambiar el volumen de una fuente." {
+		t.Errorf("ValidationError = %+v", verr)
+	}
+}
+
+func TestValidateCoercesAndChecks(t *testing.T) {
+	params, err := volumeSpec.Validate(map[string]interface{}{"source": " Mic ", "volume": "0,5", "extra": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["source"] != "Mic" || params["volume"] != 0.5 || params["extra"] != 1 {
+		t.Errorf("coerced params = %v", params)
+	}
+
+	for _, tt := range []struct {
+		params map[string]interface{}
+		reason string
+	}{
+		{map[string]interface{}{"volume": 0.5}, "Falta el parámetro source"},
+		{map[string]interface{}{"source": "Mic", "volume": 2}, "entre 0 y 1"},
+		{map[string]interface{}{"source": "Mic", "volume": "alto"}, "debe ser un número"},
+	} {
+		_, err := volumeSpec.Validate(tt.params)
+		if err == nil || !strings.Contains(err.Error(), tt.reason) {
+			t.Errorf("Validate(%v) = %v, want %q", tt.params, err, tt.reason)
+		}
+	}
+}
+
+func TestPromptActionMarksOneOf(t *testing.T) {
+	params := volumeSpec.PromptAction().Params
+	if !params[1].Required || params[2].Required {
+		t.Errorf("prompt params = %+v, want volume required and volume_db optional", params)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jarvisstreamer/jarvis/internal/config"
@@ -21,8 +22,18 @@ import (
 const (
 	twitchAPIURL  = "https://api.twitch.tv/helix"
 	twitchAuthURL = "https://id.twitch.tv/oauth2"
+
+	// channelCacheTTL is how long the title/category shown to the LLM is reused
+	channelCacheTTL = time.Minute
 )
 
+// channelInfo is the cached title and category of the channel
+type channelInfo struct {
+	title     string
+	category  string
+	fetchedAt time.Time
+}
+
 // Executor implements the Twitch action executor
 type Executor struct {
 	clientID      string
@@ -34,6 +45,9 @@ type Executor struct {
 	client        *http.Client
 	log           zerolog.Logger
 	enabled       bool
+
+	channelMu sync.Mutex
+	channel   channelInfo
 }
 
 // NewExecutor creates a new Twitch executor
@@ -70,6 +84,56 @@ func (e *Executor) SupportedActions() []string {
 	}
 }
 
+// Schema describes the parameters of every Twitch action
+func (e *Executor) Schema() []executor.ActionSpec {
+	user := executor.ParamSpec{Name: "user", Type: executor.ParamString, Required: true, Description: "nombre del usuario"}
+	return []executor.ActionSpec{
+		{
+			Name:        "twitch.clip",
+			Description: "Crear un clip",
+			Examples:    []llm.PromptExample{{Phrase: "hazme un clip", Reply: "Dale, creando clip"}},
+		},
+		{
+			Name:        "twitch.title",
+			Description: "Cambiar el título del stream",
+			Params: []executor.ParamSpec{
+				{Name: "title", Type: executor.ParamString, Required: true, Description: "nuevo título"},
+			},
+			Examples: []llm.PromptExample{
+				{Phrase: "cambia el título a vuelvo en 5", Params: map[string]interface{}{"title": "Vuelvo en 5"}, Reply: "Título cambiado"},
+			},
+		},
+		{
+			Name:        "twitch.category",
+			Description: "Cambiar la categoría del stream",
+			Params: []executor.ParamSpec{
+				{Name: "category", Type: executor.ParamString, Required: true, Description: "nombre del juego o categoría"},
+			},
+		},
+		{
+			Name:        "twitch.ban",
+			Description: "Banear a un usuario",
+			Params: []executor.ParamSpec{
+				user,
+				{Name: "reason", Type: executor.ParamString, Description: "motivo del ban"},
+			},
+			Examples: []llm.PromptExample{
+				{Phrase: "banea a trollmaster por spam", Params: map[string]interface{}{"user": "trollmaster", "reason": "spam"}, Reply: "Baneando a trollmaster"},
+			},
+		},
+		{
+			Name:        "twitch.timeout",
+			Description: "Dar timeout a un usuario",
+			Params: []executor.ParamSpec{
+				user,
+				// Twitch accepts timeouts of up to two weeks
+				{Name: "duration", Type: executor.ParamInt, Min: executor.Limit(1), Max: executor.Limit(1209600), Default: 600, Description: "duración en segundos"},
+			},
+		},
+		{Name: "twitch.unban", Description: "Quitar el ban a un usuario", Params: []executor.ParamSpec{user}},
+	}
+}
+
 // CanHandle returns true if this executor can handle the action
 func (e *Executor) CanHandle(action string) bool {
 	return strings.HasPrefix(action, "twitch.")
@@ -163,6 +227,7 @@ func (e *Executor) setTitle(ctx context.Context, action llm.Action) (executor.Re
 		return executor.NewErrorResult(err), err
 	}
 
+	e.invalidateChannel()
 	return executor.NewResult("Title updated to: " + title), nil
 }
 
@@ -193,6 +258,7 @@ func (e *Executor) setCategory(ctx context.Context, action llm.Action) (executor
 		return executor.NewErrorResult(err), err
 	}
 
+	e.invalidateChannel()
 	return executor.NewResult("Category updated to: " + category), nil
 }
 
@@ -329,6 +395,97 @@ func (e *Executor) unbanUser(ctx context.Context, action llm.Action) (executor.R
 	return executor.NewResult("User unbanned: " + user), nil
 }
 
+// PrepareUndo captures the title or category an action is about to change,
+// and lifts bans and timeouts
+func (e *Executor) PrepareUndo(ctx context.Context, action llm.Action) (*llm.Action, error) {
+	switch action.Action {
+	case "twitch.title", "twitch.category":
+		// Not the cached info: a change made on the dashboard since would
+		// be reverted to a stale value
+		info, err := e.channelInfo(ctx, 0)
+		if err != nil {
+			return nil, err
+		}
+		if action.Action == "twitch.title" && info.title != "" {
+			return &llm.Action{Action: "twitch.title", Params: map[string]interface{}{"title": info.title}}, nil
+		}
+		if action.Action == "twitch.title" || info.category == "" {
+			return nil, nil
+		}
+		return &llm.Action{Action: "twitch.category", Params: map[string]interface{}{"category": info.category}}, nil
+
+	case "twitch.ban", "twitch.timeout":
+		return &llm.Action{Action: "twitch.unban", Params: map[string]interface{}{"user": action.GetStringParam("user")}}, nil
+	}
+
+	return nil, nil
+}
+
+// LiveContext reports the current stream title and category
+func (e *Executor) LiveContext(ctx context.Context) []llm.ContextItem {
+	info, err := e.channelInfo(ctx, channelCacheTTL)
+	if err != nil {
+		e.log.Debug().Err(err).Msg("Could not get channel info")
+		return nil
+	}
+
+	var items []llm.ContextItem
+	if info.title != "" {
+		items = append(items, llm.ContextItem{Label: "Título del stream", Value: info.title})
+	}
+	if info.category != "" {
+		items = append(items, llm.ContextItem{Label: "Categoría del stream", Value: info.category})
+	}
+	return items
+}
+
+// channelInfo returns the channel title and category, from the cache if
+// fetched less than maxAge ago
+func (e *Executor) channelInfo(ctx context.Context, maxAge time.Duration) (channelInfo, error) {
+	e.channelMu.Lock()
+	defer e.channelMu.Unlock()
+
+	if !e.channel.fetchedAt.IsZero() && time.Since(e.channel.fetchedAt) < maxAge {
+		return e.channel, nil
+	}
+
+	// GET /channels?broadcaster_id=xxx
+	params := url.Values{}
+	params.Set("broadcaster_id", e.broadcasterID)
+
+	resp, err := e.apiRequest(ctx, "GET", "/channels?"+params.Encode(), nil)
+	if err != nil {
+		return channelInfo{}, err
+	}
+
+	var result struct {
+		Data []struct {
+			Title    string `json:"title"`
+			GameName string `json:"game_name"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return channelInfo{}, fmt.Errorf("failed to parse channel info: %w", err)
+	}
+	if len(result.Data) == 0 {
+		return channelInfo{}, fmt.Errorf("channel not found")
+	}
+
+	e.channel = channelInfo{
+		title:     result.Data[0].Title,
+		category:  result.Data[0].GameName,
+		fetchedAt: time.Now(),
+	}
+	return e.channel, nil
+}
+
+// invalidateChannel forces the next channelInfo call to refetch
+func (e *Executor) invalidateChannel() {
+	e.channelMu.Lock()
+	e.channel = channelInfo{}
+	e.channelMu.Unlock()
+}
+
 // getUserID gets a user's ID from their username
 func (e *Executor) getUserID(ctx context.Context, username string) (string, error) {
 	params := url.Values{}
@@ -1,3 +1,5 @@
+//go:build !nohotkey
+
 package hotkey
 
 import (
@@ -0,0 +1,17 @@
+//go:build nohotkey
+
+package hotkey
+
+import (
+	"context"
+	"fmt"
+)
+
+type Listener struct{}
+
+// NewListener returns a stub error when global hotkeys are disabled.
+func NewListener(parent context.Context, onDown, onUp func()) (*Listener, error) {
+	return nil, fmt.Errorf("global hotkeys are disabled in this build (nohotkey tag)")
+}
+
+func (l *Listener) Close() {}
@@ -0,0 +1,206 @@
+package intent
+
+import "github.com/jarvisstreamer/jarvis/internal/config"
+
+// BuiltinRules returns the rules for high-confidence everyday commands.
+// micSource is the OBS input muted by "silencia el micro"; without it those
+// phrases are left to the LLM, which knows the sources.
+func BuiltinRules(micSource string) []config.IntentRule {
+	rules := []config.IntentRule{
+		// Music
+		{
+			Action: "music.next",
+			Patterns: []string{
+				"(siguiente|proxima|otra) (cancion|tema)",
+				"pon [la] (siguiente|proxima|otra) [cancion|tema]",
+				"(pasa|salta|cambia) [de|a] [la|esta] (cancion|tema)",
+				"(pasa|salta) a la siguiente [cancion|tema]",
+				"(next|skip) [this] song",
+			},
+			Reply: "Siguiente canción.",
+		},
+		{
+			Action: "music.previous",
+			Patterns: []string{
+				"(cancion|tema) anterior",
+				"(pon|vuelve a) la (cancion|tema) anterior",
+				"(pon|vuelve a) la anterior",
+				"previous song",
+			},
+			Reply: "Canción anterior.",
+		},
+		{
+			Action: "music.pause",
+			Patterns: []string{
+				"pausa [la] musica",
+				"pon [la] musica en pausa",
+				"pause [the] music",
+			},
+			Reply: "Música en pausa.",
+		},
+		{
+			Action: "music.resume",
+			Patterns: []string{
+				"(reanuda|continua|sigue con) [la] musica",
+				"quita [la] pausa [de la musica]",
+				"resume [the] music",
+			},
+			Reply: "Reanudando la música.",
+		},
+		{
+			Action: "music.stop",
+			Patterns: []string{
+				"(para|deten|apaga|quita) [la] musica",
+				"stop [the] music",
+			},
+			Reply: "Música detenida.",
+		},
+		{
+			Action: "music.play",
+			Patterns: []string{
+				"(pon|reproduce) [algo de|la] musica",
+				"play [some] music",
+			},
+			Reply: "Poniendo música.",
+		},
+		{
+			Action: "music.play",
+			Patterns: []string{
+				"(pon|reproduce) [algo de] musica de ${query}",
+			},
+			Reply: "Poniendo música de ${query}.",
+		},
+		{
+			Action: "music.volume",
+			Patterns: []string{
+				"(pon|sube|baja) [el] volumen de la musica (a|al) ${volume:percent} [por ciento]",
+				"[pon] [la] musica al ${volume:percent} [por ciento]",
+			},
+			Reply: "Volumen de la música al ${volume} por ciento.",
+		},
+
+		// OBS
+		{
+			Action: "obs.scene",
+			Patterns: []string{
+				"(cambia|pasa|ve) a [la] escena ${scene}",
+				"(pon|cambia) [la] escena ${scene}",
+			},
+			Reply: "Cambiando a la escena ${scene}.",
+		},
+
+		// Twitch
+		{
+			Action: "twitch.clip",
+			Patterns: []string{
+				"(haz|crea|saca|graba) [un] clip",
+				"clipea [eso|esto]",
+				"(make|create) a clip",
+			},
+			Reply: "Creando clip.",
+		},
+
+		// Schedule
+		{
+			Action: "schedule.once",
+			Patterns: []string{
+				"(recuerdame|recuerda me) ${reminder} en ${minutes:number} minutos",
+				"en ${minutes:number} minutos (recuerdame|recuerda me) ${reminder}",
+			},
+			Reply: "Te lo recuerdo en ${minutes} minutos.",
+		},
+		{
+			Action: "schedule.every",
+			Patterns: []string{
+				"(recuerdame|recuerda me) ${reminder} cada ${minutes:number} minutos",
+				"cada ${minutes:number} minutos (recuerdame|recuerda me) ${reminder}",
+			},
+			Reply: "Te lo recordaré cada ${minutes} minutos.",
+		},
+		{
+			Action: "schedule.list",
+			Patterns: []string{
+				"que (tengo|hay) programado",
+				"(lista|dime) [los|las] (recordatorios|tareas programadas)",
+				"list [my] (reminders|scheduled jobs)",
+			},
+		},
+		{
+			Action: "schedule.cancel",
+			Patterns: []string{
+				"cancela [el|la] (recordatorio|tarea) [numero] ${id:int}",
+			},
+		},
+		{
+			Action: "schedule.cancel",
+			Patterns: []string{
+				"cancela (todos los recordatorios|todas las tareas programadas|todo lo programado)",
+			},
+			Params: map[string]interface{}{"all": true},
+		},
+		{
+			Action: "schedule.cancel",
+			Patterns: []string{
+				"cancela [el] (ultimo recordatorio|recordatorio)",
+				"cancela [la] (ultima tarea programada|tarea programada)",
+			},
+		},
+
+		// System
+		{
+			Action:   "system.status",
+			Patterns: []string{"estado del sistema", "system status"},
+		},
+		{
+			Action:   "system.help",
+			Patterns: []string{"ayuda", "que puedes hacer", "help"},
+		},
+		{
+			Action: "system.undo",
+			Patterns: []string{
+				"(deshaz|deshacer|revierte|anula) [eso|esto|lo ultimo|el ultimo comando|la ultima accion]",
+				"undo [that]",
+			},
+		},
+	}
+
+	if micSource == "" {
+		return rules
+	}
+	return append(rules,
+		config.IntentRule{
+			Action: "obs.mute",
+			Patterns: []string{
+				"(silencia|mutea|apaga|calla) [el|mi] (micro|microfono|mic)",
+				"mute [my|the] (mic|microphone)",
+			},
+			Params: map[string]interface{}{"source": micSource},
+			Reply:  "Micrófono silenciado.",
+		},
+		config.IntentRule{
+			Action: "obs.unmute",
+			Patterns: []string{
+				"(activa|desmutea|desilencia|enciende|prende) [el|mi] (micro|microfono|mic)",
+				"unmute [my|the] (mic|microphone)",
+			},
+			Params: map[string]interface{}{"source": micSource},
+			Reply:  "Micrófono activado.",
+		},
+	)
+}
+
+// MacroRules returns the rules that run each macro by its phrases
+func MacroRules(macros []config.MacroConfig) []config.IntentRule {
+	var rules []config.IntentRule
+	for _, m := range macros {
+		if len(m.Phrases) == 0 {
+			continue
+		}
+		rules = append(rules, config.IntentRule{
+			Action:   "macro." + m.Name,
+			Patterns: m.Phrases,
+			Reply:    m.Reply,
+		})
+	}
+	return rules
+}
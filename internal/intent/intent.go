@@ -0,0 +1,190 @@
+// Package intent provides a deterministic fast path that maps common
+// phrasings straight to actions without a round trip to the LLM
+package intent
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jarvisstreamer/jarvis/internal/config"
+	"github.com/jarvisstreamer/jarvis/internal/llm"
+)
+
+// fillerWords are dropped from the start of an utterance before matching
+var fillerWords = map[string]bool{
+	"jarvis": true, "oye": true, "hey": true, "ey": true, "ok": true, "okay": true,
+}
+
+// rule is a compiled intent rule
+type rule struct {
+	cfg      config.IntentRule
+	patterns []*pattern
+}
+
+// Matcher maps utterances to actions using user and built-in rules
+type Matcher struct {
+	enabled bool
+	user    []*rule
+	builtin []*rule
+}
+
+// candidate is a rule that matched an utterance
+type candidate struct {
+	rule     *rule
+	params   map[string]interface{}
+	spoken   map[string]string
+	literals int
+}
+
+// NewMatcher compiles the configured rules. Invalid rules are skipped and
+// reported in the returned error; the matcher is usable either way.
+func NewMatcher(cfg config.IntentsConfig) (*Matcher, error) {
+	m := &Matcher{enabled: cfg.Enabled}
+	var errs []error
+
+	m.user, errs = compileRules(cfg.Rules)
+	if !cfg.DisableBuiltin {
+		builtin, builtinErrs := compileRules(BuiltinRules(cfg.MicSource))
+		m.builtin = builtin
+		errs = append(errs, builtinErrs...)
+	}
+
+	return m, errors.Join(errs...)
+}
+
+// compileRules compiles every pattern of every rule
+func compileRules(rules []config.IntentRule) ([]*rule, []error) {
+	var compiled []*rule
+	var errs []error
+
+	for i, cfg := range rules {
+		if cfg.Action == "" {
+			errs = append(errs, fmt.Errorf("intent rule %d has no action", i+1))
+			continue
+		}
+		r := &rule{cfg: cfg}
+		for _, src := range cfg.Patterns {
+			p, err := compilePattern(src)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("intent rule %s: %w", cfg.Action, err))
+				continue
+			}
+			r.patterns = append(r.patterns, p)
+		}
+		if len(r.patterns) > 0 {
+			compiled = append(compiled, r)
+		}
+	}
+
+	return compiled, errs
+}
+
+// Match returns the action for an utterance when exactly one rule matches
+// it with the highest specificity. User rules take precedence over the
+// built-in ones; ambiguous or unmatched input returns false.
+func (m *Matcher) Match(text string) (llm.Action, bool) {
+	if m == nil || !m.enabled {
+		return llm.Action{}, false
+	}
+
+	original, normalized := tokenize(text)
+	original, normalized = stripFillers(original, normalized)
+	if len(normalized) == 0 {
+		return llm.Action{}, false
+	}
+
+	for _, rules := range [][]*rule{m.user, m.builtin} {
+		best, ambiguous := bestMatch(rules, original, normalized)
+		if ambiguous {
+			return llm.Action{}, false
+		}
+		if best != nil {
+			return best.action(), true
+		}
+	}
+
+	return llm.Action{}, false
+}
+
+// bestMatch returns the most specific matching rule, or ambiguous if
+// several equally specific rules disagree on the resulting action
+func bestMatch(rules []*rule, original, normalized []string) (*candidate, bool) {
+	var best []*candidate
+	for _, r := range rules {
+		for _, p := range r.patterns {
+			params, spoken, ok := p.match(original, normalized)
+			if !ok {
+				continue
+			}
+			c := &candidate{rule: r, params: params, spoken: spoken, literals: p.literals}
+			switch {
+			case len(best) == 0 || c.literals > best[0].literals:
+				best = []*candidate{c}
+			case c.literals == best[0].literals:
+				best = append(best, c)
+			}
+			break
+		}
+	}
+
+	if len(best) == 0 {
+		return nil, false
+	}
+	first := best[0].action()
+	for _, c := range best[1:] {
+		other := c.action()
+		if other.Action != first.Action || !reflect.DeepEqual(other.Params, first.Params) {
+			return nil, true
+		}
+	}
+	return best[0], false
+}
+
+// action builds the llm.Action for a matched rule
+func (c *candidate) action() llm.Action {
+	params := make(map[string]interface{}, len(c.rule.cfg.Params)+len(c.params))
+	for k, v := range c.rule.cfg.Params {
+		params[k] = v
+	}
+	for k, v := range c.params {
+		params[k] = v
+	}
+
+	reply := c.rule.cfg.Reply
+	for name, value := range c.spoken {
+		reply = strings.ReplaceAll(reply, "${"+name+"}", value)
+	}
+
+	return llm.Action{
+		Action: c.rule.cfg.Action,
+		Params: params,
+		Reply:  reply,
+	}
+}
+
+// stripFillers drops leading fillers ("oye jarvis"), a leading or trailing
+// "por favor" and the clause breaks around them
+func stripFillers(original, normalized []string) ([]string, []string) {
+	for len(normalized) > 0 && (fillerWords[normalized[0]] || normalized[0] == clauseBreak) {
+		original, normalized = original[1:], normalized[1:]
+	}
+	if len(normalized) >= 2 && normalized[0] == "por" && normalized[1] == "favor" {
+		original, normalized = original[2:], normalized[2:]
+	}
+	for len(normalized) > 0 && normalized[0] == clauseBreak {
+		original, normalized = original[1:], normalized[1:]
+	}
+	for {
+		n := len(normalized)
+		switch {
+		case n >= 1 && (normalized[n-1] == clauseBreak || normalized[n-1] == "jarvis"):
+			original, normalized = original[:n-1], normalized[:n-1]
+		case n >= 2 && normalized[n-2] == "por" && normalized[n-1] == "favor":
+			original, normalized = original[:n-2], normalized[:n-2]
+		default:
+			return original, normalized
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package intent
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jarvisstreamer/jarvis/internal/config"
+)
+
+func newTestMatcher(t *testing.T, rules ...config.IntentRule) *Matcher {
+	t.Helper()
+	m, err := NewMatcher(config.IntentsConfig{Enabled: true, Rules: rules})
+	if err != nil {
+		t.Fatalf("NewMatcher: %v", err)
+	}
+	return m
+}
+
+func TestMatchBuiltin(t *testing.T) {
+	m := newTestMatcher(t)
+
+	tests := []struct {
+		text   string
+		action string
+		params map[string]interface{}
+	}{
+		{"Jarvis, siguiente canción.", "music.next", map[string]interface{}{}},
+		{"oye Jarvis pausa la música por favor", "music.pause", map[string]interface{}{}},
+		{"Jarvis, pon la escena BRB.", "obs.scene", map[string]interface{}{"scene": "BRB"}},
+		{"cambia a la escena Just Chatting", "obs.scene", map[string]interface{}{"scene": "Just Chatting"}},
+		{"pon el volumen de la música al cincuenta por ciento", "music.volume", map[string]interface{}{"volume": 0.5}},
+		{"pon la música al 35%", "music.volume", map[string]interface{}{"volume": 0.35}},
+	}
+	for _, tt := range tests {
+		action, ok := m.Match(tt.text)
+		if !ok {
+			t.Errorf("Match(%q) didn't match, want %s", tt.text, tt.action)
+			continue
+		}
+		if action.Action != tt.action || !reflect.DeepEqual(action.Params, tt.params) {
+			t.Errorf("Match(%q) = %s %v, want %s %v", tt.text, action.Action, action.Params, tt.action, tt.params)
+		}
+	}
+}
+
+func TestMatchStopsAtClauseBoundaries(t *testing.T) {
+	m := newTestMatcher(t)
+
+	// Several requests in one utterance are a plan for the LLM, not a
+	// scene named after the rest of the sentence
+	for _, text := range []string{
+		"Jarvis, pon la escena BRB, pausa la música y cambia el título a vuelvo en 5",
+		"pon la escena BRB y pausa la música",
+		"pon la escena BRB. Luego pausa la música",
+		"pon música de Queen, y sube el volumen",
+	} {
+		if action, ok := m.Match(text); ok {
+			t.Errorf("Match(%q) = %s %v, want no match", text, action.Action, action.Params)
+		}
+	}
+}
+
+func TestMatchNoMatch(t *testing.T) {
+	m := newTestMatcher(t)
+	for _, text := range []string{"", "Jarvis", "qué tiempo hace mañana", "cuéntame un chiste"} {
+		if action, ok := m.Match(text); ok {
+			t.Errorf("Match(%q) = %s, want no match", text, action.Action)
+		}
+	}
+}
+
+func TestMatchUserRulesFirst(t *testing.T) {
+	m := newTestMatcher(t, config.IntentRule{
+		Action:   "obs.scene",
+		Patterns: []string{"modo descanso"},
+		Params:   map[string]interface{}{"scene": "BRB"},
+		Reply:    "Escena de descanso.",
+	}, config.IntentRule{
+		Action:   "music.volume",
+		Patterns: []string{"siguiente cancion"},
+	})
+
+	action, ok := m.Match("Jarvis, modo descanso")
+	if !ok || action.Action != "obs.scene" || action.Params["scene"] != "BRB" || action.Reply != "Escena de descanso." {
+		t.Errorf("Match(modo descanso) = %+v, %v", action, ok)
+	}
+	if action, _ := m.Match("siguiente canción"); action.Action != "music.volume" {
+		t.Errorf("user rule didn't override the built-in one: got %s", action.Action)
+	}
+}
+
+func TestMatchReplyPlaceholders(t *testing.T) {
+	m := newTestMatcher(t)
+	action, ok := m.Match("pon la música al veinticinco por ciento")
+	if !ok {
+		t.Fatal("no match")
+	}
+	if want := "Volumen de la música al 25 por ciento."; action.Reply != want {
+		t.Errorf("Reply = %q, want %q", action.Reply, want)
+	}
+}
+
+func TestCompilePatternErrors(t *testing.T) {
+	for _, src := range []string{
+		"${scene}",
+		"pon (la escena ${scene}",
+		"pon [la escena",
+		"pon ${scene",
+		"pon ${:text}",
+		"pon ${scene:color}",
+		"pon (|la) escena",
+	} {
+		if _, err := compilePattern(src); err == nil {
+			t.Errorf("compilePattern(%q) succeeded, want an error", src)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	original, normalized := tokenize("¡Jarvis, sube el volumen a 2,5... y pon Canción!")
+	wantOriginal := []string{"Jarvis", ",", "sube", "el", "volumen", "a", "2,5", ",", "y", "pon", "Canción", ","}
+	wantNormalized := []string{"jarvis", ",", "sube", "el", "volumen", "a", "2,5", ",", "y", "pon", "cancion", ","}
+	if !reflect.DeepEqual(original, wantOriginal) {
+		t.Errorf("original = %q, want %q", original, wantOriginal)
+	}
+	if !reflect.DeepEqual(normalized, wantNormalized) {
+		t.Errorf("normalized = %q, want %q", normalized, wantNormalized)
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"25", 25},
+		{"2,5", 2.5},
+		{"veinticinco", 25},
+		{"ciento treinta y dos", 132},
+		{"dos punto cinco", 2.5},
+		{"tres y medio", 3.5},
+		{"mil doscientos", 1200},
+	}
+	for _, tt := range tests {
+		got, ok := ParseNumber(tt.in)
+		if !ok || got != tt.want {
+			t.Errorf("ParseNumber(%q) = %v, %v, want %v", tt.in, got, ok, tt.want)
+		}
+	}
+	if _, ok := ParseNumber("escena"); ok {
+		t.Error("ParseNumber(escena) succeeded")
+	}
+}
+
+func TestMatchMicSource(t *testing.T) {
+	m := newTestMatcher(t)
+	if action, ok := m.Match("Jarvis, silencia el micro"); ok {
+		t.Errorf("Match without a mic source = %s %v, want it left to the LLM", action.Action, action.Params)
+	}
+
+	m, err := NewMatcher(config.IntentsConfig{Enabled: true, MicSource: "Micro XLR"})
+	if err != nil {
+		t.Fatalf("NewMatcher: %v", err)
+	}
+	for text, want := range map[string]string{"Jarvis, silencia el micro": "obs.mute", "activa el micrófono": "obs.unmute"} {
+		action, ok := m.Match(text)
+		if !ok || action.Action != want || action.Params["source"] != "Micro XLR" {
+			t.Errorf("Match(%q) = %s %v, %v; want %s on Micro XLR", text, action.Action, action.Params, ok, want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package intent
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// numberWords maps normalized Spanish number words to their value
+var numberWords = map[string]float64{
+	"cero": 0, "un": 1, "uno": 1, "una": 1, "dos": 2, "tres": 3, "cuatro": 4,
+	"cinco": 5, "seis": 6, "siete": 7, "ocho": 8, "nueve": 9, "diez": 10,
+	"once": 11, "doce": 12, "trece": 13, "catorce": 14, "quince": 15,
+	"dieciseis": 16, "diecisiete": 17, "dieciocho": 18, "diecinueve": 19,
+	"veinte": 20, "veintiun": 21, "veintiuno": 21, "veintiuna": 21, "veintidos": 22,
+	"veintitres": 23, "veinticuatro": 24, "veinticinco": 25, "veintiseis": 26,
+	"veintisiete": 27, "veintiocho": 28, "veintinueve": 29,
+	"treinta": 30, "cuarenta": 40, "cincuenta": 50, "sesenta": 60,
+	"setenta": 70, "ochenta": 80, "noventa": 90,
+	"cien": 100, "ciento": 100, "doscientos": 200, "trescientos": 300,
+	"cuatrocientos": 400, "quinientos": 500, "seiscientos": 600,
+	"setecientos": 700, "ochocientos": 800, "novecientos": 900,
+}
+
+// Words that may appear inside a spoken number without being numbers themselves
+const (
+	wordAnd      = "y"
+	wordThousand = "mil"
+	wordHalf     = "medio"
+)
+
+// decimalWords introduce the decimal part of a spoken number
+var decimalWords = map[string]bool{"punto": true, "coma": true}
+
+// isNumberToken returns true if a normalized token can be part of a number
+func isNumberToken(token string) bool {
+	if _, ok := numberWords[token]; ok {
+		return true
+	}
+	switch token {
+	case wordAnd, wordThousand, wordHalf, "media", "%":
+		return true
+	}
+	if decimalWords[token] {
+		return true
+	}
+	_, err := parseDigits(token)
+	return err == nil
+}
+
+// parseDigits parses "25", "2.5", "2,5" or "25%"
+func parseDigits(token string) (float64, error) {
+	token = strings.TrimSuffix(token, "%")
+	token = strings.Replace(token, ",", ".", 1)
+	return strconv.ParseFloat(token, 64)
+}
+
+// ParseNumber converts digits or Spanish number words ("veinticinco",
+// "ciento treinta y dos", "dos punto cinco", "tres y medio") to a number
+func ParseNumber(s string) (float64, bool) {
+	tokens := strings.Fields(s)
+	if len(tokens) > 0 && tokens[len(tokens)-1] == "%" {
+		tokens = tokens[:len(tokens)-1]
+	}
+	if len(tokens) == 0 {
+		return 0, false
+	}
+
+	if len(tokens) == 1 {
+		if v, err := parseDigits(tokens[0]); err == nil {
+			return v, true
+		}
+	}
+
+	var total, current float64
+	seen := false
+	for i, token := range tokens {
+		switch {
+		case token == wordAnd:
+			if !seen || i == len(tokens)-1 {
+				return 0, false
+			}
+		case token == wordThousand:
+			if current == 0 {
+				current = 1
+			}
+			total += current * 1000
+			current = 0
+			seen = true
+		case token == wordHalf || token == "media":
+			if !seen {
+				return 0, false
+			}
+			current += 0.5
+		case decimalWords[token]:
+			if !seen {
+				return 0, false
+			}
+			frac, ok := parseFraction(tokens[i+1:])
+			if !ok {
+				return 0, false
+			}
+			return total + current + frac, true
+		default:
+			v, ok := numberWords[token]
+			if !ok {
+				d, err := parseDigits(token)
+				if err != nil {
+					return 0, false
+				}
+				v = d
+			}
+			current += v
+			seen = true
+		}
+	}
+
+	if !seen {
+		return 0, false
+	}
+	return total + current, true
+}
+
+// parseFraction parses the words after "punto"/"coma" as a decimal fraction
+func parseFraction(tokens []string) (float64, bool) {
+	if len(tokens) == 0 {
+		return 0, false
+	}
+
+	// "punto cero cinco" is read digit by digit
+	if len(tokens) > 1 {
+		var digits strings.Builder
+		for _, token := range tokens {
+			v, ok := numberWords[token]
+			if !ok || v > 9 {
+				digits.Reset()
+				break
+			}
+			digits.WriteString(strconv.Itoa(int(v)))
+		}
+		if digits.Len() > 0 {
+			v, err := strconv.ParseFloat("0."+digits.String(), 64)
+			return v, err == nil
+		}
+	}
+
+	// "punto veinticinco" is read as a whole number
+	n, ok := ParseNumber(strings.Join(tokens, " "))
+	if !ok || n != math.Trunc(n) {
+		return 0, false
+	}
+	places := len(strconv.Itoa(int(n)))
+	return n / math.Pow(10, float64(places)), true
+}
@@ -0,0 +1,261 @@
+package intent
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/jarvisstreamer/jarvis/pkg/utils"
+)
+
+// Capture kinds accepted in ${name:kind} placeholders
+const (
+	kindText    = "text"
+	kindNumber  = "number"
+	kindInt     = "int"
+	kindPercent = "percent"
+)
+
+// clauseBreak is the word punctuation between clauses becomes
+const clauseBreak = ","
+
+// clausePunctuation ends a clause
+const clausePunctuation = ",;:.!?"
+
+// clauseWords separate clauses; text captures can't contain them
+var clauseWords = map[string]bool{
+	clauseBreak: true, "y": true, "luego": true,
+}
+
+// capture is a named placeholder of a pattern
+type capture struct {
+	name string
+	kind string
+}
+
+// pattern is a compiled phrasing of a rule
+type pattern struct {
+	source   string
+	re       *regexp.Regexp
+	captures []capture
+	literals int // Required literal words, used to prefer the most specific match
+}
+
+// numberTokenPattern matches one token of a spoken or written number
+var numberTokenPattern = func() string {
+	words := make([]string, 0, len(numberWords)+6)
+	for w := range numberWords {
+		words = append(words, w)
+	}
+	words = append(words, wordAnd, wordThousand, wordHalf, "media", "punto", "coma")
+	// Longest first so "veintiuno" wins over "veinti..." prefixes
+	sort.Slice(words, func(i, j int) bool { return len(words[i]) > len(words[j]) })
+	return `(?:\d+(?:[.,]\d+)?%?|%|` + strings.Join(words, "|") + `)`
+}()
+
+// compilePattern turns "(pon|cambia a) [la] escena ${scene}" into a regular
+// expression over normalized, space-terminated words
+func compilePattern(src string) (*pattern, error) {
+	p := &pattern{source: src}
+	var re strings.Builder
+	var word strings.Builder
+
+	flush := func() {
+		for _, w := range strings.Fields(utils.NormalizeText(word.String())) {
+			re.WriteString(regexp.QuoteMeta(w) + " ")
+			p.literals++
+		}
+		word.Reset()
+	}
+
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t':
+			flush()
+
+		case c == '[' || c == '(':
+			flush()
+			closer := byte(']')
+			if c == '(' {
+				closer = ')'
+			}
+			end := strings.IndexByte(src[i+1:], closer)
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed %q in pattern %q", c, src)
+			}
+			group, err := compileGroup(src[i+1 : i+1+end])
+			if err != nil {
+				return nil, fmt.Errorf("%w in pattern %q", err, src)
+			}
+			re.WriteString("(?:" + group + ")")
+			if c == '[' {
+				re.WriteString("?")
+			} else {
+				p.literals++
+			}
+			i += end + 1
+
+		case c == '$' && i+1 < len(src) && src[i+1] == '{':
+			flush()
+			end := strings.IndexByte(src[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed placeholder in pattern %q", src)
+			}
+			capt, err := parsePlaceholder(src[i+2 : i+end])
+			if err != nil {
+				return nil, fmt.Errorf("%w in pattern %q", err, src)
+			}
+			p.captures = append(p.captures, capt)
+			if capt.kind == kindText {
+				re.WriteString(`((?:\S+ )+?)`)
+			} else {
+				re.WriteString(`((?:` + numberTokenPattern + ` )+?)`)
+			}
+			i += end
+
+		default:
+			word.WriteByte(c)
+		}
+	}
+	flush()
+
+	if p.literals == 0 {
+		return nil, fmt.Errorf("pattern %q has no fixed words", src)
+	}
+
+	compiled, err := regexp.Compile("^" + re.String() + "$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", src, err)
+	}
+	p.re = compiled
+	return p, nil
+}
+
+// compileGroup compiles the "a|b c" alternatives of a [] or () group
+func compileGroup(inner string) (string, error) {
+	var alts []string
+	for _, alt := range strings.Split(inner, "|") {
+		words := strings.Fields(utils.NormalizeText(alt))
+		if len(words) == 0 {
+			return "", fmt.Errorf("empty alternative in group %q", inner)
+		}
+		var b strings.Builder
+		for _, w := range words {
+			b.WriteString(regexp.QuoteMeta(w) + " ")
+		}
+		alts = append(alts, b.String())
+	}
+	return strings.Join(alts, "|"), nil
+}
+
+// parsePlaceholder parses the "name" or "name:kind" inside ${...}
+func parsePlaceholder(spec string) (capture, error) {
+	name, kind, _ := strings.Cut(spec, ":")
+	name = strings.TrimSpace(name)
+	kind = strings.TrimSpace(kind)
+	if name == "" {
+		return capture{}, fmt.Errorf("placeholder without name")
+	}
+	switch kind {
+	case "":
+		kind = kindText
+	case kindText, kindNumber, kindInt, kindPercent:
+	default:
+		return capture{}, fmt.Errorf("unknown placeholder type %q", kind)
+	}
+	return capture{name: name, kind: kind}, nil
+}
+
+// match matches normalized words against the pattern and returns the
+// captured params and their spoken form
+func (p *pattern) match(original, normalized []string) (map[string]interface{}, map[string]string, bool) {
+	s := strings.Join(normalized, " ") + " "
+	loc := p.re.FindStringSubmatchIndex(s)
+	if loc == nil {
+		return nil, nil, false
+	}
+
+	params := make(map[string]interface{}, len(p.captures))
+	spoken := make(map[string]string, len(p.captures))
+	for i, capt := range p.captures {
+		start, end := loc[2*(i+1)], loc[2*(i+1)+1]
+		first := strings.Count(s[:start], " ")
+		last := strings.Count(s[:end], " ")
+
+		if capt.kind == kindText {
+			// A capture running over a clause boundary swallowed another
+			// request ("pon la escena BRB, pausa la música"); that's the
+			// LLM's job
+			for _, w := range normalized[first:last] {
+				if clauseWords[w] {
+					return nil, nil, false
+				}
+			}
+
+			// Keep the original casing so names like "BRB" reach OBS untouched
+			value := strings.Join(original[first:last], " ")
+			params[capt.name] = value
+			spoken[capt.name] = value
+			continue
+		}
+
+		n, ok := ParseNumber(strings.Join(normalized[first:last], " "))
+		if !ok {
+			return nil, nil, false
+		}
+		spoken[capt.name] = strconv.FormatFloat(n, 'f', -1, 64)
+		switch capt.kind {
+		case kindInt:
+			params[capt.name] = int(n + 0.5)
+		case kindPercent:
+			params[capt.name] = n / 100
+		default:
+			params[capt.name] = n
+		}
+	}
+
+	return params, spoken, true
+}
+
+// tokenize splits an utterance into words, returning each word as spoken
+// (without punctuation) and in normalized form. Decimal separators between
+// digits are kept so "2.5" stays one word, and punctuation between clauses
+// becomes a clauseBreak word.
+func tokenize(text string) (original, normalized []string) {
+	runes := []rune(text)
+	var word []rune
+	flush := func() {
+		if len(word) == 0 {
+			return
+		}
+		w := string(word)
+		original = append(original, w)
+		normalized = append(normalized, strings.ToLower(utils.RemoveAccents(w)))
+		word = word[:0]
+	}
+
+	for i, r := range runes {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '%':
+			word = append(word, r)
+		case (r == '.' || r == ',') && i > 0 && i+1 < len(runes) &&
+			unicode.IsDigit(runes[i-1]) && unicode.IsDigit(runes[i+1]):
+			word = append(word, r)
+		case strings.ContainsRune(clausePunctuation, r):
+			flush()
+			if n := len(normalized); n > 0 && normalized[n-1] != clauseBreak {
+				original = append(original, clauseBreak)
+				normalized = append(normalized, clauseBreak)
+			}
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	return original, normalized
+}
@@ -57,6 +57,25 @@ func (a *autoProvider) Complete(ctx context.Context, prompt string) (Action, err
 	return p.Complete(ctx, prompt)
 }
 
+func (a *autoProvider) CompleteChat(ctx context.Context, messages []Message) (Action, error) {
+	p, err := a.selectProvider(ctx)
+	if err != nil {
+		return Action{}, err
+	}
+	return p.CompleteChat(ctx, messages)
+}
+
+func (a *autoProvider) CompleteChatStream(ctx context.Context, messages []Message, onReply func(delta string)) (Action, error) {
+	p, err := a.selectProvider(ctx)
+	if err != nil {
+		return Action{}, err
+	}
+	if sp, ok := p.(StreamingProvider); ok {
+		return sp.CompleteChatStream(ctx, messages, onReply)
+	}
+	return p.CompleteChat(ctx, messages)
+}
+
 func (a *autoProvider) CompleteRaw(ctx context.Context, prompt string) (string, error) {
 	p, err := a.selectProvider(ctx)
 	if err != nil {
@@ -4,15 +4,30 @@ package llm
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/jarvisstreamer/jarvis/internal/config"
 )
 
+// ActionPlan is the action name for an ordered list of actions
+const ActionPlan = "plan"
+
+// Plan error policies
+const (
+	PlanStopOnError     = "stop"     // Abort the remaining steps after a failure (default)
+	PlanContinueOnError = "continue" // Run every step regardless of failures
+)
+
 // Action represents a parsed action from the LLM
 type Action struct {
 	Action string                 `json:"action"`
 	Params map[string]interface{} `json:"params"`
 	Reply  string                 `json:"reply"`
+
+	// Steps holds the ordered actions of a plan (Action == "plan")
+	Steps []Action `json:"steps,omitempty"`
+	// OnError is the plan policy when a step fails: "stop" or "continue"
+	OnError string `json:"on_error,omitempty"`
 }
 
 // IsEmpty returns true if the action is empty/invalid
@@ -20,6 +35,55 @@ func (a Action) IsEmpty() bool {
 	return a.Action == ""
 }
 
+// IsPlan returns true if the action is a multi-step plan
+func (a Action) IsPlan() bool {
+	return a.Action == ActionPlan || len(a.Steps) > 0
+}
+
+// ContinueOnError returns true if the plan should keep running after a failed step
+func (a Action) ContinueOnError() bool {
+	return strings.EqualFold(a.OnError, PlanContinueOnError)
+}
+
+// Normalize fills defaults and validates an action that didn't come from the LLM
+func (a *Action) Normalize() error {
+	return normalizeAction(a)
+}
+
+// normalizeAction fills defaults and validates an action parsed from the LLM
+func normalizeAction(action *Action) error {
+	if action.Params == nil {
+		action.Params = make(map[string]interface{})
+	}
+
+	if action.Action == "" && len(action.Steps) > 0 {
+		action.Action = ActionPlan
+	}
+
+	if action.Action == "" {
+		return fmt.Errorf("action field is empty")
+	}
+
+	if action.Action == ActionPlan {
+		if len(action.Steps) == 0 {
+			return fmt.Errorf("plan has no steps")
+		}
+		for i := range action.Steps {
+			if action.Steps[i].IsPlan() {
+				return fmt.Errorf("nested plans are not supported (step %d)", i+1)
+			}
+			if err := normalizeAction(&action.Steps[i]); err != nil {
+				return fmt.Errorf("invalid plan step %d: %w", i+1, err)
+			}
+		}
+		if action.OnError == "" {
+			action.OnError = PlanStopOnError
+		}
+	}
+
+	return nil
+}
+
 // GetStringParam gets a string parameter from the action
 func (a Action) GetStringParam(key string) string {
 	if v, ok := a.Params[key]; ok {
@@ -70,6 +134,28 @@ func (a Action) GetBoolParam(key string) bool {
 	return false
 }
 
+// Message roles used in chat conversations
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message is a single turn of a chat conversation
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// withSystemPrompt prepends the default system prompt unless the
+// conversation already starts with a system message
+func withSystemPrompt(messages []Message) []Message {
+	if len(messages) > 0 && messages[0].Role == RoleSystem {
+		return messages
+	}
+	return append([]Message{{Role: RoleSystem, Content: GetSystemPrompt()}}, messages...)
+}
+
 // Provider is the interface for LLM providers
 type Provider interface {
 	// Name returns the provider name
@@ -78,6 +164,10 @@ type Provider interface {
 	// Complete sends a prompt to the LLM and returns an Action
 	Complete(ctx context.Context, prompt string) (Action, error)
 
+	// CompleteChat sends a conversation (oldest first, ending with the
+	// user's latest message) and returns an Action
+	CompleteChat(ctx context.Context, messages []Message) (Action, error)
+
 	// CompleteRaw sends a prompt and returns the raw response
 	CompleteRaw(ctx context.Context, prompt string) (string, error)
 
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/jarvisstreamer/jarvis/internal/config"
+	"github.com/jarvisstreamer/jarvis/internal/events"
 	"github.com/jarvisstreamer/jarvis/pkg/logger"
 	"github.com/jarvisstreamer/jarvis/pkg/utils"
 	"github.com/rs/zerolog"
@@ -50,6 +51,23 @@ type OllamaResponse struct {
 	CreatedAt string `json:"created_at"`
 }
 
+// OllamaChatRequest represents a request to the Ollama chat API
+type OllamaChatRequest struct {
+	Model    string         `json:"model"`
+	Messages []Message      `json:"messages"`
+	Stream   bool           `json:"stream"`
+	Format   string         `json:"format,omitempty"`
+	Options  *OllamaOptions `json:"options,omitempty"`
+}
+
+// OllamaChatResponse represents a response from the Ollama chat API
+type OllamaChatResponse struct {
+	Model     string  `json:"model"`
+	Message   Message `json:"message"`
+	Done      bool    `json:"done"`
+	CreatedAt string  `json:"created_at"`
+}
+
 // OllamaTagsResponse represents the response from /api/tags
 type OllamaTagsResponse struct {
 	Models []struct {
@@ -82,15 +100,31 @@ func (p *OllamaProvider) Name() string {
 
 // Complete sends a prompt to Ollama and returns an Action
 func (p *OllamaProvider) Complete(ctx context.Context, prompt string) (Action, error) {
-	p.log.Debug().Str("prompt", prompt).Msg("Sending prompt to Ollama")
+	return p.CompleteChat(ctx, []Message{{Role: RoleUser, Content: prompt}})
+}
+
+// CompleteChat sends a conversation to Ollama via /api/chat and returns an Action
+func (p *OllamaProvider) CompleteChat(ctx context.Context, messages []Message) (Action, error) {
+	return p.CompleteChatStream(ctx, messages, nil)
+}
+
+// CompleteChatStream is CompleteChat with the reply of a conversational
+// answer passed to onReply as Ollama generates it; with a nil onReply the
+// completion is requested in one piece
+func (p *OllamaProvider) CompleteChatStream(ctx context.Context, messages []Message, onReply func(delta string)) (Action, error) {
+	p.log.Debug().Int("messages", len(messages)).Msg("Sending conversation to Ollama")
+
+	start := time.Now()
+	defer func() {
+		events.Publish(events.Timing{Stage: "llm", Provider: p.Name(), Duration: time.Since(start)})
+	}()
 
 	// Create request
-	reqBody := OllamaRequest{
-		Model:  p.model,
-		Prompt: prompt,
-		System: GetSystemPrompt(),
-		Stream: false,
-		Format: "json", // Force JSON output
+	reqBody := OllamaChatRequest{
+		Model:    p.model,
+		Messages: withSystemPrompt(messages),
+		Stream:   onReply != nil,
+		Format:   "json", // Force JSON output
 		Options: &OllamaOptions{
 			Temperature: 0.3,
 			NumPredict:  500,
@@ -103,7 +137,7 @@ func (p *OllamaProvider) Complete(ctx context.Context, prompt string) (Action, e
 	}
 
 	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, "POST", p.url+"/api/generate", bytes.NewReader(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", p.url+"/api/chat", bytes.NewReader(jsonBody))
 	if err != nil {
 		return Action{}, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -116,28 +150,34 @@ func (p *OllamaProvider) Complete(ctx context.Context, prompt string) (Action, e
 	}
 	defer resp.Body.Close()
 
-	// Read response
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return Action{}, fmt.Errorf("failed to read response: %w", err)
-	}
-
 	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
 		return Action{}, fmt.Errorf("Ollama returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	// Parse Ollama response
-	var ollamaResp OllamaResponse
-	if err := json.Unmarshal(body, &ollamaResp); err != nil {
-		return Action{}, fmt.Errorf("failed to parse Ollama response: %w", err)
+	// Streamed, the body holds one chunk per JSON object; otherwise a single one
+	stream := newReplyStream(onReply)
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var chunk OllamaChatResponse
+		if err := decoder.Decode(&chunk); err == io.EOF {
+			break
+		} else if err != nil {
+			return Action{}, fmt.Errorf("failed to parse Ollama response: %w", err)
+		}
+		stream.write(chunk.Message.Content)
+		if chunk.Done || !reqBody.Stream {
+			break
+		}
 	}
 
-	p.log.Debug().Str("response", ollamaResp.Response).Msg("Received response from Ollama")
+	content := stream.String()
+	p.log.Debug().Str("response", content).Msg("Received response from Ollama")
 
 	// Parse the action from the response
-	action, err := p.parseAction(ollamaResp.Response)
+	action, err := p.parseAction(content)
 	if err != nil {
-		p.log.Warn().Err(err).Str("raw_response", ollamaResp.Response).Msg("Failed to parse action, returning fallback")
+		p.log.Warn().Err(err).Str("raw_response", content).Msg("Failed to parse action, returning fallback")
 		return Action{
 			Action: "none",
 			Params: map[string]interface{}{},
@@ -243,14 +283,9 @@ func (p *OllamaProvider) parseAction(response string) (Action, error) {
 		return Action{}, fmt.Errorf("failed to parse action JSON: %w", err)
 	}
 
-	// Initialize params map if nil
-	if action.Params == nil {
-		action.Params = make(map[string]interface{})
-	}
-
-	// Validate action
-	if action.Action == "" {
-		return Action{}, fmt.Errorf("action field is empty")
+	// Fill defaults and validate (including plan steps)
+	if err := normalizeAction(&action); err != nil {
+		return Action{}, err
 	}
 
 	return action, nil
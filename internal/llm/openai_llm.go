@@ -1,15 +1,18 @@
 package llm
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/jarvisstreamer/jarvis/internal/config"
+	"github.com/jarvisstreamer/jarvis/internal/events"
 	"github.com/jarvisstreamer/jarvis/pkg/logger"
 	"github.com/jarvisstreamer/jarvis/pkg/utils"
 	"github.com/rs/zerolog"
@@ -33,6 +36,7 @@ type OpenAIChatRequest struct {
 	Temperature    float64         `json:"temperature,omitempty"`
 	MaxTokens      int             `json:"max_tokens,omitempty"`
 	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+	Stream         bool            `json:"stream,omitempty"`
 }
 
 // ResponseFormat specifies the output format
@@ -68,6 +72,17 @@ type OpenAIChatResponse struct {
 	Error *OpenAIError `json:"error,omitempty"`
 }
 
+// OpenAIStreamChunk represents one server-sent event of a streamed completion
+type OpenAIStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Error *OpenAIError `json:"error,omitempty"`
+}
+
 // OpenAIError represents an error from the OpenAI API
 type OpenAIError struct {
 	Message string `json:"message"`
@@ -109,26 +124,41 @@ func (p *OpenAIProvider) Name() string {
 
 // Complete sends a prompt to OpenAI and returns an Action
 func (p *OpenAIProvider) Complete(ctx context.Context, prompt string) (Action, error) {
-	p.log.Debug().Str("prompt", prompt).Msg("Sending prompt to OpenAI")
+	return p.CompleteChat(ctx, []Message{{Role: RoleUser, Content: prompt}})
+}
+
+// CompleteChat sends a conversation to OpenAI and returns an Action
+func (p *OpenAIProvider) CompleteChat(ctx context.Context, messages []Message) (Action, error) {
+	return p.CompleteChatStream(ctx, messages, nil)
+}
+
+// CompleteChatStream is CompleteChat with the reply of a conversational
+// answer passed to onReply as OpenAI generates it; with a nil onReply the
+// completion is requested in one piece
+func (p *OpenAIProvider) CompleteChatStream(ctx context.Context, messages []Message, onReply func(delta string)) (Action, error) {
+	p.log.Debug().Int("messages", len(messages)).Msg("Sending conversation to OpenAI")
+
+	start := time.Now()
+	defer func() {
+		events.Publish(events.Timing{Stage: "llm", Provider: p.Name(), Duration: time.Since(start)})
+	}()
+
+	conversation := withSystemPrompt(messages)
+	chatMessages := make([]OpenAIMessage, len(conversation))
+	for i, m := range conversation {
+		chatMessages[i] = OpenAIMessage{Role: m.Role, Content: m.Content}
+	}
 
 	// Create request
 	reqBody := OpenAIChatRequest{
-		Model: p.model,
-		Messages: []OpenAIMessage{
-			{
-				Role:    "system",
-				Content: GetSystemPrompt(),
-			},
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
+		Model:       p.model,
+		Messages:    chatMessages,
 		Temperature: p.temperature,
 		MaxTokens:   500,
 		ResponseFormat: &ResponseFormat{
 			Type: "json_object",
 		},
+		Stream: onReply != nil,
 	}
 
 	jsonBody, err := json.Marshal(reqBody)
@@ -151,33 +181,25 @@ func (p *OpenAIProvider) Complete(ctx context.Context, prompt string) (Action, e
 	}
 	defer resp.Body.Close()
 
-	// Read response
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return Action{}, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	// Parse response
-	var openAIResp OpenAIChatResponse
-	if err := json.Unmarshal(body, &openAIResp); err != nil {
-		return Action{}, fmt.Errorf("failed to parse OpenAI response: %w", err)
-	}
-
-	// Check for API error
-	if openAIResp.Error != nil {
-		return Action{}, fmt.Errorf("OpenAI API error: %s", openAIResp.Error.Message)
-	}
-
+	// Errors come back as a plain JSON body
 	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		var openAIResp OpenAIChatResponse
+		if json.Unmarshal(body, &openAIResp) == nil && openAIResp.Error != nil {
+			return Action{}, fmt.Errorf("OpenAI API error: %s", openAIResp.Error.Message)
+		}
 		return Action{}, fmt.Errorf("OpenAI returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	// Check for valid response
-	if len(openAIResp.Choices) == 0 {
-		return Action{}, fmt.Errorf("no choices in OpenAI response")
+	var content string
+	if reqBody.Stream {
+		content, err = readOpenAIStream(resp.Body, onReply)
+	} else {
+		content, err = readOpenAICompletion(resp.Body)
+	}
+	if err != nil {
+		return Action{}, err
 	}
-
-	content := openAIResp.Choices[0].Message.Content
 	p.log.Debug().Str("response", content).Msg("Received response from OpenAI")
 
 	// Parse the action from the response
@@ -194,6 +216,58 @@ func (p *OpenAIProvider) Complete(ctx context.Context, prompt string) (Action, e
 	return action, nil
 }
 
+// readOpenAICompletion reads the content of a completion sent in one piece
+func readOpenAICompletion(body io.Reader) (string, error) {
+	var openAIResp OpenAIChatResponse
+	if err := json.NewDecoder(body).Decode(&openAIResp); err != nil {
+		return "", fmt.Errorf("failed to parse OpenAI response: %w", err)
+	}
+	if openAIResp.Error != nil {
+		return "", fmt.Errorf("OpenAI API error: %s", openAIResp.Error.Message)
+	}
+	if len(openAIResp.Choices) == 0 {
+		return "", fmt.Errorf("no choices in OpenAI response")
+	}
+	return openAIResp.Choices[0].Message.Content, nil
+}
+
+// readOpenAIStream reads the server-sent events of a streamed completion:
+// "data: {chunk}" lines, ending with "data: [DONE]"
+func readOpenAIStream(body io.Reader, onReply func(delta string)) (string, error) {
+	stream := newReplyStream(onReply)
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk OpenAIStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return "", fmt.Errorf("failed to parse OpenAI response: %w", err)
+		}
+		if chunk.Error != nil {
+			return "", fmt.Errorf("OpenAI API error: %s", chunk.Error.Message)
+		}
+		if len(chunk.Choices) > 0 {
+			stream.write(chunk.Choices[0].Delta.Content)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if stream.String() == "" {
+		return "", fmt.Errorf("no choices in OpenAI response")
+	}
+	return stream.String(), nil
+}
+
 // CompleteRaw sends a prompt and returns the raw response
 func (p *OpenAIProvider) CompleteRaw(ctx context.Context, prompt string) (string, error) {
 	reqBody := OpenAIChatRequest{
@@ -279,14 +353,9 @@ func (p *OpenAIProvider) parseAction(response string) (Action, error) {
 		return Action{}, fmt.Errorf("failed to parse action JSON: %w", err)
 	}
 
-	// Initialize params map if nil
-	if action.Params == nil {
-		action.Params = make(map[string]interface{})
-	}
-
-	// Validate action
-	if action.Action == "" {
-		return Action{}, fmt.Errorf("action field is empty")
+	// Fill defaults and validate (including plan steps)
+	if err := normalizeAction(&action); err != nil {
+		return Action{}, err
 	}
 
 	return action, nil
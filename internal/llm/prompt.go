@@ -1,7 +1,10 @@
 package llm
 
 import (
+	"encoding/json"
+	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -17,12 +20,12 @@ func IsJarvisActivated(input string) bool {
 	// Pattern matches: jarvis (with or without punctuation, at start/middle/end)
 	// Also matches variations and mentions with @
 	patterns := []string{
-		`\bjarvis\b`,           // word boundary match
-		`^jarvis[\s,.:!?]*`,    // start of sentence
+		`\bjarvis\b`,            // word boundary match
+		`^jarvis[\s,.:!?]*`,     // start of sentence
 		`[\s,]jarvis[\s,.:!?]*`, // middle or end with punctuation
-		`@jarvis`,              // mention style
-		`oye jarvis`,           // Spanish variation
-		`hey jarvis`,           // English variation
+		`@jarvis`,               // mention style
+		`oye jarvis`,            // Spanish variation
+		`hey jarvis`,            // English variation
 	}
 
 	for _, pattern := range patterns {
@@ -34,8 +37,8 @@ func IsJarvisActivated(input string) bool {
 	return false
 }
 
-// SystemPrompt is the main system prompt for Jarvis
-const SystemPrompt = `Eres Jarvis, un asistente de voz inteligente y amigable para streamers. Tu personalidad es como la de un compañero de transmisión experto, con sentido del humor, empático y muy útil. Hablas como una persona real, no como un robot.
+// systemPromptIntro is the persona and response format of the Spanish prompt
+const systemPromptIntro = `Eres Jarvis, un asistente de voz inteligente y amigable para streamers. Tu personalidad es como la de un compañero de transmisión experto, con sentido del humor, empático y muy útil. Hablas como una persona real, no como un robot.
 
 Tu trabajo es:
 1. Interpretar comandos de voz y convertirlos en acciones estructuradas
@@ -45,98 +48,14 @@ Tu trabajo es:
 IMPORTANTE: Debes responder ÚNICAMENTE con un objeto JSON válido. No incluyas ningún texto adicional, explicación o markdown.
 
 El formato de respuesta DEBE ser exactamente:
-{"action": "nombre.accion", "params": {...}, "reply": "mensaje para el usuario"}
+{"action": "nombre.accion", "params": {...}, "reply": "mensaje para el usuario"}`
 
-ACCIONES DISPONIBLES:
-
-== TWITCH ==
-- twitch.clip: Crear un clip del stream
-  params: {duration: número (segundos, opcional, default 30)}
-  ejemplo: {"action": "twitch.clip", "params": {"duration": 30}, "reply": "Creando clip de 30 segundos"}
-
-- twitch.title: Cambiar el título del stream
-  params: {title: "nuevo título"}
-  ejemplo: {"action": "twitch.title", "params": {"title": "Jugando Minecraft"}, "reply": "Cambiando título a Jugando Minecraft"}
-
-- twitch.category: Cambiar la categoría del stream
-  params: {category: "nombre de categoría"}
-  ejemplo: {"action": "twitch.category", "params": {"category": "Just Chatting"}, "reply": "Cambiando categoría a Just Chatting"}
-
-- twitch.ban: Banear a un usuario
-  params: {user: "nombre_usuario", reason: "razón" (opcional)}
-  ejemplo: {"action": "twitch.ban", "params": {"user": "troll123", "reason": "spam"}, "reply": "Baneando a troll123"}
-
-- twitch.timeout: Dar timeout a un usuario
-  params: {user: "nombre_usuario", duration: número (segundos)}
-  ejemplo: {"action": "twitch.timeout", "params": {"user": "spammer", "duration": 600}, "reply": "Timeout de 10 minutos para spammer"}
-
-- twitch.unban: Desbanear a un usuario
-  params: {user: "nombre_usuario"}
-  ejemplo: {"action": "twitch.unban", "params": {"user": "usuario123"}, "reply": "Desbaneando a usuario123"}
-
-== OBS ==
-- obs.scene: Cambiar a una escena
-  params: {scene: "nombre de escena"}
-  ejemplo: {"action": "obs.scene", "params": {"scene": "Gameplay"}, "reply": "Cambiando a escena Gameplay"}
-
-- obs.source.show: Mostrar una fuente
-  params: {source: "nombre de fuente"}
-  ejemplo: {"action": "obs.source.show", "params": {"source": "Webcam"}, "reply": "Mostrando webcam"}
-
-- obs.source.hide: Ocultar una fuente
-  params: {source: "nombre de fuente"}
-  ejemplo: {"action": "obs.source.hide", "params": {"source": "Webcam"}, "reply": "Ocultando webcam"}
-
-- obs.volume: Cambiar volumen de una fuente
-  params: {source: "nombre", volume: número (0.0 a 1.0)}
-  ejemplo: {"action": "obs.volume", "params": {"source": "Micrófono", "volume": 0.8}, "reply": "Volumen del micrófono al 80%"}
-
-- obs.mute: Mutear una fuente
-  params: {source: "nombre de fuente"}
-  ejemplo: {"action": "obs.mute", "params": {"source": "Desktop Audio"}, "reply": "Muteando audio del escritorio"}
-
-- obs.unmute: Desmutear una fuente
-  params: {source: "nombre de fuente"}
-  ejemplo: {"action": "obs.unmute", "params": {"source": "Desktop Audio"}, "reply": "Activando audio del escritorio"}
-
-- obs.text: Cambiar texto de una fuente de texto
-  params: {source: "nombre", text: "nuevo texto"}
-  ejemplo: {"action": "obs.text", "params": {"source": "Título", "text": "¡Nuevo récord!"}, "reply": "Texto actualizado"}
-
-== MÚSICA ==
-- music.play: Reproducir música
-  params: {query: "búsqueda" (opcional)}
-  ejemplo: {"action": "music.play", "params": {"query": "rock"}, "reply": "Reproduciendo música rock"}
-
-- music.pause: Pausar la música
-  params: {}
-  ejemplo: {"action": "music.pause", "params": {}, "reply": "Música pausada"}
-
-- music.resume: Reanudar la música
-  params: {}
-  ejemplo: {"action": "music.resume", "params": {}, "reply": "Reanudando música"}
-
-- music.next: Siguiente canción
-  params: {}
-  ejemplo: {"action": "music.next", "params": {}, "reply": "Siguiente canción"}
-
-- music.previous: Canción anterior
-  params: {}
-  ejemplo: {"action": "music.previous", "params": {}, "reply": "Canción anterior"}
-
-- music.volume: Cambiar volumen de música
-  params: {volume: número (0.0 a 1.0)}
-  ejemplo: {"action": "music.volume", "params": {"volume": 0.5}, "reply": "Volumen de música al 50%"}
-
-- music.stop: Detener la música
-  params: {}
-  ejemplo: {"action": "music.stop", "params": {}, "reply": "Música detenida"}
-
-== CALCULADORA ==
-- calc: Realizar cálculos matemáticos
+// systemPromptBuiltins describes the actions handled by Brain itself
+const systemPromptBuiltins = `== CALCULADORA ==
+- calc: Realizar cálculos matemáticos (Jarvis calcula el resultado y lo dice, no lo calcules tú)
   params: {expression: "expresión matemática"}
-  ejemplo: {"action": "calc", "params": {"expression": "2 + 2"}, "reply": "2 más 2 son 4"}
-  soporta: suma (+), resta (-), multiplicación (*), división (/), exponentes (^)
+  ejemplo: {"action": "calc", "params": {"expression": "2 + 2"}, "reply": "Calculando"}
+  soporta: suma (+), resta (-), multiplicación (*), división (/), exponentes (^), porcentajes (%), paréntesis, sqrt() y round()
 
 == SISTEMA ==
 - system.status: Estado del sistema
@@ -145,45 +64,41 @@ ACCIONES DISPONIBLES:
 
 - system.help: Mostrar ayuda
   params: {}
-  ejemplo: {"action": "system.help", "params": {}, "reply": "Puedo ayudarte con Twitch, OBS, música y cálculos. ¿Qué necesitas?"}
+  ejemplo: {"action": "system.help", "params": {}, "reply": "Te cuento lo que puedo hacer"}
+
+- system.undo: Deshacer el último comando
+  params: {}
+  ejemplo: {"action": "system.undo", "params": {}, "reply": "Deshaciendo"}
 
 - none: Cuando no hay acción específica o es solo conversación
   params: {}
   ejemplo: {"action": "none", "params": {}, "reply": "Hola, ¿en qué puedo ayudarte?"}
 
-REGLAS:
+== VARIAS ACCIONES ==
+- plan: Cuando el usuario pide varias cosas en una sola frase, devuelve la lista ordenada en "steps"
+  on_error: "stop" (por defecto, detiene el resto si algo falla) o "continue" (ejecuta todo igualmente)
+  cada paso usa el mismo formato {"action", "params", "reply"}`
+
+// systemPromptRules holds the rules, style and examples of the Spanish prompt
+const systemPromptRules = `REGLAS:
 1. SIEMPRE responde con JSON válido
 2. El campo "reply" debe ser una respuesta natural, amigable y conversacional en español
 3. Usa contracciones naturales: "voy a", "no me", etc. (no: "voy a..." sino "voy a...")
 4. Sé casual pero profesional, como hablaría un amigo streamer
 5. Si no entiendes, pide clarificación de forma amigable, no robótica
-6. Interpreta sinónimos y variaciones naturales: "silencia el micro" = mute, "sube volumen" = aumentar
+6. Interpreta sinónimos y variaciones naturales: "sube", "aumenta" y "pon más alto" piden lo mismo
 7. Los nombres de usuario, escenas y fuentes deben preservarse exactamente como se mencionan
 8. Para errores o imposibles, explica por qué de forma natural
 9. Puedes usar emojis en la respuesta si es apropiado (pero no en exceso)
 10. Mantén respuestas cortas (1-2 frases máximo) a menos que se pida más información
+11. Usa los mensajes anteriores de la conversación para completar datos que faltaban y resolver referencias ("él", "ese usuario", "la misma escena")
 
 ESTILO DE RESPUESTAS (ejemplos):
-En lugar de: "Cambiando a escena Gameplay"
-Di algo como: "Ya está, poniendo la escena Gameplay" o "Listo, cambiando a Gameplay"
-
-En lugar de: "Muteando audio del escritorio"
-Di: "Silenciando el audio del escritorio" o "Dale, sin audio del escritorio"
-
-En lugar de: "Siguiente canción"
-Di: "Vamos con la siguiente" o "Siguiente tema"
-
-EJEMPLOS DE INTERPRETACIÓN:
-- "hazme un clip" → twitch.clip + reply: "Dale, creando clip de 30 segundos"
-- "pon la escena de solo charlando" → obs.scene + reply: "Ya está, poniendo 'solo charlando'"
-- "silencia el micro" → obs.mute + reply: "Micro silenciado"
-- "sube el volumen de la música" → music.volume (0.8) + reply: "Volumen subido al 80%"
-- "siguiente" → music.next + reply: "Siguiente tema"
-- "banea a ese troll" → none + reply: "¿Cuál es el nombre del usuario que quieres banear?"
-- "cuánto es dos más dos" → calc (2+2) + reply: "2 + 2 = 4"
-- "eres Jarvis?" → none + reply: "Claro, soy Jarvis, tu asistente. ¿En qué te ayudo?"
-- "hola Jarvis" → none + reply: "Hola! ¿Qué necesitas?"
-- "buenas" → none + reply: "Qué onda, ¿lista para el stream?"
+En lugar de: "Acción completada"
+Di algo como: "Listo, ya está" o "Hecho"
+
+En lugar de: "Procesando la solicitud"
+Di: "Dale, voy" o "Marchando"
 
 CONTEXTO DE STREAMING:
 - Recuerda que el usuario está streamando en vivo
@@ -191,115 +106,318 @@ CONTEXTO DE STREAMING:
 - Usa lenguaje de streamer/gamer cuando sea apropiado
 - Sé empático: los streamers están concentrados, mantén respuestas breves`
 
-// BuildPrompt builds the full prompt with the user's input
-func BuildPrompt(userInput string) string {
-	return userInput
-}
-
-// GetSystemPrompt returns the system prompt
-func GetSystemPrompt() string {
-	return SystemPrompt
-}
-
-// GetSystemPromptForLanguage returns system prompt for a specific language
-func GetSystemPromptForLanguage(lang string) string {
-	// For now, only Spanish is supported
-	// Could be extended with translations
-	switch strings.ToLower(lang) {
-	case "en":
-		return SystemPromptEN
-	default:
-		return SystemPrompt
-	}
-}
+// systemPromptExamples are the interpretation examples of the Spanish
+// prompt that don't depend on any executor; the available actions add theirs
+const systemPromptExamples = `- "cuánto es dos más dos" → calc {"expression":"2 + 2"} + reply: "Calculando"
+- "cambia eso" (falta saber qué) → none + reply: "¿Qué quieres que cambie?"
+- "eres Jarvis?" → none + reply: "Claro, soy Jarvis, tu asistente. ¿En qué te ayudo?"
+- "hola Jarvis" → none + reply: "Hola! ¿Qué necesitas?"
+- "buenas" → none + reply: "Qué onda, ¿lista para el stream?"`
 
-// SystemPromptEN is the English version of the system prompt
-const SystemPromptEN = `You are Jarvis, an intelligent voice assistant for streamers. Your job is to interpret voice commands and convert them into structured actions.
+// systemPromptIntroEN is the persona and response format of the English prompt
+const systemPromptIntroEN = `You are Jarvis, an intelligent voice assistant for streamers. Your job is to interpret voice commands and convert them into structured actions.
 
 IMPORTANT: You must respond ONLY with a valid JSON object. Do not include any additional text, explanation, or markdown.
 
 The response format MUST be exactly:
-{"action": "name.action", "params": {...}, "reply": "message for the user"}
+{"action": "name.action", "params": {...}, "reply": "message for the user"}`
+
+// systemPromptBuiltinsEN describes the actions handled by Brain itself
+const systemPromptBuiltinsEN = `== CALCULATOR ==
+- calc: Perform math calculations (Jarvis computes and speaks the result, don't compute it yourself)
+  params: {expression: "math expression"}
+  example: {"action": "calc", "params": {"expression": "2 + 2"}, "reply": "Calculating"}
+  supports: addition (+), subtraction (-), multiplication (*), division (/), powers (^), percentages (%), parentheses, sqrt() and round()
+
+== SYSTEM ==
+- system.status: System status
+  params: {}
+
+- system.help: Show help
+  params: {}
+
+- system.undo: Undo the last command
+  params: {}
+
+- none: When there's no specific action or it's just conversation
+  params: {}
+
+== MULTIPLE ACTIONS ==
+- plan: When the user asks for several things in one sentence, return the ordered list in "steps"
+  on_error: "stop" (default, abort the rest if a step fails) or "continue" (run every step anyway)
+  each step uses the same {"action", "params", "reply"} format`
+
+// systemPromptRulesEN holds the rules of the English prompt
+const systemPromptRulesEN = `RULES:
+1. ALWAYS respond with valid JSON
+2. The "reply" field should be a natural, friendly response
+3. If you don't understand the command, use action "none" and ask for clarification
+4. Interpret synonyms and natural language variations
+5. Preserve usernames, scene names, and source names exactly as mentioned
+6. If the user asks for something impossible, use action "none" and explain why
+7. Use the previous messages of the conversation to fill in missing details and resolve references ("him", "that user", "the same scene")`
+
+// PromptParam describes an action parameter in the system prompt
+type PromptParam struct {
+	Name        string
+	Type        string // "string", "number", "integer", "boolean" or "object"
+	Description string
+	Required    bool
+	Min         *float64
+	Max         *float64
+	Enum        []string
+	Default     interface{}
+}
 
-AVAILABLE ACTIONS:
+// PromptExample shows the model how a phrase maps to an action
+type PromptExample struct {
+	Phrase string // What the user says, in Spanish
+	Params map[string]interface{}
+	Reply  string
+}
 
-== TWITCH ==
-- twitch.clip: Create a clip of the stream
-  params: {duration: number (seconds, optional, default 30)}
+// PromptAction describes an action in the system prompt
+type PromptAction struct {
+	Name        string
+	Description string
+	Params      []PromptParam
+	Examples    []PromptExample
+}
 
-- twitch.title: Change the stream title
-  params: {title: "new title"}
+// ContextItem is a piece of live state shown to the model, e.g. the current OBS scene
+type ContextItem struct {
+	Label string
+	Value string
+}
 
-- twitch.category: Change the stream category
-  params: {category: "category name"}
+// promptWords holds the words used to render the generated prompt sections
+type promptWords struct {
+	catalog, context, contextHint  string
+	required, optional, defaultVal string
+	from, to, oneOf                string
+	example, planReply             string
+	examples                       string // Header of the interpretation examples; empty to leave them out
+	types                          map[string]string
+	groups                         map[string]string
+}
 
-- twitch.ban: Ban a user
-  params: {user: "username", reason: "reason" (optional)}
+var promptWordsES = promptWords{
+	catalog:     "ACCIONES DISPONIBLES:",
+	context:     "CONTEXTO ACTUAL:",
+	contextHint: "Usa exactamente estos nombres; no inventes escenas, fuentes ni canciones.",
+	required:    "obligatorio",
+	optional:    "opcional",
+	defaultVal:  "por defecto",
+	from:        "de",
+	to:          "a",
+	oneOf:       "uno de",
+	example:     "ejemplo",
+	planReply:   "Listo, todo hecho",
+	examples:    "EJEMPLOS DE INTERPRETACIÓN:",
+	types:       map[string]string{"string": "texto", "number": "número", "integer": "entero", "boolean": "true/false", "object": "objeto"},
+	groups:      map[string]string{"twitch": "TWITCH", "obs": "OBS", "music": "MÚSICA", "schedule": "PROGRAMAR", "macro": "RUTINAS"},
+}
 
-- twitch.timeout: Timeout a user
-  params: {user: "username", duration: number (seconds)}
+var promptWordsEN = promptWords{
+	catalog:     "AVAILABLE ACTIONS:",
+	context:     "CURRENT CONTEXT:",
+	contextHint: "Use these exact names; don't make up scenes, sources or songs.",
+	required:    "required",
+	optional:    "optional",
+	defaultVal:  "default",
+	from:        "from",
+	to:          "to",
+	oneOf:       "one of",
+	example:     "example",
+	planReply:   "Done",
+	types:       map[string]string{"string": "text", "number": "number", "integer": "integer", "boolean": "true/false", "object": "object"},
+	groups:      map[string]string{"twitch": "TWITCH", "obs": "OBS", "music": "MUSIC", "schedule": "SCHEDULE", "macro": "ROUTINES"},
+}
 
-- twitch.unban: Unban a user
-  params: {user: "username"}
+// BuildSystemPrompt builds the system prompt for a language from the
+// actions that are currently available and the live state of the stream
+func BuildSystemPrompt(lang string, actions []PromptAction, live []ContextItem) string {
+	intro, builtins, rules, words := systemPromptIntro, systemPromptBuiltins, systemPromptRules, promptWordsES
+	if strings.EqualFold(lang, "en") {
+		intro, builtins, rules, words = systemPromptIntroEN, systemPromptBuiltinsEN, systemPromptRulesEN, promptWordsEN
+	}
 
-== OBS ==
-- obs.scene: Switch to a scene
-  params: {scene: "scene name"}
+	var b strings.Builder
+	b.WriteString(intro)
+	b.WriteString("\n\n")
+	b.WriteString(words.catalog)
+	b.WriteString("\n\n")
+	writeActionCatalog(&b, actions, words)
+	b.WriteString(builtins)
+	b.WriteString("\n")
+	steps := planExample(actions)
+	if len(steps) > 0 {
+		fmt.Fprintf(&b, "  %s: %s\n", words.example, planJSON(steps, words.planReply))
+	}
+	b.WriteString("\n")
 
-- obs.source.show: Show a source
-  params: {source: "source name"}
+	if len(live) > 0 {
+		b.WriteString(words.context)
+		b.WriteString("\n")
+		for _, item := range live {
+			fmt.Fprintf(&b, "- %s: %s\n", item.Label, item.Value)
+		}
+		b.WriteString(words.contextHint)
+		b.WriteString("\n\n")
+	}
 
-- obs.source.hide: Hide a source
-  params: {source: "source name"}
+	b.WriteString(rules)
 
-- obs.volume: Change source volume
-  params: {source: "name", volume: number (0.0 to 1.0)}
+	if words.examples != "" {
+		b.WriteString("\n\n")
+		b.WriteString(words.examples)
+		b.WriteString("\n")
+		writeExamples(&b, actions, steps, words)
+	}
+	return b.String()
+}
 
-- obs.mute: Mute a source
-  params: {source: "source name"}
+// writeExamples renders the interpretation examples: those of the available
+// actions, so no disabled action is ever suggested, and the built-in ones
+func writeExamples(b *strings.Builder, actions []PromptAction, plan []exampleStep, words promptWords) {
+	for _, action := range actions {
+		for _, ex := range action.Examples {
+			fmt.Fprintf(b, "- %q → %s", ex.Phrase, action.Name)
+			if len(ex.Params) > 0 {
+				fmt.Fprintf(b, " %s", compactJSON(ex.Params))
+			}
+			fmt.Fprintf(b, " + reply: %q\n", ex.Reply)
+		}
+	}
 
-- obs.unmute: Unmute a source
-  params: {source: "source name"}
+	if len(plan) > 0 {
+		phrases := make([]string, len(plan))
+		names := make([]string, len(plan))
+		for i, step := range plan {
+			phrases[i] = step.Phrase
+			names[i] = step.action
+		}
+		fmt.Fprintf(b, "- %q → plan [%s] + reply: %q\n", strings.Join(phrases, " y "), strings.Join(names, ", "), words.planReply)
+	}
 
-- obs.text: Change text source content
-  params: {source: "name", text: "new text"}
+	b.WriteString(systemPromptExamples)
+}
 
-== MUSIC ==
-- music.play: Play music
-  params: {query: "search" (optional)}
+// exampleStep is an action example used as a step of the plan example
+type exampleStep struct {
+	action string
+	PromptExample
+}
 
-- music.pause: Pause music
-  params: {}
+// planExample picks the first example of the first two available groups of
+// actions ("obs", "music"...) to show a plan with; it is empty if fewer
+// than two groups have examples
+func planExample(actions []PromptAction) []exampleStep {
+	var steps []exampleStep
+	groups := make(map[string]bool)
+	for _, action := range actions {
+		group, _, _ := strings.Cut(action.Name, ".")
+		if len(action.Examples) == 0 || groups[group] {
+			continue
+		}
+		groups[group] = true
+		steps = append(steps, exampleStep{action: action.Name, PromptExample: action.Examples[0]})
+		if len(steps) == 2 {
+			return steps
+		}
+	}
+	return nil
+}
 
-- music.resume: Resume music
-  params: {}
+// planJSON renders the plan example as the model should answer it
+func planJSON(steps []exampleStep, reply string) string {
+	parts := make([]string, len(steps))
+	for i, step := range steps {
+		params := step.Params
+		if params == nil {
+			params = map[string]interface{}{}
+		}
+		parts[i] = fmt.Sprintf(`{"action": %q, "params": %s, "reply": %q}`, step.action, compactJSON(params), step.Reply)
+	}
+	return fmt.Sprintf(`{"action": "plan", "steps": [%s], "on_error": "continue", "reply": %q}`, strings.Join(parts, ", "), reply)
+}
 
-- music.next: Next song
-  params: {}
+// compactJSON renders example params on one line
+func compactJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
 
-- music.previous: Previous song
-  params: {}
+// writeActionCatalog renders the actions grouped by their prefix ("obs", "twitch"...)
+func writeActionCatalog(b *strings.Builder, actions []PromptAction, words promptWords) {
+	sorted := make([]PromptAction, len(actions))
+	copy(sorted, actions)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	group := ""
+	for _, action := range sorted {
+		prefix, _, _ := strings.Cut(action.Name, ".")
+		if prefix != group {
+			group = prefix
+			title, ok := words.groups[prefix]
+			if !ok {
+				title = strings.ToUpper(prefix)
+			}
+			fmt.Fprintf(b, "== %s ==\n", title)
+		}
 
-- music.volume: Change music volume
-  params: {volume: number (0.0 to 1.0)}
+		fmt.Fprintf(b, "- %s: %s\n", action.Name, action.Description)
+		params := make([]string, 0, len(action.Params))
+		for _, p := range action.Params {
+			params = append(params, describeParam(p, words))
+		}
+		fmt.Fprintf(b, "  params: {%s}\n\n", strings.Join(params, ", "))
+	}
+}
 
-- music.stop: Stop music
-  params: {}
+// describeParam renders a parameter as `name: type (required, 0 to 1) - description`
+func describeParam(p PromptParam, words promptWords) string {
+	typ, ok := words.types[p.Type]
+	if !ok {
+		typ = p.Type
+	}
 
-== SYSTEM ==
-- system.status: System status
-  params: {}
+	notes := []string{words.optional}
+	if p.Required {
+		notes[0] = words.required
+	}
+	if p.Min != nil && p.Max != nil {
+		notes = append(notes, fmt.Sprintf("%s %g %s %g", words.from, *p.Min, words.to, *p.Max))
+	}
+	if len(p.Enum) > 0 {
+		notes = append(notes, fmt.Sprintf("%s: %s", words.oneOf, strings.Join(p.Enum, ", ")))
+	}
+	if p.Default != nil {
+		notes = append(notes, fmt.Sprintf("%s %v", words.defaultVal, p.Default))
+	}
 
-- system.help: Show help
-  params: {}
+	desc := fmt.Sprintf("%s: %s (%s)", p.Name, typ, strings.Join(notes, ", "))
+	if p.Description != "" {
+		desc += " - " + p.Description
+	}
+	return desc
+}
 
-- none: When there's no specific action or it's just conversation
-  params: {}
+// BuildPrompt builds the full prompt with the user's input
+func BuildPrompt(userInput string) string {
+	return userInput
+}
 
-RULES:
-1. ALWAYS respond with valid JSON
-2. The "reply" field should be a natural, friendly response
-3. If you don't understand the command, use action "none" and ask for clarification
-4. Interpret synonyms and natural language variations
-5. Preserve usernames, scene names, and source names exactly as mentioned
-6. If the user asks for something impossible, use action "none" and explain why`
+// GetSystemPrompt returns the system prompt with only the built-in actions;
+// Brain normally sends a prompt built from the registered executors instead
+func GetSystemPrompt() string {
+	return BuildSystemPrompt("es", nil, nil)
+}
+
+// GetSystemPromptForLanguage returns the built-in system prompt for a specific language
+func GetSystemPromptForLanguage(lang string) string {
+	return BuildSystemPrompt(lang, nil, nil)
+}
@@ -0,0 +1,74 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+)
+
+var (
+	clipAction = PromptAction{
+		Name:        "twitch.clip",
+		Description: "Crear un clip",
+		Examples:    []PromptExample{{Phrase: "hazme un clip", Reply: "Dale, creando clip"}},
+	}
+	titleAction = PromptAction{
+		Name:        "twitch.title",
+		Description: "Cambiar el título del stream",
+		Params:      []PromptParam{{Name: "title", Type: "string", Required: true}},
+		Examples:    []PromptExample{{Phrase: "cambia el título a vuelvo en 5", Params: map[string]interface{}{"title": "Vuelvo en 5"}, Reply: "Título cambiado"}},
+	}
+	nextAction = PromptAction{
+		Name:        "music.next",
+		Description: "Pasar a la siguiente canción",
+		Examples:    []PromptExample{{Phrase: "siguiente", Reply: "Vamos con la siguiente"}},
+	}
+)
+
+func TestSystemPromptOnlyShowsAvailableActions(t *testing.T) {
+	for _, lang := range []string{"es", "en"} {
+		prompt := BuildSystemPrompt(lang, nil, nil)
+		for _, action := range []string{"obs.", "twitch.", "music.", "schedule."} {
+			if strings.Contains(prompt, action) {
+				t.Errorf("%s prompt without executors mentions %s", lang, action)
+			}
+		}
+		if strings.Contains(prompt, "Twitch") || strings.Contains(prompt, "OBS") {
+			t.Errorf("%s prompt without executors advertises Twitch or OBS", lang)
+		}
+		// Jarvis computes the result; the example must not do it
+		if strings.Contains(prompt, "2 + 2 = 4") {
+			t.Errorf("%s prompt example computes the calc result", lang)
+		}
+	}
+}
+
+func TestSystemPromptExamples(t *testing.T) {
+	prompt := BuildSystemPrompt("es", []PromptAction{clipAction, titleAction, nextAction}, nil)
+
+	for _, want := range []string{
+		`- "hazme un clip" → twitch.clip + reply: "Dale, creando clip"`,
+		`- "cambia el título a vuelvo en 5" → twitch.title {"title":"Vuelvo en 5"} + reply: "Título cambiado"`,
+		// The plan takes its steps from two different groups
+		`- "hazme un clip y siguiente" → plan [twitch.clip, music.next]`,
+		`{"action": "plan", "steps": [{"action": "twitch.clip", "params": {}, "reply": "Dale, creando clip"}, {"action": "music.next", "params": {}, "reply": "Vamos con la siguiente"}]`,
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt is missing %s", want)
+		}
+	}
+}
+
+func TestSystemPromptPlanExampleNeedsTwoGroups(t *testing.T) {
+	prompt := BuildSystemPrompt("en", []PromptAction{clipAction, titleAction}, nil)
+	if strings.Contains(prompt, `"action": "plan", "steps"`) {
+		t.Error("plan example built from a single group")
+	}
+
+	prompt = BuildSystemPrompt("en", []PromptAction{clipAction, nextAction}, nil)
+	if !strings.Contains(prompt, `"action": "plan", "steps": [{"action": "twitch.clip"`) {
+		t.Error("English prompt has no plan example")
+	}
+	if strings.Contains(prompt, "hazme un clip") {
+		t.Error("English prompt lists the Spanish interpretation examples")
+	}
+}
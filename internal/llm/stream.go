@@ -0,0 +1,190 @@
+package llm
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// StreamingProvider is implemented by providers that can stream their
+// completion. onReply receives the "reply" of a plain conversational answer
+// (action "none") piece by piece while the model is still writing it, so
+// speech can start before the JSON is complete; replies of real actions are
+// never streamed, since they would be spoken before the action runs.
+type StreamingProvider interface {
+	CompleteChatStream(ctx context.Context, messages []Message, onReply func(delta string)) (Action, error)
+}
+
+// replyStream turns the growing raw completion into reply deltas
+type replyStream struct {
+	raw     strings.Builder
+	sent    int // Bytes of the reply already passed to onReply
+	onReply func(delta string)
+}
+
+// newReplyStream creates a replyStream; a nil onReply discards the deltas
+func newReplyStream(onReply func(delta string)) *replyStream {
+	return &replyStream{onReply: onReply}
+}
+
+// write appends a chunk of the completion and emits any new reply text
+func (s *replyStream) write(chunk string) {
+	s.raw.WriteString(chunk)
+	if s.onReply == nil {
+		return
+	}
+
+	fields := partialFields(s.raw.String())
+	if fields["action"] != "none" {
+		return
+	}
+	if reply := fields["reply"]; len(reply) > s.sent {
+		s.onReply(reply[s.sent:])
+		s.sent = len(reply)
+	}
+}
+
+// String returns the completion received so far
+func (s *replyStream) String() string {
+	return s.raw.String()
+}
+
+// partialFields decodes the top-level string fields of a JSON object that
+// may still be incomplete. The last value can be cut short; it is decoded
+// up to the last complete character. Nested objects are skipped.
+func partialFields(raw string) map[string]string {
+	fields := make(map[string]string)
+
+	start := strings.IndexByte(raw, '{')
+	if start < 0 {
+		return fields
+	}
+
+	depth := 0
+	key := ""
+	expectKey := false
+	for i := start; i < len(raw); i++ {
+		switch c := raw[i]; c {
+		case '{', '[':
+			depth++
+			expectKey = depth == 1 && c == '{'
+		case '}', ']':
+			depth--
+			if depth <= 0 {
+				return fields
+			}
+		case ',':
+			if depth == 1 {
+				expectKey = true
+				key = ""
+			}
+		case '"':
+			value, end, complete := decodeString(raw, i+1)
+			switch {
+			case depth != 1:
+			case expectKey:
+				key = value
+				expectKey = false
+			case key != "":
+				fields[key] = value
+			}
+			if !complete {
+				return fields
+			}
+			i = end
+		}
+	}
+	return fields
+}
+
+// decodeString decodes a JSON string starting right after its opening
+// quote. It returns the text, the index of the closing quote and whether
+// the string was complete.
+func decodeString(raw string, i int) (string, int, bool) {
+	var b strings.Builder
+	for i < len(raw) {
+		c := raw[i]
+		switch {
+		case c == '"':
+			return b.String(), i, true
+		case c != '\\':
+			b.WriteByte(c)
+			i++
+			continue
+		}
+
+		// Escape sequence; stop if it is still arriving
+		if i+1 >= len(raw) {
+			break
+		}
+		switch esc := raw[i+1]; esc {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case 'b', 'f':
+		case 'u':
+			r, size, ok := decodeUnicode(raw[i:])
+			if !ok {
+				return trimPartialRune(b.String()), i, false
+			}
+			b.WriteRune(r)
+			i += size
+			continue
+		default:
+			b.WriteByte(esc) // \" \\ \/
+		}
+		i += 2
+	}
+	return trimPartialRune(b.String()), i, false
+}
+
+// decodeUnicode decodes a \uXXXX escape, joining surrogate pairs
+func decodeUnicode(s string) (rune, int, bool) {
+	if len(s) < 6 {
+		return 0, 0, false
+	}
+	code, err := strconv.ParseUint(s[2:6], 16, 16)
+	if err != nil {
+		return utf16.DecodeRune(0, 0), 6, true
+	}
+	r := rune(code)
+	if !utf16.IsSurrogate(r) {
+		return r, 6, true
+	}
+	if len(s) < 12 {
+		return 0, 0, false
+	}
+	low, err := strconv.ParseUint(s[8:12], 16, 16)
+	if err != nil || s[6:8] != `\u` {
+		return utf16.DecodeRune(0, 0), 6, true
+	}
+	return utf16.DecodeRune(r, rune(low)), 12, true
+}
+
+// trimPartialRune drops a multi-byte character cut at the end of a chunk
+func trimPartialRune(s string) string {
+	for i := len(s) - 1; i >= 0 && i >= len(s)-3; i-- {
+		c := s[i]
+		if c < 0x80 {
+			return s
+		}
+		if c >= 0xC0 {
+			// Lead byte: keep it only if its sequence is complete
+			size := 2
+			if c >= 0xF0 {
+				size = 4
+			} else if c >= 0xE0 {
+				size = 3
+			}
+			if len(s)-i < size {
+				return s[:i]
+			}
+			return s
+		}
+	}
+	return s
+}
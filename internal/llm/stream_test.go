@@ -0,0 +1,215 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/jarvisstreamer/jarvis/internal/config"
+)
+
+func TestDecodeString(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string // After the opening quote
+		want     string
+		complete bool
+	}{
+		{"plain", `hola" resto`, "hola", true},
+		{"escapes", `di \"hola\"\n\ttab \\ \/ fin"`, "di \"hola\"\n\ttab \\ / fin", true},
+		{"unicode", `caf\u00e9"`, "café", true},
+		{"surrogate pair", `risa \ud83d\ude00"`, "risa 😀", true},
+		{"invalid escape digits", `a\u00zzb"`, "a\uFFFDb", true},
+		{"cut", `hola`, "hola", false},
+		{"cut in escape", `hola\`, "hola", false},
+		{"cut in unicode escape", `caf\u00`, "caf", false},
+		{"cut between surrogates", `risa \ud83d`, "risa ", false},
+		{"cut in low surrogate", `risa \ud83d\ude`, "risa ", false},
+		{"cut in multi-byte rune", "caf\xc3", "caf", false},
+	}
+	for _, tt := range tests {
+		got, end, complete := decodeString(tt.raw, 0)
+		if got != tt.want || complete != tt.complete {
+			t.Errorf("%s: decodeString(%q) = %q, %v; want %q, %v", tt.name, tt.raw, got, complete, tt.want, tt.complete)
+		}
+		if complete && tt.raw[end] != '"' {
+			t.Errorf("%s: end %d is not the closing quote", tt.name, end)
+		}
+	}
+}
+
+func TestDecodeUnicode(t *testing.T) {
+	tests := []struct {
+		s    string
+		want rune
+		size int
+		ok   bool
+	}{
+		{`\u0041 resto`, 'A', 6, true},
+		{`\u00f1`, 'ñ', 6, true},
+		{`\ud83d\ude00`, '😀', 12, true},
+		{`\u00`, 0, 0, false},
+		{`\ud83d`, 0, 0, false},
+		{`\ud83d\ude0`, 0, 0, false},
+		{`\ud83dabcdef`, utf8.RuneError, 6, true}, // Lone high surrogate
+		{`\uzzzz`, utf8.RuneError, 6, true},
+	}
+	for _, tt := range tests {
+		r, size, ok := decodeUnicode(tt.s)
+		if r != tt.want || size != tt.size || ok != tt.ok {
+			t.Errorf("decodeUnicode(%q) = %q, %d, %v; want %q, %d, %v", tt.s, r, size, ok, tt.want, tt.size, tt.ok)
+		}
+	}
+}
+
+func TestTrimPartialRune(t *testing.T) {
+	tests := []struct{ s, want string }{
+		{"", ""},
+		{"hola", "hola"},
+		{"café", "café"},
+		{"caf\xc3", "caf"},
+		{"€", "€"},
+		{"a\xe2\x82", "a"},
+		{"😀", "😀"},
+		{"a" + "😀"[:3], "a"},
+		{"a" + "😀"[:1], "a"},
+	}
+	for _, tt := range tests {
+		if got := trimPartialRune(tt.s); got != tt.want {
+			t.Errorf("trimPartialRune(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestPartialFields(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want map[string]string
+	}{
+		{"complete", `{"action": "none", "reply": "Hola"}`, map[string]string{"action": "none", "reply": "Hola"}},
+		{"cut value", `{"action": "none", "reply": "Hol`, map[string]string{"action": "none", "reply": "Hol"}},
+		{"cut key", `{"action": "none", "rep`, map[string]string{"action": "none"}},
+		{"text before the object", `Claro: {"reply": "a"}`, map[string]string{"reply": "a"}},
+		{"escaped quotes", `{"reply": "di \"hola\", vale", "action": "none"}`, map[string]string{"reply": `di "hola", vale`, "action": "none"}},
+		{"nested params", `{"action": "none", "params": {"reply": "x"}, "reply": "y"}`, map[string]string{"action": "none", "reply": "y"}},
+		{"nested steps", `{"action": "plan", "steps": [{"action": "obs.scene", "reply": "escena"}], "reply": "Listo"}`, map[string]string{"action": "plan", "reply": "Listo"}},
+		{"cut in a step", `{"action": "plan", "steps": [{"action": "obs.scene", "reply": "esc`, map[string]string{"action": "plan"}},
+		{"no object", `hola`, map[string]string{}},
+	}
+	for _, tt := range tests {
+		if got := partialFields(tt.raw); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: partialFields(%q) = %v, want %v", tt.name, tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestReplyStream(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		want   string
+	}{
+		{"action first", []string{`{"action": "none", "reply": "Ho`, `la, ¿qué tal?"}`}, "Hola, ¿qué tal?"},
+		{"reply before action", []string{`{"reply": "Ho`, `la", "action"`, `: "none"}`}, "Hola"},
+		{"escape split across chunks", []string{`{"action": "none", "reply": "di \`, `"hola\" \`, `n"}`}, "di \"hola\" \n"},
+		{"surrogate pair split across chunks", []string{`{"action": "none", "reply": "risa \ud83d`, `\ude00 y caf\u00`, `e9"}`}, "risa 😀 y café"},
+		{"rune cut mid-chunk", []string{`{"action": "none", "reply": "caf` + "\xc3", "\xa9 con leche\"}"}, "café con leche"},
+		{"real action", []string{`{"action": "obs.scene", "reply": "Cambiando"}`}, ""},
+		{"reply before a real action", []string{`{"reply": "Cambiando", `, `"action": "obs.scene"}`}, ""},
+		{"nested step replies", []string{`{"action": "none", "steps": [{"reply": "paso"}], `, `"reply": "Hola"}`}, "Hola"},
+		{"plan", []string{`{"action": "plan", "steps": [{"action": "none", "reply": "paso"}], "reply": "Listo"}`}, ""},
+	}
+	for _, tt := range tests {
+		var got strings.Builder
+		stream := newReplyStream(func(delta string) {
+			if !utf8.ValidString(delta) {
+				t.Errorf("%s: delta %q is not valid UTF-8", tt.name, delta)
+			}
+			got.WriteString(delta)
+		})
+		for _, chunk := range tt.chunks {
+			stream.write(chunk)
+		}
+		if got.String() != tt.want {
+			t.Errorf("%s: streamed %q, want %q", tt.name, got.String(), tt.want)
+		}
+		if stream.String() != strings.Join(tt.chunks, "") {
+			t.Errorf("%s: raw completion = %q", tt.name, stream.String())
+		}
+	}
+}
+
+func TestReplyStreamByteByByte(t *testing.T) {
+	raw := `{"action": "none", "reply": "A\u00f1o \"nuevo\" \ud83d\ude00 \u00e9😀\n"}`
+	var got strings.Builder
+	stream := newReplyStream(func(delta string) {
+		if !utf8.ValidString(delta) {
+			t.Errorf("delta %q is not valid UTF-8", delta)
+		}
+		got.WriteString(delta)
+	})
+	for i := 0; i < len(raw); i++ {
+		stream.write(raw[i : i+1])
+	}
+
+	var want struct{ Reply string }
+	if err := json.Unmarshal([]byte(raw), &want); err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != want.Reply {
+		t.Errorf("streamed %q, want %q", got.String(), want.Reply)
+	}
+}
+
+func TestOllamaStreamsOnlyWithCallback(t *testing.T) {
+	var streamed []bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OllamaChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("bad request: %v", err)
+		}
+		streamed = append(streamed, req.Stream)
+
+		enc := json.NewEncoder(w)
+		if !req.Stream {
+			_ = enc.Encode(OllamaChatResponse{Message: Message{Content: `{"action": "none", "reply": "Hola"}`}, Done: true})
+			return
+		}
+		for _, chunk := range []string{`{"action": "none", `, `"reply": "Ho`, `la"}`} {
+			_ = enc.Encode(OllamaChatResponse{Message: Message{Content: chunk}})
+		}
+		_ = enc.Encode(OllamaChatResponse{Done: true})
+	}))
+	defer server.Close()
+
+	p, err := NewOllamaProvider(config.OllamaConfig{URL: server.URL, Model: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	messages := []Message{{Role: RoleUser, Content: "hola"}}
+
+	action, err := p.CompleteChat(ctx, messages)
+	if err != nil || action.Reply != "Hola" {
+		t.Fatalf("CompleteChat = %+v, %v", action, err)
+	}
+
+	var deltas []string
+	action, err = p.CompleteChatStream(ctx, messages, func(delta string) { deltas = append(deltas, delta) })
+	if err != nil || action.Reply != "Hola" {
+		t.Fatalf("CompleteChatStream = %+v, %v", action, err)
+	}
+	if strings.Join(deltas, "") != "Hola" {
+		t.Errorf("deltas = %q, want Hola", deltas)
+	}
+
+	if !reflect.DeepEqual(streamed, []bool{false, true}) {
+		t.Errorf("stream requested = %v, want only with a callback", streamed)
+	}
+}
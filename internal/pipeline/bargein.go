@@ -0,0 +1,118 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+
+	"github.com/jarvisstreamer/jarvis/internal/config"
+	"github.com/jarvisstreamer/jarvis/internal/events"
+	"github.com/jarvisstreamer/jarvis/pkg/utils"
+)
+
+const (
+	// bargeInWarmup is how long the detector only learns how loud Jarvis's
+	// own voice is in the mic, before it may fire
+	bargeInWarmup = 300 * time.Millisecond
+
+	// bargeInGap is the pause between playbacks after which the detector
+	// starts over; the pause between streamed sentences is shorter
+	bargeInGap = time.Second
+)
+
+// bargeInDetector tells the user talking over Jarvis apart from Jarvis's
+// own voice leaking from the speakers into the mic
+type bargeInDetector struct {
+	threshold float64 // Minimum RMS of the user's voice
+	ratio     float64 // How much louder than the echo the user must be
+	minSpeech time.Duration
+
+	lastFeed time.Time
+	heard    time.Duration // Playback audio analyzed since the detector started over
+	echo     float64       // Running RMS of the playback as heard by the mic
+	speech   time.Duration // Continuous speech over the echo
+}
+
+// newBargeInDetector creates a detector from configuration; a higher
+// sensitivity lowers both the absolute and the over-the-echo thresholds
+func newBargeInDetector(cfg config.BargeInConfig) *bargeInDetector {
+	return &bargeInDetector{
+		threshold: 500 * (1 - cfg.Sensitivity), // Same scale as the energy VAD threshold
+		ratio:     1.5 + 3*(1-cfg.Sensitivity),
+		minSpeech: time.Duration(cfg.MinSpeechMs) * time.Millisecond,
+	}
+}
+
+// feed analyzes a chunk recorded during playback and returns true once
+// the user has been talking over Jarvis for long enough
+func (d *bargeInDetector) feed(samples []int16, chunk time.Duration, now time.Time) bool {
+	if now.Sub(d.lastFeed) > bargeInGap {
+		d.heard, d.echo, d.speech = 0, 0, 0
+	}
+	d.lastFeed = now
+	d.heard += chunk
+
+	rms := utils.CalculateRMS(samples)
+	if d.heard <= bargeInWarmup {
+		d.echo = 0.7*d.echo + 0.3*rms
+		return false
+	}
+
+	if rms <= d.threshold || rms <= d.echo*d.ratio {
+		d.speech = 0
+		d.echo = 0.95*d.echo + 0.05*rms
+		return false
+	}
+
+	d.speech += chunk
+	return d.speech >= d.minSpeech
+}
+
+// onSpeech follows TTS playback, whoever started it (replies, reminders),
+// so the pipeline shows it is speaking and listens for barge-in meanwhile
+func (p *Pipeline) onSpeech(env events.Envelope) {
+	switch env.Event.(type) {
+	case events.TTSStarted:
+		p.stateMu.Lock()
+		switch p.state {
+		case StateIdle, StateProcessing, StateConfirming:
+			p.afterSpeech = p.state
+			p.stateMu.Unlock()
+			p.setState(StateSpeaking)
+			return
+		}
+		p.stateMu.Unlock()
+
+	case events.TTSStopped:
+		p.stateMu.Lock()
+		speaking := p.state == StateSpeaking
+		after := p.afterSpeech
+		p.stateMu.Unlock()
+		if speaking {
+			p.setState(after)
+		}
+	}
+}
+
+// checkBargeIn interrupts Jarvis and starts recording when the user talks
+// over its speech, and reports whether it did. The recording ends on
+// silence, so it needs the VAD.
+func (p *Pipeline) checkBargeIn(ctx context.Context, audio []byte) bool {
+	if !p.cfg.Audio.BargeIn.Enabled || !p.cfg.Audio.VAD.Enabled {
+		return false
+	}
+
+	samples := utils.BytesToInt16(audio)
+	chunk := time.Duration(len(samples)) * time.Second / time.Duration(p.cfg.Audio.SampleRate*p.cfg.Audio.Channels)
+	if !p.bargeIn.feed(samples, chunk, time.Now()) {
+		return false
+	}
+
+	p.log.Info().Msg("User talked over the reply, interrupting")
+	p.brain.StopSpeaking()
+
+	p.setState(StateListening)
+	p.startRecording()
+	p.record(audio, true) // The detector just heard the user talking
+	p.recordInBackground(ctx)
+	return true
+}
@@ -4,14 +4,17 @@ package pipeline
 import (
 	"bytes"
 	"context"
-	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jarvisstreamer/jarvis/internal/brain"
 	"github.com/jarvisstreamer/jarvis/internal/config"
+	"github.com/jarvisstreamer/jarvis/internal/events"
 	"github.com/jarvisstreamer/jarvis/internal/llm"
 	"github.com/jarvisstreamer/jarvis/internal/stt"
+	"github.com/jarvisstreamer/jarvis/internal/vad"
+	"github.com/jarvisstreamer/jarvis/internal/wakeword"
 	"github.com/jarvisstreamer/jarvis/pkg/logger"
 	"github.com/jarvisstreamer/jarvis/pkg/utils"
 	"github.com/rs/zerolog"
@@ -25,6 +28,8 @@ const (
 	StateListening
 	StateRecording
 	StateProcessing
+	StateConfirming
+	StateSpeaking
 )
 
 func (s State) String() string {
@@ -37,6 +42,10 @@ func (s State) String() string {
 		return "recording"
 	case StateProcessing:
 		return "processing"
+	case StateConfirming:
+		return "confirming"
+	case StateSpeaking:
+		return "speaking"
 	default:
 		return "unknown"
 	}
@@ -49,13 +58,27 @@ type Pipeline struct {
 	brain       *brain.Brain
 	log         zerolog.Logger
 
-	state   State
-	stateMu sync.RWMutex
+	state       State
+	afterSpeech State // State to go back to when playback ends
+	stateMu     sync.RWMutex
 
 	// Audio buffer for recording
 	audioBuffer *bytes.Buffer
+	speechEnd   int         // Length of the recording at the end of its last speech
+	preRoll     *ringBuffer // Latest audio while not recording, to start recordings with
 	bufferMu    sync.Mutex
 
+	// Speech timing of the current recording, guarded by bufferMu: the
+	// loop writes it and the recording goroutines read it
+	silenceStart time.Time
+	speechStart  time.Time
+	hasSpeech    bool
+
+	// Ownership of the current recording: one that ends on silence is
+	// processed by its own goroutine, so releasing the hotkey leaves it alone
+	recordingID   atomic.Uint64 // Incremented by each recording
+	endsOnSilence atomic.Bool
+
 	// Channels for events
 	wakeWordChan chan struct{}
 	hotkeyDown   chan struct{}
@@ -64,45 +87,55 @@ type Pipeline struct {
 	stopChan     chan struct{}
 
 	// VAD settings
-	silenceStart time.Time
-	speechStart  time.Time
-	hasSpeech    bool
+	vad vad.Detector
+
+	bargeIn     *bargeInDetector
+	unsubscribe func()
 
-	// Callbacks
-	onStateChange func(State)
-	onTranscript  func(string)
-	onResponse    func(string)
-	onError       func(error)
+	// Wake word spotting; without a detector any voice starts a recording
+	wakeWord      *wakeword.Detector
+	wakeTriggered atomic.Bool // The current recording follows the wake word
 }
 
 // NewPipeline creates a new processing pipeline
 func NewPipeline(cfg *config.Config, sttProvider stt.Provider, brn *brain.Brain) *Pipeline {
-	return &Pipeline{
+	p := &Pipeline{
 		cfg:          cfg,
 		sttProvider:  sttProvider,
 		brain:        brn,
 		log:          logger.Component("pipeline"),
 		state:        StateIdle,
 		audioBuffer:  bytes.NewBuffer(nil),
+		preRoll:      newRingBuffer(time.Duration(cfg.Audio.PreRollMs)*time.Millisecond, cfg.Audio.SampleRate, cfg.Audio.Channels),
 		wakeWordChan: make(chan struct{}, 1),
 		hotkeyDown:   make(chan struct{}, 1),
 		hotkeyUp:     make(chan struct{}, 1),
 		audioChan:    make(chan []byte, 100),
 		stopChan:     make(chan struct{}),
+		bargeIn:      newBargeInDetector(cfg.Audio.BargeIn),
 	}
-}
 
-// SetCallbacks sets the callback functions
-func (p *Pipeline) SetCallbacks(
-	onStateChange func(State),
-	onTranscript func(string),
-	onResponse func(string),
-	onError func(error),
-) {
-	p.onStateChange = onStateChange
-	p.onTranscript = onTranscript
-	p.onResponse = onResponse
-	p.onError = onError
+	detector, err := vad.New(cfg.Audio.VAD, cfg.Audio.SampleRate, cfg.Audio.Channels)
+	if err != nil {
+		p.log.Warn().Err(err).Msg("Falling back to the adaptive VAD")
+		detector = vad.NewAdaptive(cfg.Audio.VAD.Sensitivity, cfg.Audio.SampleRate, cfg.Audio.Channels)
+	}
+	p.vad = detector
+	p.unsubscribe = events.Subscribe(p.onSpeech, events.KindTTSStarted, events.KindTTSStopped)
+
+	// Leave the confirming state when a pending confirmation times out
+	brn.OnConfirmationChange(func(session string, pending bool) {
+		if !pending && session == brain.DefaultSession {
+			p.stateMu.RLock()
+			confirming := p.state == StateConfirming
+			p.stateMu.RUnlock()
+			if confirming {
+				p.setState(StateIdle)
+			}
+		}
+	})
+
+	return p
 }
 
 // setState updates the pipeline state
@@ -118,9 +151,7 @@ func (p *Pipeline) setState(state State) {
 			Str("to", state.String()).
 			Msg("State change")
 
-		if p.onStateChange != nil {
-			p.onStateChange(state)
-		}
+		events.Publish(events.StateChanged{From: oldState.String(), To: state.String()})
 	}
 }
 
@@ -131,6 +162,12 @@ func (p *Pipeline) GetState() State {
 	return p.state
 }
 
+// SetWakeWord makes the pipeline start recording only when the detector
+// spots the wake word, instead of on any voice; call it before Start
+func (p *Pipeline) SetWakeWord(d *wakeword.Detector) {
+	p.wakeWord = d
+}
+
 // Start starts the pipeline
 func (p *Pipeline) Start(ctx context.Context) error {
 	p.log.Info().Msg("Starting pipeline")
@@ -143,6 +180,7 @@ func (p *Pipeline) Start(ctx context.Context) error {
 // Stop stops the pipeline
 func (p *Pipeline) Stop() {
 	p.log.Info().Msg("Stopping pipeline")
+	p.unsubscribe()
 	close(p.stopChan)
 }
 
@@ -179,31 +217,57 @@ func (p *Pipeline) FeedAudio(audio []byte) {
 	}
 }
 
-// ProcessText directly processes a text command (for testing)
-func (p *Pipeline) ProcessText(ctx context.Context, text string) (string, error) {
-	p.setState(StateProcessing)
-	defer p.setState(StateIdle)
+// endProcessing goes back to rest after a command, unless a barge-in or the
+// hotkey has already started the next recording
+func (p *Pipeline) endProcessing(ctx context.Context) {
+	switch p.GetState() {
+	case StateListening, StateRecording:
+		return
+	}
+	p.setState(p.restState(ctx))
+}
 
-	// Check if Jarvis name is mentioned
-	if !llm.IsJarvisActivated(text) {
-		p.log.Debug().Str("text", text).Msg("Ignoring input - Jarvis name not mentioned")
-		return "", nil
+// restState returns the state the pipeline goes back to after processing
+func (p *Pipeline) restState(ctx context.Context) State {
+	if p.brain.HasPendingConfirmation(ctx) {
+		return StateConfirming
 	}
+	return StateIdle
+}
 
-	if p.onTranscript != nil {
-		p.onTranscript(text)
+// ProcessText directly processes a text command (for testing); the
+// response is nil if the input wasn't addressed to Jarvis
+func (p *Pipeline) ProcessText(ctx context.Context, text string) (*brain.Response, error) {
+	// Check if Jarvis name is mentioned; a confirmation answer doesn't need it
+	if !p.brain.HasPendingConfirmation(ctx) && !llm.IsJarvisActivated(text) {
+		p.log.Debug().Str("text", text).Msg("Ignoring input - Jarvis name not mentioned")
+		return nil, nil
 	}
 
+	return p.HandleCommand(ctx, text)
+}
+
+// HandleCommand processes a typed command and speaks the response. Unlike
+// ProcessText it doesn't require the Jarvis name, since the command was
+// explicitly addressed to Jarvis (control API, Stream Deck...).
+func (p *Pipeline) HandleCommand(ctx context.Context, text string) (*brain.Response, error) {
+	p.setState(StateProcessing)
+	defer p.endProcessing(ctx)
+
+	events.Publish(events.Transcript{Text: text, Source: "text"})
+
 	response, err := p.brain.ProcessCommand(ctx, text)
-	if err == nil && response != "" {
-		if p.onResponse != nil {
-			p.onResponse(response)
-		}
-		// Try to speak but don't fail if it doesn't work
-		_ = p.brain.ProcessAndSpeak(ctx, text)
+	if err != nil {
+		events.Publish(events.Error{Stage: "brain", Error: err.Error()})
+		return nil, err
+	}
+
+	// Try to speak but don't fail if it doesn't work
+	if err := p.brain.SpeakResponse(ctx, response); err != nil {
+		p.log.Warn().Err(err).Msg("TTS failed, but continuing without audio")
 	}
 
-	return response, err
+	return response, nil
 }
 
 // run is the main pipeline loop
@@ -226,6 +290,9 @@ func (p *Pipeline) run(ctx context.Context) {
 
 		case <-p.hotkeyDown:
 			p.log.Debug().Msg("Hotkey pressed")
+			if p.GetState() == StateSpeaking {
+				p.brain.StopSpeaking()
+			}
 			p.startRecording()
 
 		case <-p.hotkeyUp:
@@ -242,106 +309,172 @@ func (p *Pipeline) run(ctx context.Context) {
 func (p *Pipeline) handleWakeWord(ctx context.Context) {
 	p.setState(StateListening)
 	p.startRecording()
+	p.wakeTriggered.Store(true)
+
+	p.recordInBackground(ctx)
+}
+
+// recordInBackground ends the current recording on silence and processes
+// it, in a goroutine so the loop keeps handling audio. If the hotkey
+// starts another recording meanwhile, the goroutine leaves that one to it.
+func (p *Pipeline) recordInBackground(ctx context.Context) {
+	id := p.recordingID.Load()
+	p.endsOnSilence.Store(true)
 
-	// Start recording in background goroutine (non-blocking)
-	// This allows the main pipeline loop to continue processing audio
 	go func() {
-		p.recordUntilSilence(ctx)
-		p.processRecordedAudio(ctx)
+		if !p.recordUntilSilence(ctx, id) {
+			return
+		}
+		p.processRecordedAudio(ctx, id)
 	}()
 }
 
 // handleHotkeyRelease handles when the hotkey is released
 func (p *Pipeline) handleHotkeyRelease(ctx context.Context) {
-	if p.GetState() != StateRecording {
+	// A recording started by the VAD or the wake word ends on silence
+	if p.GetState() != StateRecording || p.endsOnSilence.Load() {
 		return
 	}
 
-	p.processRecordedAudio(ctx)
+	// Process in the background like the other paths, so the loop keeps
+	// handling audio and the hotkey while the reply is spoken and the user
+	// can talk over it
+	p.setState(StateProcessing)
+	go p.processRecordedAudio(ctx, p.recordingID.Load())
 }
 
-// startRecording starts recording audio
+// startRecording starts recording audio, from the pre-roll so the onset of
+// the speech that triggered it isn't lost
 func (p *Pipeline) startRecording() {
 	p.bufferMu.Lock()
 	p.audioBuffer.Reset()
-	p.bufferMu.Unlock()
-
-	p.setState(StateRecording)
+	p.audioBuffer.Write(p.preRoll.Bytes())
+	p.preRoll.Reset()
+	p.speechEnd = 0
 	p.hasSpeech = false
 	p.silenceStart = time.Time{}
 	p.speechStart = time.Time{}
+	p.recordingID.Add(1)
+	p.bufferMu.Unlock()
+
+	if p.wakeWord != nil {
+		p.wakeWord.Reset()
+	}
+	p.wakeTriggered.Store(false)
+	p.endsOnSilence.Store(false)
+
+	p.setState(StateRecording)
 }
 
 // handleAudio handles incoming audio chunks
 func (p *Pipeline) handleAudio(ctx context.Context, audio []byte) {
 	state := p.GetState()
 
-	// Auto-start recording when speech is detected in Idle state
-	if state == StateIdle && p.cfg.Audio.VAD.Enabled {
-		samples := utils.BytesToInt16(audio)
-		rms := utils.CalculateRMS(samples)
-		threshold := float64(500) * (1.0 - p.cfg.Audio.VAD.Sensitivity)
+	// Jarvis's own voice is never recorded; talking over it interrupts it
+	if state == StateSpeaking {
+		if !p.checkBargeIn(ctx, audio) {
+			p.preRoll.Write(audio)
+		}
+		return
+	}
+
+	// The VAD sees every chunk, so it keeps learning the background noise
+	samples := utils.BytesToInt16(audio)
+	speech := p.cfg.Audio.VAD.Enabled && p.vad.Process(samples)
+
+	// With a wake word, Idle only listens for it
+	if state == StateIdle && p.wakeWord != nil {
+		if p.wakeWord.Feed(samples) {
+			p.TriggerWakeWord()
+		}
+		p.preRoll.Write(audio)
+		return
+	}
 
-		if rms > threshold {
-			p.log.Debug().Float64("rms", rms).Float64("threshold", threshold).Msg("Voice detected in Idle state, starting recording")
+	// Auto-start recording when speech is detected in Idle state,
+	// or while waiting for the answer to a confirmation
+	if (state == StateIdle || state == StateConfirming) && p.cfg.Audio.VAD.Enabled {
+		if speech {
+			p.log.Debug().Msg("Voice detected in Idle state, starting recording")
 			p.setState(StateListening)
 			p.startRecording()
-
-			// Start silence detection in background
-			go func() {
-				p.recordUntilSilence(ctx)
-				p.processRecordedAudio(ctx)
-			}()
+			p.recordInBackground(ctx)
 			// Fall through to record this chunk
 		}
 	}
 
 	// Always analyze for VAD when listening or recording
 	if state == StateListening || state == StateRecording || p.GetState() == StateListening || p.GetState() == StateRecording {
-		p.bufferMu.Lock()
-		p.audioBuffer.Write(audio)
-		p.bufferMu.Unlock()
-
-		// Check for speech/silence
-		if p.cfg.Audio.VAD.Enabled {
-			p.analyzeVAD(audio)
-		}
+		p.record(audio, speech)
+		return
 	}
+
+	p.preRoll.Write(audio)
 }
 
-// analyzeVAD analyzes audio for Voice Activity Detection
-func (p *Pipeline) analyzeVAD(audio []byte) {
-	// Convert bytes to samples
-	samples := utils.BytesToInt16(audio)
+// record appends a chunk to the recording and tracks speech and silence
+func (p *Pipeline) record(audio []byte, speech bool) {
+	p.bufferMu.Lock()
+	defer p.bufferMu.Unlock()
 
-	// Calculate RMS energy
-	rms := utils.CalculateRMS(samples)
+	p.audioBuffer.Write(audio)
+	if speech {
+		p.speechEnd = p.audioBuffer.Len()
+	}
 
-	// Threshold based on sensitivity
-	// Higher sensitivity = lower threshold = more sensitive to quiet sounds
-	threshold := float64(500) * (1.0 - p.cfg.Audio.VAD.Sensitivity)
+	// Check for speech/silence
+	if p.cfg.Audio.VAD.Enabled {
+		p.analyzeVAD(speech)
+	}
+}
 
-	isSpeech := rms > threshold
+// trimSilence cuts the silence that ended a recording down to the
+// post-roll after the last speech
+func (p *Pipeline) trimSilence() {
+	frame := 2 * p.cfg.Audio.Channels
+	pad := int(float64(p.cfg.Audio.PostRollMs) / 1000 * float64(p.cfg.Audio.SampleRate))
 
+	p.bufferMu.Lock()
+	defer p.bufferMu.Unlock()
+	if p.speechEnd > 0 && p.speechEnd+pad*frame < p.audioBuffer.Len() {
+		p.audioBuffer.Truncate(p.speechEnd + pad*frame)
+	}
+}
+
+// sttAudio converts a recording from the capture format to the one STT
+// providers take
+func (p *Pipeline) sttAudio(audio []byte) []byte {
+	rate, channels := p.cfg.Audio.SampleRate, p.cfg.Audio.Channels
+	if rate == stt.SampleRate && channels == stt.Channels {
+		return audio
+	}
+	samples := utils.Downmix(utils.BytesToInt16(audio), channels)
+	return utils.Int16ToBytes(utils.Resample(samples, rate, stt.SampleRate, stt.Channels))
+}
+
+// analyzeVAD tracks when speech starts and when the silence after it
+// starts, from the VAD decision on the latest chunk. The caller holds bufferMu.
+func (p *Pipeline) analyzeVAD(isSpeech bool) {
 	now := time.Now()
 
 	if isSpeech {
 		if !p.hasSpeech {
 			p.speechStart = now
 			p.hasSpeech = true
-			p.log.Debug().Float64("rms", rms).Msg("Speech detected")
+			p.log.Debug().Msg("Speech detected")
 		}
 		p.silenceStart = time.Time{} // Reset silence timer
 	} else {
 		if p.hasSpeech && p.silenceStart.IsZero() {
 			p.silenceStart = now
-			p.log.Debug().Float64("rms", rms).Msg("Silence detected")
+			p.log.Debug().Msg("Silence detected")
 		}
 	}
 }
 
-// recordUntilSilence records until silence is detected
-func (p *Pipeline) recordUntilSilence(ctx context.Context) {
+// recordUntilSilence waits until the recording ends on silence; it reports
+// false if the hotkey started another recording in the meantime
+func (p *Pipeline) recordUntilSilence(ctx context.Context, id uint64) bool {
 	silenceThreshold := time.Duration(p.cfg.Audio.VAD.SilenceThresholdMs) * time.Millisecond
 	maxRecordTime := 30 * time.Second // Maximum recording time
 
@@ -355,37 +488,59 @@ func (p *Pipeline) recordUntilSilence(ctx context.Context) {
 	for {
 		select {
 		case <-ctx.Done():
-			return
+			return true
 		case <-p.stopChan:
-			return
+			return true
 		case <-timeout.C:
 			p.log.Warn().Msg("Recording timeout reached")
-			return
+			return true
 		case <-ticker.C:
+			if p.recordingID.Load() != id {
+				p.log.Debug().Msg("Recording taken over by the hotkey")
+				return false
+			}
+
 			// Check if we have enough silence after speech
-			if p.hasSpeech && !p.silenceStart.IsZero() {
-				silenceDuration := time.Since(p.silenceStart)
+			p.bufferMu.Lock()
+			silenceStart := p.silenceStart
+			if !p.hasSpeech {
+				silenceStart = time.Time{}
+			}
+			p.bufferMu.Unlock()
+
+			if !silenceStart.IsZero() {
+				silenceDuration := time.Since(silenceStart)
 				if silenceDuration >= silenceThreshold {
 					p.log.Debug().
 						Dur("silence", silenceDuration).
 						Msg("Silence threshold reached")
-					return
+					p.trimSilence()
+					return true
 				}
 			}
 		}
 	}
 }
 
-// processRecordedAudio processes the recorded audio buffer
-func (p *Pipeline) processRecordedAudio(ctx context.Context) {
-	p.setState(StateProcessing)
-	defer p.setState(StateIdle)
-
+// processRecordedAudio processes the recorded audio buffer, unless the
+// recording id has been replaced by a newer one
+func (p *Pipeline) processRecordedAudio(ctx context.Context, id uint64) {
 	p.bufferMu.Lock()
-	audio := p.audioBuffer.Bytes()
+	if p.recordingID.Load() != id {
+		p.bufferMu.Unlock()
+		p.log.Debug().Msg("Recording taken over by the hotkey")
+		return
+	}
+	audio := bytes.Clone(p.audioBuffer.Bytes())
 	p.audioBuffer.Reset()
+	hasSpeech, speechStart := p.hasSpeech, p.speechStart
+	woken := p.wakeTriggered.Load()
 	p.bufferMu.Unlock()
 
+	confirming := p.brain.HasPendingConfirmation(ctx)
+	p.setState(StateProcessing)
+	defer p.endProcessing(ctx)
+
 	if len(audio) == 0 {
 		p.log.Warn().Msg("No audio recorded")
 		return
@@ -393,8 +548,8 @@ func (p *Pipeline) processRecordedAudio(ctx context.Context) {
 
 	// Check minimum speech duration
 	minSpeech := time.Duration(p.cfg.Audio.VAD.MinSpeechMs) * time.Millisecond
-	if p.hasSpeech && !p.speechStart.IsZero() {
-		speechDuration := time.Since(p.speechStart)
+	if hasSpeech && !speechStart.IsZero() {
+		speechDuration := time.Since(speechStart)
 		if speechDuration < minSpeech {
 			p.log.Debug().
 				Dur("duration", speechDuration).
@@ -407,12 +562,10 @@ func (p *Pipeline) processRecordedAudio(ctx context.Context) {
 	p.log.Info().Int("bytes", len(audio)).Msg("Processing recorded audio")
 
 	// Transcribe
-	result, err := p.sttProvider.Transcribe(ctx, audio)
+	result, err := p.sttProvider.Transcribe(ctx, p.sttAudio(audio))
 	if err != nil {
 		p.log.Error().Err(err).Msg("Transcription failed")
-		if p.onError != nil {
-			p.onError(fmt.Errorf("transcription failed: %w", err))
-		}
+		events.Publish(events.Error{Stage: "stt", Error: err.Error()})
 		return
 	}
 
@@ -424,36 +577,32 @@ func (p *Pipeline) processRecordedAudio(ctx context.Context) {
 
 	p.log.Info().Str("text", text).Msg("Transcribed")
 
-	// Check if Jarvis name is mentioned
-	if !llm.IsJarvisActivated(text) {
+	// Check if Jarvis name is mentioned; a confirmation answer, or a command
+	// spoken after the wake word, doesn't need it
+	if !confirming && !woken && !llm.IsJarvisActivated(text) {
 		p.log.Debug().Str("text", text).Msg("Ignoring transcription - Jarvis name not mentioned")
 		return
 	}
 
-	if p.onTranscript != nil {
-		p.onTranscript(text)
-	}
+	events.Publish(events.Transcript{Text: text, Source: "voice"})
 
 	// Process command
 	response, err := p.brain.ProcessCommand(ctx, text)
 	if err != nil {
 		p.log.Error().Err(err).Msg("Command processing failed")
-		if p.onError != nil {
-			p.onError(fmt.Errorf("command processing failed: %w", err))
-		}
+		events.Publish(events.Error{Stage: "brain", Error: err.Error()})
 		return
 	}
 
-	if response != "" {
-		p.log.Info().Str("response", response).Msg("Response")
-
-		if p.onResponse != nil {
-			p.onResponse(response)
-		}
+	if response.Text != "" {
+		p.log.Info().
+			Str("response", response.Text).
+			Dur("total", response.Timings.Total).
+			Msg("Response")
 	}
 
-	// Speak response
-	if err := p.brain.ProcessAndSpeak(ctx, text); err != nil {
+	// Speak the response of that single execution
+	if err := p.brain.SpeakResponse(ctx, response); err != nil {
 		p.log.Error().Err(err).Msg("TTS failed")
 	}
 }
@@ -0,0 +1,106 @@
+package pipeline
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jarvisstreamer/jarvis/internal/brain"
+	"github.com/jarvisstreamer/jarvis/internal/config"
+	"github.com/jarvisstreamer/jarvis/internal/stt"
+)
+
+// countingSTT counts the recordings it is given and transcribes them all
+// to silence
+type countingSTT struct {
+	calls atomic.Int32
+}
+
+func (s *countingSTT) Name() string { return "counting" }
+func (s *countingSTT) Transcribe(context.Context, []byte) (*stt.TranscriptionResult, error) {
+	s.calls.Add(1)
+	return &stt.TranscriptionResult{}, nil
+}
+func (s *countingSTT) TranscribeFile(context.Context, string) (*stt.TranscriptionResult, error) {
+	return &stt.TranscriptionResult{}, nil
+}
+func (s *countingSTT) SetLanguage(string)               {}
+func (s *countingSTT) IsAvailable(context.Context) bool { return true }
+func (s *countingSTT) Close() error                     { return nil }
+
+func newTestPipeline(t *testing.T) (*Pipeline, *countingSTT) {
+	cfg := &config.Config{}
+	cfg.Audio.SampleRate = 16000
+	cfg.Audio.Channels = 1
+	cfg.Audio.VAD.Enabled = true
+	cfg.Audio.VAD.SilenceThresholdMs = 50
+
+	transcriber := &countingSTT{}
+	p := NewPipeline(cfg, transcriber, brain.New(cfg, nil, nil))
+	t.Cleanup(p.unsubscribe)
+	return p, transcriber
+}
+
+// waitCalls waits for the pipeline to go back to rest and returns how many
+// recordings were transcribed
+func waitCalls(t *testing.T, p *Pipeline, transcriber *countingSTT) int32 {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for p.GetState() != StateIdle {
+		if time.Now().After(deadline) {
+			t.Fatalf("pipeline stuck in %s", p.GetState())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// Let a stray second goroutine show up
+	time.Sleep(200 * time.Millisecond)
+	return transcriber.calls.Load()
+}
+
+func TestHotkeyReleaseLeavesSilenceRecording(t *testing.T) {
+	p, transcriber := newTestPipeline(t)
+	ctx := context.Background()
+	chunk := make([]byte, 320)
+
+	// Started by the VAD, the way handleAudio does
+	p.setState(StateListening)
+	p.startRecording()
+	p.recordInBackground(ctx)
+	p.record(chunk, true)
+
+	p.handleHotkeyRelease(ctx)
+	if state := p.GetState(); state != StateRecording {
+		t.Fatalf("hotkey release cut the recording short: state %s", state)
+	}
+	p.record(chunk, false)
+
+	if calls := waitCalls(t, p, transcriber); calls != 1 {
+		t.Errorf("recording transcribed %d times, want once", calls)
+	}
+}
+
+func TestHotkeyTakesOverSilenceRecording(t *testing.T) {
+	p, transcriber := newTestPipeline(t)
+	ctx := context.Background()
+	chunk := make([]byte, 320)
+
+	p.setState(StateListening)
+	p.startRecording()
+	p.recordInBackground(ctx)
+	p.record(chunk, true)
+
+	// Hotkey pressed while the VAD recording waits for silence
+	p.startRecording()
+	p.record(chunk, true)
+	p.record(chunk, false)
+	time.Sleep(150 * time.Millisecond) // Past the silence threshold
+	if calls := transcriber.calls.Load(); calls != 0 || p.GetState() != StateRecording {
+		t.Fatalf("hotkey recording processed before the release (%d times, state %s)", calls, p.GetState())
+	}
+	p.handleHotkeyRelease(ctx)
+
+	if calls := waitCalls(t, p, transcriber); calls != 1 {
+		t.Errorf("recording transcribed %d times, want once", calls)
+	}
+}
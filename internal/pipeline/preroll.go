@@ -0,0 +1,57 @@
+package pipeline
+
+import "time"
+
+// ringBuffer keeps the latest audio up to a fixed size
+type ringBuffer struct {
+	data  []byte
+	start int // Oldest byte
+	size  int
+}
+
+// newRingBuffer creates a ring holding the given duration of audio, rounded
+// down to whole sample frames
+func newRingBuffer(d time.Duration, sampleRate, channels int) *ringBuffer {
+	frame := 2 * channels // 16-bit samples
+	frames := int(d.Seconds() * float64(sampleRate))
+	return &ringBuffer{data: make([]byte, frames*frame)}
+}
+
+// Write appends audio, dropping the oldest when the ring is full
+func (r *ringBuffer) Write(audio []byte) {
+	if len(r.data) == 0 {
+		return
+	}
+	if len(audio) >= len(r.data) {
+		copy(r.data, audio[len(audio)-len(r.data):])
+		r.start, r.size = 0, len(r.data)
+		return
+	}
+
+	// Once full, the write position is the oldest byte, so overwriting
+	// always drops the oldest audio first
+	for len(audio) > 0 {
+		end := (r.start + r.size) % len(r.data)
+		n := copy(r.data[end:], audio)
+		audio = audio[n:]
+
+		r.size += n
+		if r.size > len(r.data) {
+			r.start = (r.start + r.size - len(r.data)) % len(r.data)
+			r.size = len(r.data)
+		}
+	}
+}
+
+// Bytes returns a copy of the buffered audio, oldest first
+func (r *ringBuffer) Bytes() []byte {
+	out := make([]byte, r.size)
+	n := copy(out, r.data[r.start:min(r.start+r.size, len(r.data))])
+	copy(out[n:], r.data)
+	return out
+}
+
+// Reset empties the ring
+func (r *ringBuffer) Reset() {
+	r.start, r.size = 0, 0
+}
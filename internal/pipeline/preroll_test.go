@@ -0,0 +1,80 @@
+package pipeline
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestNewRingBufferSize(t *testing.T) {
+	tests := []struct {
+		d                    time.Duration
+		sampleRate, channels int
+		want                 int
+	}{
+		{300 * time.Millisecond, 16000, 1, 9600},
+		{300 * time.Millisecond, 48000, 2, 57600},
+		{time.Second / 3, 16000, 1, 10666}, // Whole frames only
+		{0, 16000, 1, 0},
+	}
+	for _, tt := range tests {
+		if got := len(newRingBuffer(tt.d, tt.sampleRate, tt.channels).data); got != tt.want {
+			t.Errorf("newRingBuffer(%v, %d, %d) holds %d bytes, want %d", tt.d, tt.sampleRate, tt.channels, got, tt.want)
+		}
+	}
+}
+
+func TestRingBufferKeepsLatest(t *testing.T) {
+	r := &ringBuffer{data: make([]byte, 8)}
+	if got := r.Bytes(); len(got) != 0 {
+		t.Errorf("empty ring = %v", got)
+	}
+
+	r.Write([]byte{1, 2, 3})
+	if got := r.Bytes(); !bytes.Equal(got, []byte{1, 2, 3}) {
+		t.Errorf("partial ring = %v", got)
+	}
+
+	// Wraps around, dropping the oldest bytes
+	r.Write([]byte{4, 5, 6, 7, 8, 9, 10})
+	if got := r.Bytes(); !bytes.Equal(got, []byte{3, 4, 5, 6, 7, 8, 9, 10}) {
+		t.Errorf("wrapped ring = %v", got)
+	}
+	r.Write([]byte{11})
+	if got := r.Bytes(); !bytes.Equal(got, []byte{4, 5, 6, 7, 8, 9, 10, 11}) {
+		t.Errorf("ring after another write = %v", got)
+	}
+
+	// A write bigger than the ring keeps only its tail
+	r.Write([]byte{20, 21, 22, 23, 24, 25, 26, 27, 28, 29})
+	if got := r.Bytes(); !bytes.Equal(got, []byte{22, 23, 24, 25, 26, 27, 28, 29}) {
+		t.Errorf("ring after oversize write = %v", got)
+	}
+
+	r.Reset()
+	if got := r.Bytes(); len(got) != 0 {
+		t.Errorf("ring after Reset = %v", got)
+	}
+	r.Write([]byte{30})
+	if got := r.Bytes(); !bytes.Equal(got, []byte{30}) {
+		t.Errorf("ring reused after Reset = %v", got)
+	}
+}
+
+func TestRingBufferBytesIsACopy(t *testing.T) {
+	r := &ringBuffer{data: make([]byte, 4)}
+	r.Write([]byte{1, 2, 3, 4})
+	got := r.Bytes()
+	r.Write([]byte{5, 6})
+	if !bytes.Equal(got, []byte{1, 2, 3, 4}) {
+		t.Errorf("Bytes() changed by a later write: %v", got)
+	}
+}
+
+func TestRingBufferZeroSize(t *testing.T) {
+	r := newRingBuffer(0, 16000, 1)
+	r.Write([]byte{1, 2})
+	if got := r.Bytes(); len(got) != 0 {
+		t.Errorf("zero-size ring = %v", got)
+	}
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/jarvisstreamer/jarvis/internal/events"
+)
+
+const (
+	// clientBuffer is how many events a WebSocket client may lag behind before events are dropped
+	clientBuffer = 64
+	writeTimeout = 5 * time.Second
+	pingInterval = 30 * time.Second
+)
+
+// upgrader accepts any origin: every request is already authenticated by token
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// handleEvents streams bus events as JSON envelopes over a WebSocket.
+// ?types=state_changed,reply limits the stream to those kinds.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		s.log.Warn().Err(err).Msg("WebSocket upgrade failed")
+		return
+	}
+	defer conn.Close()
+
+	var kinds []events.Kind
+	if types := r.URL.Query().Get("types"); types != "" {
+		for _, t := range strings.Split(types, ",") {
+			kinds = append(kinds, events.Kind(strings.TrimSpace(t)))
+		}
+	}
+
+	// The bus handler must not block on a slow client, so it only queues
+	queue := make(chan events.Envelope, clientBuffer)
+	unsubscribe := events.Subscribe(func(env events.Envelope) {
+		select {
+		case queue <- env:
+		default:
+		}
+	}, kinds...)
+	defer unsubscribe()
+
+	s.log.Debug().Str("remote", r.RemoteAddr).Msg("Event stream client connected")
+	defer s.log.Debug().Str("remote", r.RemoteAddr).Msg("Event stream client disconnected")
+
+	// Reading is only needed to notice the client going away
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	// Start with the current state so clients don't wait for the next change
+	state := s.pipeline.GetState().String()
+	select {
+	case queue <- events.Envelope{
+		Kind:  events.KindStateChanged,
+		Time:  time.Now(),
+		Event: events.StateChanged{From: state, To: state},
+	}:
+	default:
+	}
+
+	ping := time.NewTicker(pingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-s.ctx.Done():
+			return
+
+		case env := <-queue:
+			if !wantsKind(kinds, env.Kind) {
+				continue
+			}
+			conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := conn.WriteJSON(env); err != nil {
+				return
+			}
+
+		case <-ping.C:
+			conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// wantsKind returns true if a client filtering on kinds should receive kind
+func wantsKind(kinds []events.Kind, kind events.Kind) bool {
+	if len(kinds) == 0 {
+		return true
+	}
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/jarvisstreamer/jarvis/internal/brain"
+	"github.com/jarvisstreamer/jarvis/internal/executor"
+	"github.com/jarvisstreamer/jarvis/internal/llm"
+)
+
+// commandRequest is the body of POST /command
+type commandRequest struct {
+	Text    string `json:"text"`
+	Session string `json:"session,omitempty"` // Conversation to continue; the voice one by default
+}
+
+// actionRequest is the body of POST /action
+type actionRequest struct {
+	llm.Action
+	Session string `json:"session,omitempty"`
+}
+
+// replyResponse is returned by POST /command and POST /action
+type replyResponse struct {
+	Response string            `json:"response"`
+	Action   string            `json:"action,omitempty"`
+	Results  []executor.Result `json:"results,omitempty"`
+}
+
+// newReplyResponse builds the reply of a command; a nil response (input not
+// addressed to Jarvis) has an empty reply
+func newReplyResponse(resp *brain.Response) replyResponse {
+	if resp == nil {
+		return replyResponse{}
+	}
+	reply := replyResponse{Response: resp.Text, Results: resp.Results}
+	if resp.Action != nil {
+		reply.Action = resp.Action.Action
+	}
+	return reply
+}
+
+// statusResponse is returned by GET /status
+type statusResponse struct {
+	State               string                  `json:"state"`
+	PendingConfirmation bool                    `json:"pending_confirmation"`
+	STT                 *brain.ComponentStatus  `json:"stt"`
+	LLM                 *brain.ComponentStatus  `json:"llm"`
+	TTS                 *brain.ComponentStatus  `json:"tts"`
+	Executors           []brain.ComponentStatus `json:"executors"`
+}
+
+// handleCommand processes a text command as if it had been spoken
+func (s *Server) handleCommand(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var req commandRequest
+	if err := readJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	req.Text = strings.TrimSpace(req.Text)
+	if req.Text == "" {
+		writeError(w, http.StatusBadRequest, "text is required")
+		return
+	}
+
+	s.log.Info().Str("text", req.Text).Str("remote", r.RemoteAddr).Msg("Command received")
+
+	response, err := s.pipeline.HandleCommand(s.commandContext(req.Session), req.Text)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newReplyResponse(response))
+}
+
+// handleAction runs a raw action, skipping interpretation and confirmation
+func (s *Server) handleAction(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var req actionRequest
+	if err := readJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := req.Action.Normalize(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.log.Info().Str("action", req.Action.Action).Str("remote", r.RemoteAddr).Msg("Action received")
+
+	response, err := s.brain.ExecuteAction(s.commandContext(req.Session), req.Action, "api")
+	var verr *executor.ValidationError
+	if errors.As(err, &verr) {
+		writeError(w, http.StatusBadRequest, verr.Reason)
+		return
+	}
+	var perr *executor.PolicyError
+	if errors.As(err, &perr) {
+		writeError(w, http.StatusForbidden, perr.Reason)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newReplyResponse(response))
+}
+
+// handleStatus reports the pipeline state and provider/executor health
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	ctx := r.Context()
+	health := s.brain.Status(ctx)
+
+	resp := statusResponse{
+		State:               s.pipeline.GetState().String(),
+		PendingConfirmation: s.brain.HasPendingConfirmation(ctx),
+		LLM:                 health.LLM,
+		TTS:                 health.TTS,
+		Executors:           health.Executors,
+	}
+	if s.sttProvider != nil {
+		resp.STT = &brain.ComponentStatus{Name: s.sttProvider.Name(), Available: s.sttProvider.IsAvailable(ctx)}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// commandContext returns the server context, scoped to a conversation session if one was given
+func (s *Server) commandContext(session string) context.Context {
+	if session == "" {
+		return s.ctx
+	}
+	return brain.WithSession(s.ctx, session)
+}
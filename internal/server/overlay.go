@@ -0,0 +1,51 @@
+package server
+
+import (
+	"embed"
+	"html/template"
+	"net/http"
+)
+
+//go:embed overlay/index.html
+var overlayFS embed.FS
+
+// overlayTemplate is the OBS browser-source page
+var overlayTemplate = template.Must(template.ParseFS(overlayFS, "overlay/index.html"))
+
+// overlayThemes are the themes defined in the overlay stylesheet
+var overlayThemes = map[string]bool{
+	"dark":    true,
+	"light":   true,
+	"minimal": true,
+	"neon":    true,
+}
+
+// overlayData fills the overlay template
+type overlayData struct {
+	Theme       string
+	HideAfterMs int // Negative keeps the overlay visible
+}
+
+// handleOverlay serves the overlay page; ?theme= overrides the configured theme
+// so several browser sources can use different looks
+func (s *Server) handleOverlay(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	theme := s.cfg.Overlay.Theme
+	if t := r.URL.Query().Get("theme"); overlayThemes[t] {
+		theme = t
+	}
+
+	data := overlayData{Theme: theme, HideAfterMs: -1}
+	if s.cfg.Overlay.HideAfterSeconds >= 0 {
+		data.HideAfterMs = s.cfg.Overlay.HideAfterSeconds * 1000
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := overlayTemplate.Execute(w, data); err != nil {
+		s.log.Error().Err(err).Msg("Failed to render overlay")
+	}
+}
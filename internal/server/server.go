@@ -0,0 +1,141 @@
+// Package server provides the local HTTP and WebSocket control API used by
+// Stream Deck buttons, other tools and the OBS overlay
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jarvisstreamer/jarvis/internal/brain"
+	"github.com/jarvisstreamer/jarvis/internal/config"
+	"github.com/jarvisstreamer/jarvis/internal/pipeline"
+	"github.com/jarvisstreamer/jarvis/internal/stt"
+	"github.com/jarvisstreamer/jarvis/pkg/logger"
+	"github.com/rs/zerolog"
+)
+
+// maxBodyBytes limits the size of JSON request bodies
+const maxBodyBytes = 64 << 10
+
+// Server is the local control API
+type Server struct {
+	cfg         config.ServerConfig
+	pipeline    *pipeline.Pipeline
+	brain       *brain.Brain
+	sttProvider stt.Provider
+	log         zerolog.Logger
+
+	http *http.Server
+	ctx  context.Context
+}
+
+// New creates a control API server; sttProvider may be nil
+func New(cfg config.ServerConfig, p *pipeline.Pipeline, b *brain.Brain, sttProvider stt.Provider) *Server {
+	s := &Server{
+		cfg:         cfg,
+		pipeline:    p,
+		brain:       b,
+		sttProvider: sttProvider,
+		log:         logger.Component("server"),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/command", s.authorized(s.handleCommand))
+	mux.HandleFunc("/action", s.authorized(s.handleAction))
+	mux.HandleFunc("/status", s.authorized(s.handleStatus))
+	mux.HandleFunc("/events", s.authorized(s.handleEvents))
+	if cfg.Overlay.Enabled {
+		mux.HandleFunc("/overlay", s.authorized(s.handleOverlay))
+	}
+
+	s.http = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return s
+}
+
+// Start binds the configured address and serves in the background. Commands
+// run with ctx, so they are not cut short when a client disconnects.
+func (s *Server) Start(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.cfg.Address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.cfg.Address, err)
+	}
+	s.ctx = ctx
+
+	s.log.Info().Str("address", ln.Addr().String()).Msg("Control API listening")
+
+	go func() {
+		if err := s.http.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.log.Error().Err(err).Msg("Control API stopped")
+		}
+	}()
+
+	return nil
+}
+
+// Close stops accepting requests and waits briefly for running ones
+func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.http.Shutdown(ctx)
+}
+
+// authorized rejects requests without the configured token, given either as
+// "Authorization: Bearer <token>" or as ?token= for browser WebSockets
+func (s *Server) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+			token = strings.TrimPrefix(auth, "Bearer ")
+		}
+
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.Token)) != 1 {
+			s.log.Warn().Str("remote", r.RemoteAddr).Str("path", r.URL.Path).Msg("Rejected unauthorized request")
+			writeError(w, http.StatusUnauthorized, "invalid or missing token")
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// readJSON decodes a JSON request body into v
+func readJSON(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid JSON body: %w", err)
+	}
+	return nil
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// requireMethod writes a 405 and returns false if the request uses another method
+func requireMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return false
+	}
+	return true
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/jarvisstreamer/jarvis/internal/config"
+	"github.com/jarvisstreamer/jarvis/internal/events"
 	"github.com/jarvisstreamer/jarvis/pkg/logger"
 	"github.com/jarvisstreamer/jarvis/pkg/utils"
 	"github.com/rs/zerolog"
@@ -64,6 +65,11 @@ func (p *OpenAISTTProvider) Name() string {
 
 // Transcribe converts audio bytes to text
 func (p *OpenAISTTProvider) Transcribe(ctx context.Context, audio []byte) (*TranscriptionResult, error) {
+	start := time.Now()
+	defer func() {
+		events.Publish(events.Timing{Stage: "stt", Provider: p.Name(), Duration: time.Since(start)})
+	}()
+
 	// Create temporary WAV file
 	tempFile := utils.GetTempFilePath("jarvis_audio", ".wav")
 	defer os.Remove(tempFile)
@@ -74,7 +80,7 @@ func (p *OpenAISTTProvider) Transcribe(ctx context.Context, audio []byte) (*Tran
 			return nil, fmt.Errorf("failed to write temp audio file: %w", err)
 		}
 	} else {
-		if err := utils.SaveWAV(tempFile, audio, 16000, 1, 16); err != nil {
+		if err := utils.SaveWAV(tempFile, audio, SampleRate, Channels, 16); err != nil {
 			return nil, fmt.Errorf("failed to save audio as WAV: %w", err)
 		}
 	}
@@ -8,6 +8,13 @@ import (
 	"github.com/jarvisstreamer/jarvis/internal/config"
 )
 
+// Audio format providers transcribe: 16-bit PCM at this sample rate and
+// channel count
+const (
+	SampleRate = 16000
+	Channels   = 1
+)
+
 // TranscriptionResult holds the result of a transcription
 type TranscriptionResult struct {
 	Text       string  // Transcribed text
@@ -22,7 +29,7 @@ type Provider interface {
 	Name() string
 
 	// Transcribe converts audio bytes to text
-	// Audio should be in WAV format, 16kHz, mono, 16-bit PCM
+	// Audio should be raw 16-bit PCM at SampleRate, mono
 	Transcribe(ctx context.Context, audio []byte) (*TranscriptionResult, error)
 
 	// TranscribeFile transcribes an audio file
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/jarvisstreamer/jarvis/internal/config"
+	"github.com/jarvisstreamer/jarvis/internal/events"
 	"github.com/jarvisstreamer/jarvis/pkg/logger"
 	"github.com/jarvisstreamer/jarvis/pkg/utils"
 	"github.com/rs/zerolog"
@@ -51,6 +52,11 @@ func (p *WhisperProvider) Transcribe(ctx context.Context, audio []byte) (*Transc
 		Int("audio_len", len(audio)).
 		Msg("Transcribe called with audio bytes")
 
+	start := time.Now()
+	defer func() {
+		events.Publish(events.Timing{Stage: "stt", Provider: p.Name(), Duration: time.Since(start)})
+	}()
+
 	// Create temporary WAV file
 	tempFile := utils.GetTempFilePath("jarvis_audio", ".wav")
 	defer os.Remove(tempFile)
@@ -64,7 +70,7 @@ func (p *WhisperProvider) Transcribe(ctx context.Context, audio []byte) (*Transc
 		p.log.Debug().Str("file", tempFile).Int("size", len(audio)).Msg("Wrote WAV file (already WAV format)")
 	} else {
 		// Raw PCM, convert to WAV
-		if err := utils.SaveWAV(tempFile, audio, 16000, 1, 16); err != nil {
+		if err := utils.SaveWAV(tempFile, audio, SampleRate, Channels, 16); err != nil {
 			return nil, fmt.Errorf("failed to save audio as WAV: %w", err)
 		}
 		p.log.Debug().Str("file", tempFile).Int("pcm_size", len(audio)).Msg("Converted PCM to WAV")
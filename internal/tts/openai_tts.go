@@ -77,7 +77,7 @@ func (p *OpenAITTSProvider) Name() string {
 }
 
 // Speak converts text to speech and plays it
-func (p *OpenAITTSProvider) Speak(ctx context.Context, text string) error {
+func (p *OpenAITTSProvider) Speak(ctx context.Context, text string) (err error) {
 	p.mu.Lock()
 	if p.isPlaying {
 		p.mu.Unlock()
@@ -96,6 +96,9 @@ func (p *OpenAITTSProvider) Speak(ctx context.Context, text string) error {
 
 	p.log.Debug().Str("text", text).Msg("Speaking text via OpenAI")
 
+	stopped := speechStarted(p.Name(), text)
+	defer func() { stopped(err) }()
+
 	// Synthesize audio
 	audio, err := p.Synthesize(ctx, text)
 	if err != nil {
@@ -66,7 +66,7 @@ func (p *PiperProvider) Name() string {
 }
 
 // Speak converts text to speech and plays it
-func (p *PiperProvider) Speak(ctx context.Context, text string) error {
+func (p *PiperProvider) Speak(ctx context.Context, text string) (err error) {
 	p.mu.Lock()
 	if p.isPlaying {
 		p.mu.Unlock()
@@ -85,6 +85,9 @@ func (p *PiperProvider) Speak(ctx context.Context, text string) error {
 
 	p.log.Debug().Str("text", text).Msg("Speaking text")
 
+	stopped := speechStarted(p.Name(), text)
+	defer func() { stopped(err) }()
+
 	// Synthesize audio
 	audio, err := p.Synthesize(ctx, text)
 	if err != nil {
@@ -155,7 +158,7 @@ func (p *PiperProvider) Synthesize(ctx context.Context, text string) ([]byte, er
 
 	// Wait for completion
 	if err := cmd.Wait(); err != nil {
-		errMsg := fmt.Sprintf("piper execution failed: %w", err)
+		errMsg := fmt.Sprintf("piper execution failed: %v", err)
 		if stderr.Len() > 0 {
 			errMsg = fmt.Sprintf("%s (stderr: %s)", errMsg, stderr.String())
 		}
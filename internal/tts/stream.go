@@ -0,0 +1,143 @@
+package tts
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// maxQueuedSentences bounds the sentences waiting to be spoken
+const maxQueuedSentences = 32
+
+// Stream speaks text that arrives in pieces, one sentence at a time:
+// playback starts as soon as the first sentence is complete, while the
+// rest is still being written
+type Stream struct {
+	provider  Provider
+	ctx       context.Context
+	sentences chan string
+	done      chan struct{}
+
+	mu      sync.Mutex
+	pending string
+	text    strings.Builder
+	closed  bool
+	err     error
+
+	// sendMu keeps sentences in order while a full queue blocks, without
+	// holding mu; it is taken while holding mu, never the other way round
+	sendMu sync.Mutex
+}
+
+// NewStream starts a stream that speaks through the provider
+func NewStream(ctx context.Context, provider Provider) *Stream {
+	s := &Stream{
+		provider:  provider,
+		ctx:       ctx,
+		sentences: make(chan string, maxQueuedSentences),
+		done:      make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Write adds text and queues every sentence it completes
+func (s *Stream) Write(text string) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+
+	s.text.WriteString(text)
+	s.pending += text
+	var sentences []string
+	for {
+		sentence, rest, ok := cutSentence(s.pending)
+		if !ok {
+			break
+		}
+		s.pending = rest
+		sentences = append(sentences, sentence)
+	}
+
+	s.sendMu.Lock()
+	s.mu.Unlock()
+	defer s.sendMu.Unlock()
+	for _, sentence := range sentences {
+		s.queue(sentence)
+	}
+}
+
+// Text returns everything written to the stream
+func (s *Stream) Text() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.text.String()
+}
+
+// Close speaks the remaining text and waits until playback ends; it
+// returns the first speech error
+func (s *Stream) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+	} else {
+		s.closed = true
+		rest := s.pending
+		s.pending = ""
+
+		s.sendMu.Lock()
+		s.mu.Unlock()
+		s.queue(rest)
+		close(s.sentences)
+		s.sendMu.Unlock()
+	}
+
+	<-s.done
+	return s.err
+}
+
+// queue hands a sentence to the player, waiting for room in the queue
+// unless the stream's context ends first; s.sendMu must be held
+func (s *Stream) queue(sentence string) {
+	if sentence = strings.TrimSpace(sentence); sentence == "" {
+		return
+	}
+	select {
+	case s.sentences <- sentence:
+	case <-s.ctx.Done():
+	}
+}
+
+// run speaks the queued sentences in order, skipping the rest after an error
+func (s *Stream) run() {
+	defer close(s.done)
+	for sentence := range s.sentences {
+		if s.err != nil || s.ctx.Err() != nil {
+			continue
+		}
+		if err := s.provider.Speak(s.ctx, sentence); err != nil {
+			s.err = err
+		}
+	}
+}
+
+// cutSentence splits the first complete sentence off text. A sentence ends
+// at . ! ? … ; or a line break followed by a space, so decimals and names
+// still being written aren't cut.
+func cutSentence(text string) (sentence, rest string, ok bool) {
+	for i, r := range text {
+		switch r {
+		case '\n':
+			return text[:i], text[i+1:], true
+		case '.', '!', '?', '…', ';':
+			end := i + len(string(r))
+			if end < len(text) && unicode.IsSpace(rune(text[end])) {
+				return text[:end], text[end:], true
+			}
+		}
+	}
+	return "", text, false
+}
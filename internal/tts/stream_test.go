@@ -0,0 +1,126 @@
+package tts
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeProvider records what it speaks; with block set, Speak waits until
+// the context ends
+type fakeProvider struct {
+	block bool
+
+	mu     sync.Mutex
+	spoken []string
+}
+
+func (p *fakeProvider) Speak(ctx context.Context, text string) error {
+	p.mu.Lock()
+	p.spoken = append(p.spoken, text)
+	p.mu.Unlock()
+	if p.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (p *fakeProvider) Name() string                                       { return "fake" }
+func (p *fakeProvider) Synthesize(context.Context, string) ([]byte, error) { return nil, nil }
+func (p *fakeProvider) SetVoice(string) error                              { return nil }
+func (p *fakeProvider) SetSpeed(float64)                                   {}
+func (p *fakeProvider) Stop()                                              {}
+func (p *fakeProvider) IsAvailable(context.Context) bool                   { return true }
+func (p *fakeProvider) Close() error                                       { return nil }
+
+func TestStreamSpeaksSentencesInOrder(t *testing.T) {
+	provider := &fakeProvider{}
+	s := NewStream(context.Background(), provider)
+
+	for _, delta := range []string{"Hola, ", "son las 3.", "5 de la tarde. ¿Algo", " más? Adiós"} {
+		s.Write(delta)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"Hola, son las 3.5 de la tarde.", "¿Algo más?", "Adiós"}
+	if !reflect.DeepEqual(provider.spoken, want) {
+		t.Errorf("spoken %q, want %q", provider.spoken, want)
+	}
+	if got := s.Text(); got != "Hola, son las 3.5 de la tarde. ¿Algo más? Adiós" {
+		t.Errorf("Text() = %q", got)
+	}
+}
+
+func TestStreamFullQueueDoesNotBlockStop(t *testing.T) {
+	provider := &fakeProvider{block: true}
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewStream(ctx, provider)
+
+	// More sentences than the queue holds, while the first one plays
+	var text strings.Builder
+	for i := 0; i < 2*maxQueuedSentences; i++ {
+		fmt.Fprintf(&text, "Frase %d. ", i)
+	}
+	written := make(chan struct{})
+	go func() {
+		s.Write(text.String())
+		close(written)
+	}()
+
+	// The writer gets stuck on the full queue; the text is still readable
+	for deadline := time.Now().Add(time.Second); len(s.sentences) < maxQueuedSentences; {
+		if time.Now().After(deadline) {
+			t.Fatal("queue never filled up")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	textRead := make(chan string)
+	go func() { textRead <- s.Text() }()
+	select {
+	case <-textRead:
+	case <-time.After(time.Second):
+		t.Fatal("Text() blocked behind a full queue")
+	}
+
+	// Stopping the speech releases the writer and Close
+	cancel()
+	closed := make(chan struct{})
+	go func() {
+		s.Close()
+		close(closed)
+	}()
+	for _, ch := range []chan struct{}{written, closed} {
+		select {
+		case <-ch:
+		case <-time.After(time.Second):
+			t.Fatal("stream deadlocked after its context was cancelled")
+		}
+	}
+}
+
+func TestCutSentence(t *testing.T) {
+	tests := []struct {
+		text, sentence, rest string
+		ok                   bool
+	}{
+		{"Hola. Adiós", "Hola.", " Adiós", true},
+		{"Son 3.5 euros", "", "Son 3.5 euros", false},
+		{"¿Qué? Vale", "¿Qué?", " Vale", true},
+		{"Uno\ndos", "Uno", "dos", true},
+		{"Espera…", "", "Espera…", false},
+		{"Espera… ya", "Espera…", " ya", true},
+	}
+	for _, tt := range tests {
+		sentence, rest, ok := cutSentence(tt.text)
+		if sentence != tt.sentence || rest != tt.rest || ok != tt.ok {
+			t.Errorf("cutSentence(%q) = %q, %q, %v; want %q, %q, %v", tt.text, sentence, rest, ok, tt.sentence, tt.rest, tt.ok)
+		}
+	}
+}
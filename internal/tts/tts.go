@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/jarvisstreamer/jarvis/internal/config"
+	"github.com/jarvisstreamer/jarvis/internal/events"
 )
 
 // Provider is the interface for TTS providers
@@ -35,6 +36,19 @@ type Provider interface {
 	Close() error
 }
 
+// speechStarted publishes TTSStarted and returns the function that
+// publishes the matching TTSStopped once playback ends
+func speechStarted(provider, text string) func(err error) {
+	events.Publish(events.TTSStarted{Provider: provider, Text: text})
+	return func(err error) {
+		stopped := events.TTSStopped{Provider: provider}
+		if err != nil {
+			stopped.Error = err.Error()
+		}
+		events.Publish(stopped)
+	}
+}
+
 // New creates a new TTS provider based on configuration
 func New(cfg *config.Config) (Provider, error) {
 	switch cfg.TTS.Provider {
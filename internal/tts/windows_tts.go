@@ -47,7 +47,7 @@ func (w *WindowsTTSProvider) Synthesize(ctx context.Context, text string) ([]byt
 }
 
 // Speak converts text to speech using Windows SAPI and plays it
-func (w *WindowsTTSProvider) Speak(ctx context.Context, text string) error {
+func (w *WindowsTTSProvider) Speak(ctx context.Context, text string) (err error) {
 	w.mu.Lock()
 	if w.isPlaying {
 		w.mu.Unlock()
@@ -64,6 +64,9 @@ func (w *WindowsTTSProvider) Speak(ctx context.Context, text string) error {
 
 	w.log.Debug().Str("text", text).Msg("Speaking text with Windows TTS")
 
+	stopped := speechStarted(w.Name(), text)
+	defer func() { stopped(err) }()
+
 	// Create PowerShell script to speak with Spanish voice
 	script := fmt.Sprintf(`
 Add-Type -AssemblyName System.Speech
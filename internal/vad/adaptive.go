@@ -0,0 +1,222 @@
+package vad
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	frameDuration = 20 * time.Millisecond
+
+	// A frame is voiced when its speech-band energy is this far over the
+	// noise floor: 16 dB at sensitivity 0, 4 dB at sensitivity 1
+	minMargin   = 4.0
+	marginRange = 12.0
+
+	// Speech band; most of a fan's rumble lies below it and most of a
+	// keyboard's click or a hiss above it
+	bandLow  = 300.0
+	bandHigh = 3400.0
+
+	// minBandRatio is the least share of a frame's energy within the speech
+	// band for it to be voiced
+	minBandRatio = 0.25
+
+	// maxCrossings is the most zero crossings per second of a voiced frame;
+	// voiced speech stays well under it, noise and hiss go over it
+	maxCrossings = 5000.0
+
+	// Noise floor tracking, per frame: it falls quickly to quieter audio,
+	// rises steadily to louder noise and barely moves during speech, so a
+	// noise that starts and doesn't stop ends up in the floor too
+	floorFall        = 0.3
+	floorRise        = 0.05
+	floorRiseVoiced  = 0.002
+	minFloor         = 20.0 // dB; digital silence would make any sound voiced
+	attackDuration   = 60 * time.Millisecond
+	hangoverDuration = 300 * time.Millisecond
+)
+
+// Adaptive detects speech frame by frame against a noise floor it keeps
+// learning. A frame is voiced when its speech-band energy stands out from
+// the floor, most of its energy is in the speech band and its zero-crossing
+// rate is that of a voice; speech starts after a few voiced frames in a row,
+// so clicks don't count, and lasts until a hangover of unvoiced ones, so
+// the pauses between words don't end it.
+type Adaptive struct {
+	channels   int
+	sampleRate int
+	frameLen   int
+	margin     float64 // dB over the noise floor
+
+	dcBlock           dcBlocker
+	highPass, lowPass biquad
+	pending           []float64 // Samples of the frame still being filled
+
+	floor    float64 // Speech-band noise floor, dB
+	started  bool    // The floor has been initialized
+	voiced   int     // Voiced frames in a row
+	unvoiced int     // Unvoiced frames in a row
+	speaking bool
+
+	attackFrames   int
+	hangoverFrames int
+}
+
+// NewAdaptive creates an adaptive detector; a higher sensitivity lowers the
+// margin over the noise floor
+func NewAdaptive(sensitivity float64, sampleRate, channels int) *Adaptive {
+	if channels < 1 {
+		channels = 1
+	}
+	high := math.Min(bandHigh, 0.45*float64(sampleRate))
+	return &Adaptive{
+		channels:       channels,
+		sampleRate:     sampleRate,
+		frameLen:       int(frameDuration.Seconds() * float64(sampleRate)),
+		margin:         minMargin + marginRange*(1-sensitivity),
+		highPass:       newHighPass(bandLow, sampleRate),
+		lowPass:        newLowPass(high, sampleRate),
+		attackFrames:   int(attackDuration / frameDuration),
+		hangoverFrames: int(hangoverDuration / frameDuration),
+	}
+}
+
+// Process implements Detector
+func (a *Adaptive) Process(samples []int16) bool {
+	for i := 0; i+a.channels <= len(samples); i += a.channels {
+		var sum float64
+		for c := 0; c < a.channels; c++ {
+			sum += float64(samples[i+c])
+		}
+		a.pending = append(a.pending, sum/float64(a.channels))
+
+		if len(a.pending) == a.frameLen {
+			a.frame(a.pending)
+			a.pending = a.pending[:0]
+		}
+	}
+	return a.speaking
+}
+
+// Reset implements Detector
+func (a *Adaptive) Reset() {
+	a.dcBlock = dcBlocker{}
+	a.highPass.reset()
+	a.lowPass.reset()
+	a.pending = a.pending[:0]
+	a.floor, a.started = 0, false
+	a.voiced, a.unvoiced, a.speaking = 0, 0, false
+}
+
+// frame analyzes one frame and updates the floor and the speech state
+func (a *Adaptive) frame(samples []float64) {
+	var total, band float64
+	crossings := 0
+	prev := 0.0
+	for i, s := range samples {
+		s = a.dcBlock.filter(s)
+		b := a.lowPass.filter(a.highPass.filter(s))
+		total += s * s
+		band += b * b
+		if i > 0 && (s >= 0) != (prev >= 0) {
+			crossings++
+		}
+		prev = s
+	}
+
+	n := float64(len(samples))
+	bandDB := 10 * math.Log10(band/n+1e-10)
+	ratio := band / (total + 1e-10)
+	crossingRate := float64(crossings) / (n / float64(a.sampleRate))
+
+	if !a.started {
+		// Assume the audio starts without speech
+		a.floor = math.Max(bandDB, minFloor)
+		a.started = true
+	}
+
+	voiced := bandDB > a.floor+a.margin && ratio >= minBandRatio && crossingRate <= maxCrossings
+
+	switch {
+	case voiced:
+		a.floor += (bandDB - a.floor) * floorRiseVoiced
+	case bandDB < a.floor:
+		a.floor += (bandDB - a.floor) * floorFall
+	default:
+		a.floor += (bandDB - a.floor) * floorRise
+	}
+	a.floor = math.Max(a.floor, minFloor)
+
+	if voiced {
+		a.voiced++
+		a.unvoiced = 0
+	} else {
+		a.voiced = 0
+		a.unvoiced++
+	}
+	switch {
+	case !a.speaking && a.voiced >= a.attackFrames:
+		a.speaking = true
+	case a.speaking && a.unvoiced >= a.hangoverFrames:
+		a.speaking = false
+	}
+}
+
+// dcBlocker removes the DC offset some microphones add
+type dcBlocker struct {
+	x1, y1 float64
+}
+
+func (d *dcBlocker) filter(x float64) float64 {
+	y := x - d.x1 + 0.995*d.y1
+	d.x1, d.y1 = x, y
+	return y
+}
+
+// biquad is a second-order IIR filter
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+// newHighPass creates a Butterworth high-pass filter
+func newHighPass(cutoff float64, sampleRate int) biquad {
+	w := 2 * math.Pi * cutoff / float64(sampleRate)
+	alpha := math.Sin(w) / math.Sqrt2
+	cos := math.Cos(w)
+	a0 := 1 + alpha
+	return biquad{
+		b0: (1 + cos) / 2 / a0,
+		b1: -(1 + cos) / a0,
+		b2: (1 + cos) / 2 / a0,
+		a1: -2 * cos / a0,
+		a2: (1 - alpha) / a0,
+	}
+}
+
+// newLowPass creates a Butterworth low-pass filter
+func newLowPass(cutoff float64, sampleRate int) biquad {
+	w := 2 * math.Pi * cutoff / float64(sampleRate)
+	alpha := math.Sin(w) / math.Sqrt2
+	cos := math.Cos(w)
+	a0 := 1 + alpha
+	return biquad{
+		b0: (1 - cos) / 2 / a0,
+		b1: (1 - cos) / a0,
+		b2: (1 - cos) / 2 / a0,
+		a1: -2 * cos / a0,
+		a2: (1 - alpha) / a0,
+	}
+}
+
+func (f *biquad) filter(x float64) float64 {
+	y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x2, f.x1 = f.x1, x
+	f.y2, f.y1 = f.y1, y
+	return y
+}
+
+func (f *biquad) reset() {
+	f.x1, f.x2, f.y1, f.y2 = 0, 0, 0, 0
+}
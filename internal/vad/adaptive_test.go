@@ -0,0 +1,171 @@
+package vad
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jarvisstreamer/jarvis/pkg/utils"
+)
+
+// The fixtures in testdata are written by testdata/generate.go
+
+// segment is a stretch of detected speech
+type segment struct{ start, end time.Duration }
+
+func (s segment) String() string { return fmt.Sprintf("%v-%v", s.start, s.end) }
+
+// load reads a 16 kHz mono fixture
+func load(t *testing.T, name string) []int16 {
+	t.Helper()
+	samples, rate, channels, err := utils.ReadWAV(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate != 16000 || channels != 1 {
+		t.Fatalf("%s is %d Hz, %d channels; want 16000 Hz mono", name, rate, channels)
+	}
+	return samples
+}
+
+// detect feeds samples to d in chunks of the given frames and returns the
+// speech segments, timed by the chunk where the decision changed
+func detect(d Detector, samples []int16, chunk, channels int) []segment {
+	var segments []segment
+	speaking := false
+	for i := 0; i < len(samples); i += chunk * channels {
+		end := min(i+chunk*channels, len(samples))
+		at := time.Duration(end/channels) * time.Second / 16000
+		now := d.Process(samples[i:end])
+		switch {
+		case now && !speaking:
+			segments = append(segments, segment{start: at})
+		case !now && speaking:
+			segments[len(segments)-1].end = at
+		}
+		speaking = now
+	}
+	if speaking {
+		segments[len(segments)-1].end = time.Duration(len(samples)/channels) * time.Second / 16000
+	}
+	return segments
+}
+
+// within reports whether d is in [from, to]
+func within(d, from, to time.Duration) bool {
+	return d >= from && d <= to
+}
+
+func TestAdaptiveQuietSpeech(t *testing.T) {
+	got := detect(NewAdaptive(0.5, 16000, 1), load(t, "quiet_speech.wav"), 320, 1)
+
+	// The 40 ms blip is too short to start speech and the 80 ms pause
+	// between syllables too short to end it; speech is detected after the
+	// 60 ms attack and lasts through the 300 ms hangover
+	if len(got) != 1 ||
+		!within(got[0].start, 1040*time.Millisecond, 1100*time.Millisecond) ||
+		!within(got[0].end, 2260*time.Millisecond, 2360*time.Millisecond) {
+		t.Errorf("speech at %v, want one segment from ~1.06s to ~2.3s", got)
+	}
+}
+
+func TestAdaptiveSensitivity(t *testing.T) {
+	// The soft voice is ~8 dB over the room noise: inside the 10 dB margin
+	// of the default sensitivity, outside the 4 dB one of the highest
+	samples := load(t, "soft_speech.wav")
+	for _, tt := range []struct {
+		sensitivity float64
+		segments    int
+	}{{0, 0}, {0.5, 0}, {1, 1}} {
+		if got := detect(NewAdaptive(tt.sensitivity, 16000, 1), samples, 320, 1); len(got) != tt.segments {
+			t.Errorf("sensitivity %v: speech at %v, want %d segments", tt.sensitivity, got, tt.segments)
+		}
+	}
+}
+
+func TestAdaptiveRejectsNoise(t *testing.T) {
+	for _, name := range []string{"fan.wav", "keyboard.wav", "hiss.wav", "noise_rise.wav"} {
+		for _, sensitivity := range []float64{0.5, 1} {
+			if got := detect(NewAdaptive(sensitivity, 16000, 1), load(t, name), 320, 1); len(got) != 0 {
+				t.Errorf("%s at sensitivity %v: speech at %v", name, sensitivity, got)
+			}
+		}
+	}
+}
+
+func TestAdaptiveSpeechOverFan(t *testing.T) {
+	got := detect(NewAdaptive(0.5, 16000, 1), load(t, "fan_speech.wav"), 320, 1)
+	if len(got) != 1 ||
+		!within(got[0].start, 1040*time.Millisecond, 1100*time.Millisecond) ||
+		!within(got[0].end, 2260*time.Millisecond, 2360*time.Millisecond) {
+		t.Errorf("speech at %v, want one segment from ~1.06s to ~2.3s", got)
+	}
+}
+
+func TestAdaptiveNoiseFloorFalls(t *testing.T) {
+	// The loud noise at the start sets a floor far over the quiet speech
+	// that comes later; the floor must have dropped by then
+	got := detect(NewAdaptive(0.5, 16000, 1), load(t, "noise_drop.wav"), 320, 1)
+	if len(got) != 1 || !within(got[0].start, 2040*time.Millisecond, 2100*time.Millisecond) {
+		t.Errorf("speech at %v, want one segment from ~2.06s", got)
+	}
+}
+
+func TestAdaptiveNoiseFloorRises(t *testing.T) {
+	samples := load(t, "noise_rise.wav")
+	a := NewAdaptive(0.5, 16000, 1)
+
+	a.Process(samples[:8000])
+	before := a.floor
+	a.Process(samples[8000:])
+
+	// A fresh detector starts its floor at the level of the first frame
+	loud := NewAdaptive(0.5, 16000, 1)
+	loud.Process(samples[len(samples)-320:])
+
+	// The noise got louder and stayed, so the floor followed it
+	if a.floor-before < 10 || math.Abs(a.floor-loud.floor) > 2 {
+		t.Errorf("floor went from %.1f to %.1f dB, want ~%.1f dB", before, a.floor, loud.floor)
+	}
+}
+
+func TestAdaptiveChunksAndChannels(t *testing.T) {
+	samples := load(t, "quiet_speech.wav")
+	want := detect(NewAdaptive(0.5, 16000, 1), samples, 320, 1)
+
+	// Capture delivers 1024-frame chunks that don't line up with frames
+	got := detect(NewAdaptive(0.5, 16000, 1), samples, 1024, 1)
+	if len(got) != len(want) || !within(got[0].start-want[0].start, -64*time.Millisecond, 64*time.Millisecond) {
+		t.Errorf("1024-frame chunks: speech at %v, want %v", got, want)
+	}
+
+	// Stereo input is mixed down
+	stereo := make([]int16, 2*len(samples))
+	for i, s := range samples {
+		stereo[2*i], stereo[2*i+1] = s, s
+	}
+	if got := detect(NewAdaptive(0.5, 16000, 2), stereo, 320, 2); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("stereo: speech at %v, want %v", got, want)
+	}
+}
+
+func TestAdaptiveReset(t *testing.T) {
+	samples := load(t, "quiet_speech.wav")
+	a := NewAdaptive(0.5, 16000, 1)
+
+	// Cut in the middle of speech
+	if !a.Process(samples[:1800*16]) {
+		t.Fatal("no speech at 1.8s")
+	}
+	a.Reset()
+	if a.Process(nil) {
+		t.Error("still speaking after Reset")
+	}
+
+	want := detect(NewAdaptive(0.5, 16000, 1), samples, 320, 1)
+	if got := detect(a, samples, 320, 1); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("after Reset: speech at %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,194 @@
+//go:build ignore
+
+// Generate writes the synthetic 16 kHz mono recordings the VAD tests use.
+// Run it from internal/vad with: go run testdata/generate.go
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+
+	"github.com/jarvisstreamer/jarvis/pkg/utils"
+)
+
+const sampleRate = 16000
+
+// span is a stretch of a recording, in seconds
+type span struct{ from, to float64 }
+
+func main() {
+	rng := rand.New(rand.NewSource(1))
+
+	fixtures := []struct {
+		name    string
+		seconds float64
+		parts   func(out []float64)
+	}{
+		// Quiet voice in a quiet room: a 40 ms blip at 0.5 s, then two
+		// syllables 1.0-1.4 s and 1.48-2.0 s
+		{"quiet_speech.wav", 3, func(out []float64) {
+			add(out, room(rng, len(out), 30), span{0, 3})
+			add(out, voice(len(out), 300), span{0.5, 0.54}, span{1.0, 1.4}, span{1.48, 2.0})
+		}},
+		// A voice barely over the room noise, 1.0-2.0 s
+		{"soft_speech.wav", 3, func(out []float64) {
+			add(out, room(rng, len(out), 30), span{0, 3})
+			add(out, voice(len(out), 60), span{1.0, 2.0})
+		}},
+		// Loud noise that stops at 1 s, then quiet speech 2.0-2.6 s
+		{"noise_drop.wav", 3, func(out []float64) {
+			add(out, room(rng, len(out), 30), span{0, 3})
+			add(out, room(rng, len(out), 1500), span{0, 1})
+			add(out, voice(len(out), 300), span{2.0, 2.6})
+		}},
+		// Room noise that turns ten times louder at 0.5 s and stays
+		{"noise_rise.wav", 3, func(out []float64) {
+			add(out, room(rng, len(out), 30), span{0, 3})
+			add(out, noise(rng, len(out), 300), span{0.5, 3})
+		}},
+		// A fan switched on at 0.5 s
+		{"fan.wav", 3, func(out []float64) {
+			add(out, room(rng, len(out), 30), span{0, 3})
+			add(out, fan(rng, len(out), 3000), span{0.5, 3})
+		}},
+		// Normal speech 1.0-2.0 s over a running fan
+		{"fan_speech.wav", 3, func(out []float64) {
+			add(out, room(rng, len(out), 30), span{0, 3})
+			add(out, fan(rng, len(out), 1000), span{0, 3})
+			add(out, voice(len(out), 1500), span{1.0, 2.0})
+		}},
+		// Typing from 0.5 s: sharp clicks every 60-200 ms
+		{"keyboard.wav", 3, func(out []float64) {
+			add(out, room(rng, len(out), 30), span{0, 3})
+			for t := 0.5; t < 2.9; t += 0.06 + 0.14*rng.Float64() {
+				click(rng, out, int(t*sampleRate), 10000)
+			}
+		}},
+		// Loud hiss from 0.5 s
+		{"hiss.wav", 3, func(out []float64) {
+			add(out, room(rng, len(out), 30), span{0, 3})
+			add(out, hiss(rng, len(out), 2000), span{0.5, 3})
+		}},
+	}
+
+	for _, f := range fixtures {
+		out := make([]float64, int(f.seconds*sampleRate))
+		f.parts(out)
+
+		samples := make([]int16, len(out))
+		for i, s := range out {
+			samples[i] = int16(math.Max(math.MinInt16, math.Min(math.MaxInt16, math.Round(s))))
+		}
+		path := filepath.Join("testdata", f.name)
+		if err := utils.SaveWAV(path, utils.Int16ToBytes(samples), sampleRate, 1, 16); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+}
+
+// add mixes src into out over the given spans, fading each in and out
+// over 10 ms
+func add(out, src []float64, spans ...span) {
+	const fade = 0.01 * sampleRate
+	for _, s := range spans {
+		from, to := int(s.from*sampleRate), int(s.to*sampleRate)
+		for i := from; i < to; i++ {
+			gain := math.Min(1, math.Min(float64(i-from), float64(to-1-i))/fade)
+			out[i] += gain * src[i]
+		}
+	}
+}
+
+// room is the background of a quiet room: noise that fades above 1 kHz,
+// at the given RMS
+func room(rng *rand.Rand, n int, rms float64) []float64 {
+	out := make([]float64, n)
+	lp := 0.0
+	for i := range out {
+		lp += 0.33 * (rng.NormFloat64() - lp) // ~1 kHz one-pole low-pass
+		out[i] = lp
+	}
+	return scale(out, rms)
+}
+
+// noise is white noise with the given RMS
+func noise(rng *rand.Rand, n int, rms float64) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = rng.NormFloat64() * rms
+	}
+	return out
+}
+
+// voice is a vowel-like harmonic sound: a gliding 110-130 Hz pitch with
+// its harmonics falling off with frequency and shaped by two formants, at
+// the given RMS
+func voice(n int, rms float64) []float64 {
+	out := make([]float64, n)
+	phase := make([]float64, 40)
+	for i := range out {
+		t := float64(i) / sampleRate
+		f0 := 120 + 10*math.Sin(2*math.Pi*1.5*t)
+		for k := 1; k <= len(phase); k++ {
+			f := float64(k) * f0
+			if f > 3400 {
+				break
+			}
+			phase[k-1] += 2 * math.Pi * f / sampleRate
+			amp := 1/(1+math.Pow((f-600)/150, 2)) + 0.5/(1+math.Pow((f-1100)/200, 2)) + 24/f
+			out[i] += amp * math.Sin(phase[k-1])
+		}
+	}
+	return scale(out, rms)
+}
+
+// fan is a low rumble: mains hum and its harmonics over low-passed noise
+func fan(rng *rand.Rand, n int, rms float64) []float64 {
+	out := make([]float64, n)
+	lp1, lp2 := 0.0, 0.0
+	for i := range out {
+		t := float64(i) / sampleRate
+		lp1 += 0.03 * (rng.NormFloat64() - lp1) // Two ~80 Hz one-pole low-passes
+		lp2 += 0.03 * (lp1 - lp2)
+		out[i] = math.Sin(2*math.Pi*50*t) + 0.5*math.Sin(2*math.Pi*100*t) +
+			0.25*math.Sin(2*math.Pi*150*t) + 20*lp2
+	}
+	return scale(out, rms)
+}
+
+// hiss is white noise with the low end removed
+func hiss(rng *rand.Rand, n int, rms float64) []float64 {
+	out := make([]float64, n)
+	prev, y := 0.0, 0.0
+	for i := range out {
+		x := rng.NormFloat64()
+		y = 0.2 * (y + x - prev) // One-pole high-pass, ~4 kHz
+		prev = x
+		out[i] = y
+	}
+	return scale(out, rms)
+}
+
+// click adds a 3 ms decaying burst of noise at the given position
+func click(rng *rand.Rand, out []float64, at int, peak float64) {
+	for i := 0; i < 3*sampleRate/1000 && at+i < len(out); i++ {
+		out[at+i] += peak * rng.NormFloat64() * math.Exp(-float64(i)/16)
+	}
+}
+
+// scale sets the RMS of samples
+func scale(samples []float64, rms float64) []float64 {
+	var sum float64
+	for _, s := range samples {
+		sum += s * s
+	}
+	gain := rms / math.Sqrt(sum/float64(len(samples)))
+	for i := range samples {
+		samples[i] *= gain
+	}
+	return samples
+}
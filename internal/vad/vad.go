@@ -0,0 +1,56 @@
+// Package vad provides voice activity detection: it tells whether a chunk
+// of microphone audio holds speech, so recordings start and end on voice
+package vad
+
+import (
+	"fmt"
+
+	"github.com/jarvisstreamer/jarvis/internal/config"
+	"github.com/jarvisstreamer/jarvis/pkg/utils"
+)
+
+// Detector decides whether the audio fed to it holds speech. Chunks must be
+// fed in order, since detectors keep state between them. Implementations
+// are not safe for concurrent use.
+type Detector interface {
+	// Process analyzes a chunk of interleaved 16-bit samples and returns
+	// whether speech is going on at its end
+	Process(samples []int16) bool
+
+	// Reset forgets everything learned from the audio fed so far
+	Reset()
+}
+
+// New creates the detector selected by cfg.Detector for audio with the
+// given format
+func New(cfg config.VADConfig, sampleRate, channels int) (Detector, error) {
+	switch cfg.Detector {
+	case "adaptive", "":
+		return NewAdaptive(cfg.Sensitivity, sampleRate, channels), nil
+	case "energy":
+		return NewEnergy(cfg.Sensitivity), nil
+	default:
+		return nil, fmt.Errorf("unknown VAD detector: %s", cfg.Detector)
+	}
+}
+
+// Energy is the simplest detector: a chunk is speech when its power is over
+// a fixed threshold. It reacts to any loud noise and misses quiet speech,
+// but needs no adaptation time.
+type Energy struct {
+	threshold float64
+}
+
+// NewEnergy creates an energy detector; a higher sensitivity lowers the
+// threshold
+func NewEnergy(sensitivity float64) *Energy {
+	return &Energy{threshold: 500 * (1 - sensitivity)}
+}
+
+// Process implements Detector
+func (e *Energy) Process(samples []int16) bool {
+	return utils.CalculateRMS(samples) > e.threshold
+}
+
+// Reset implements Detector
+func (e *Energy) Reset() {}
@@ -0,0 +1,67 @@
+package vad
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jarvisstreamer/jarvis/internal/config"
+)
+
+func TestNew(t *testing.T) {
+	for _, tt := range []struct {
+		detector string
+		want     string
+	}{
+		{"", "*vad.Adaptive"},
+		{"adaptive", "*vad.Adaptive"},
+		{"energy", "*vad.Energy"},
+	} {
+		d, err := New(config.VADConfig{Detector: tt.detector, Sensitivity: 0.5}, 16000, 1)
+		if err != nil {
+			t.Errorf("New(%q): %v", tt.detector, err)
+			continue
+		}
+		if got := fmt.Sprintf("%T", d); got != tt.want {
+			t.Errorf("New(%q) = %s, want %s", tt.detector, got, tt.want)
+		}
+	}
+
+	if _, err := New(config.VADConfig{Detector: "webrtc"}, 16000, 1); err == nil {
+		t.Error("New accepted an unknown detector")
+	}
+}
+
+func TestEnergy(t *testing.T) {
+	// Square waves, whose mean square is the amplitude squared
+	square := func(amplitude int16) []int16 {
+		samples := make([]int16, 320)
+		for i := range samples {
+			if i%2 == 0 {
+				samples[i] = amplitude
+			} else {
+				samples[i] = -amplitude
+			}
+		}
+		return samples
+	}
+
+	e := NewEnergy(0.5) // Threshold 250
+	if e.Process(make([]int16, 320)) {
+		t.Error("silence detected as speech")
+	}
+	if e.Process(square(15)) {
+		t.Error("mean square 225 detected as speech")
+	}
+	if !e.Process(square(16)) {
+		t.Error("mean square 256 not detected as speech")
+	}
+
+	// Sensitivity lowers the threshold; the decision has no memory
+	e = NewEnergy(0.9) // Threshold 50
+	if !e.Process(square(8)) {
+		t.Error("mean square 64 not detected at sensitivity 0.9")
+	}
+	if e.Process(nil) {
+		t.Error("empty chunk detected as speech")
+	}
+}
@@ -0,0 +1,71 @@
+package wakeword
+
+import "math"
+
+// frameDistance is the Euclidean distance between two MFCC frames
+func frameDistance(a, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		d := a[i] - b[i]
+		sum += d * d
+	}
+	return math.Sqrt(sum)
+}
+
+// subsequenceDTW aligns the whole template with the best matching stretch
+// of the window, which may start and end anywhere, and returns the
+// alignment cost per template frame
+func subsequenceDTW(template, window [][]float64) float64 {
+	if len(template) == 0 || len(window) == 0 {
+		return math.Inf(1)
+	}
+
+	// prev and cur are columns over the template, one per window frame
+	prev := make([]float64, len(template))
+	cur := make([]float64, len(template))
+	best := math.Inf(1)
+	for j, frame := range window {
+		for i := range template {
+			d := frameDistance(template[i], frame)
+			switch {
+			case i == 0:
+				cur[i] = d // Free start anywhere in the window
+			case j == 0:
+				cur[i] = cur[i-1] + d
+			default:
+				cur[i] = d + math.Min(prev[i-1], math.Min(prev[i], cur[i-1]))
+			}
+		}
+		best = math.Min(best, cur[len(template)-1]) // Free end
+		prev, cur = cur, prev
+	}
+	return best / float64(len(template))
+}
+
+// dtw aligns two whole sequences and returns the cost per frame of the
+// longer one
+func dtw(a, b [][]float64) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return math.Inf(1)
+	}
+
+	prev := make([]float64, len(a))
+	cur := make([]float64, len(a))
+	for j, frame := range b {
+		for i := range a {
+			d := frameDistance(a[i], frame)
+			switch {
+			case i == 0 && j == 0:
+				cur[i] = d
+			case i == 0:
+				cur[i] = prev[i] + d
+			case j == 0:
+				cur[i] = cur[i-1] + d
+			default:
+				cur[i] = d + math.Min(prev[i-1], math.Min(prev[i], cur[i-1]))
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(a)-1] / float64(max(len(a), len(b)))
+}
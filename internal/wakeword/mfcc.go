@@ -0,0 +1,166 @@
+package wakeword
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+const (
+	frameDuration = 0.025 // Analysis window, seconds
+	hopDuration   = 0.010 // Step between frames, seconds
+	numFilters    = 26    // Mel filterbank bands
+	numCoeffs     = 12    // Cepstral coefficients kept (c1..c12; c0 is loudness)
+	preEmphasis   = 0.97
+
+	// dynamicRange floors the mel energies this far below the loudest band
+	// of the analyzed audio, so background noise looks the same whatever
+	// its level and only the speech above it is compared
+	dynamicRange = 20.0 // dB
+)
+
+// features computes MFCC frames for one sample rate
+type features struct {
+	frameLen int
+	hop      int
+	nfft     int
+	window   []float64   // Hamming window
+	filters  [][]float64 // Mel filterbank over the FFT bins
+	dct      [][]float64 // DCT-II basis, numCoeffs x numFilters
+}
+
+// newFeatures precomputes the window, filterbank and DCT for a sample rate
+func newFeatures(sampleRate int) *features {
+	f := &features{
+		frameLen: int(frameDuration * float64(sampleRate)),
+		hop:      int(hopDuration * float64(sampleRate)),
+	}
+	f.nfft = 1
+	for f.nfft < f.frameLen {
+		f.nfft <<= 1
+	}
+
+	f.window = make([]float64, f.frameLen)
+	for i := range f.window {
+		f.window[i] = 0.54 - 0.46*math.Cos(2*math.Pi*float64(i)/float64(f.frameLen-1))
+	}
+
+	// Triangular filters evenly spaced on the mel scale, up to 8 kHz or Nyquist
+	low, high := hzToMel(60), hzToMel(math.Min(8000, float64(sampleRate)/2))
+	bins := make([]int, numFilters+2)
+	for i := range bins {
+		hz := melToHz(low + (high-low)*float64(i)/float64(numFilters+1))
+		bins[i] = int(math.Floor(float64(f.nfft+1) * hz / float64(sampleRate)))
+	}
+	f.filters = make([][]float64, numFilters)
+	for m := 1; m <= numFilters; m++ {
+		filter := make([]float64, f.nfft/2+1)
+		for k := bins[m-1]; k < bins[m]; k++ {
+			filter[k] = float64(k-bins[m-1]) / float64(bins[m]-bins[m-1])
+		}
+		for k := bins[m]; k < bins[m+1]; k++ {
+			filter[k] = float64(bins[m+1]-k) / float64(bins[m+1]-bins[m])
+		}
+		f.filters[m-1] = filter
+	}
+
+	f.dct = make([][]float64, numCoeffs)
+	for c := range f.dct {
+		f.dct[c] = make([]float64, numFilters)
+		for m := range f.dct[c] {
+			f.dct[c][m] = math.Cos(math.Pi * float64(c+1) * (float64(m) + 0.5) / numFilters)
+		}
+	}
+
+	return f
+}
+
+// compute returns the MFCC frames of mono samples, and the log energy of each frame
+func (f *features) compute(samples []float64) (frames [][]float64, energies []float64) {
+	if len(samples) < f.frameLen {
+		return nil, nil
+	}
+
+	var mels [][]float64
+	peak := 0.0
+	buf := make([]complex128, f.nfft)
+	power := make([]float64, f.nfft/2+1)
+	for start := 0; start+f.frameLen <= len(samples); start += f.hop {
+		var energy float64
+		for i := 0; i < f.nfft; i++ {
+			if i >= f.frameLen {
+				buf[i] = 0
+				continue
+			}
+			s := samples[start+i]
+			if start+i > 0 {
+				s -= preEmphasis * samples[start+i-1]
+			}
+			energy += samples[start+i] * samples[start+i]
+			buf[i] = complex(s*f.window[i], 0)
+		}
+		fft(buf)
+		for k := range power {
+			a := cmplx.Abs(buf[k])
+			power[k] = a * a / float64(f.nfft)
+		}
+
+		mel := make([]float64, numFilters)
+		for m, filter := range f.filters {
+			for k, w := range filter {
+				mel[m] += w * power[k]
+			}
+			peak = math.Max(peak, mel[m])
+		}
+
+		mels = append(mels, mel)
+		energies = append(energies, 10*math.Log10(energy/float64(f.frameLen)+1e-10))
+	}
+
+	floor := peak*math.Pow(10, -dynamicRange/10) + 1e-10
+	for _, mel := range mels {
+		coeffs := make([]float64, numCoeffs)
+		for c, basis := range f.dct {
+			for m, b := range basis {
+				coeffs[c] += b * math.Log(math.Max(mel[m], floor))
+			}
+		}
+		frames = append(frames, coeffs)
+	}
+	return frames, energies
+}
+
+// fft is an in-place iterative radix-2 FFT; len(x) must be a power of two
+func fft(x []complex128) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				even, odd := x[start+k], x[start+k+size/2]*w
+				x[start+k] = even + odd
+				x[start+k+size/2] = even - odd
+				w *= step
+			}
+		}
+	}
+}
+
+func hzToMel(hz float64) float64 {
+	return 2595 * math.Log10(1+hz/700)
+}
+
+func melToHz(mel float64) float64 {
+	return 700 * (math.Pow(10, mel/2595) - 1)
+}
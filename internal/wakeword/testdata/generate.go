@@ -0,0 +1,222 @@
+//go:build ignore
+
+// Generate writes the synthetic 16 kHz mono recordings the wake word tests
+// use: three enrolled utterances of a made-up "jarvis" and recordings
+// where it, or a different word, is said over room noise.
+// Run it from internal/wakeword with: go run testdata/generate.go
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+
+	"github.com/jarvisstreamer/jarvis/internal/wakeword"
+	"github.com/jarvisstreamer/jarvis/pkg/utils"
+)
+
+const sampleRate = 16000
+
+// vowel is the pair of formants, in Hz, that makes a vowel
+type vowel struct{ f1, f2 float64 }
+
+var (
+	vowelA = vowel{750, 1250}
+	vowelI = vowel{300, 2300}
+	vowelO = vowel{500, 900}
+	vowelU = vowel{320, 800}
+)
+
+// key is a point of a word: the vowel and loudness at a time, in seconds
+// from its start; the voice glides between keys
+type key struct {
+	at    float64
+	vowel vowel
+	gain  float64
+}
+
+// word is the voiced keys of a word and the stretch, in seconds, of its
+// final hiss, if any
+type word struct {
+	keys       []key
+	hissFrom   float64
+	hissTo     float64
+	hissVolume float64
+}
+
+var (
+	// "ya-ar-vis": /i/ gliding to /a/, a dip for the "v", /i/ and an "s"
+	jarvis = word{
+		keys: []key{
+			{0, vowelI, 0}, {0.04, vowelI, 1}, {0.14, vowelA, 1}, {0.30, vowelA, 1},
+			{0.35, vowelA, 0.1}, {0.40, vowelI, 0.1}, {0.44, vowelI, 0.8}, {0.54, vowelI, 0.8}, {0.57, vowelI, 0},
+		},
+		hissFrom: 0.55, hissTo: 0.72, hissVolume: 0.5,
+	}
+	// "o-u-a": rounded vowels opening to /a/, no hiss
+	other = word{
+		keys: []key{
+			{0, vowelO, 0}, {0.04, vowelO, 1}, {0.22, vowelO, 1}, {0.30, vowelU, 0.6},
+			{0.38, vowelA, 1}, {0.62, vowelA, 1}, {0.68, vowelA, 0},
+		},
+	}
+)
+
+// utterance is how one recording says a word
+type utterance struct {
+	tempo float64 // Duration relative to the keys
+	pitch float64 // Hz
+}
+
+func main() {
+	rng := rand.New(rand.NewSource(1))
+
+	// Enroll three utterances the way "jarvis wakeword enroll" does
+	dir := wakeword.Dir("testdata", "jarvis")
+	if err := os.RemoveAll(dir); err != nil {
+		fail(err)
+	}
+	for _, u := range []utterance{{0.7, 120}, {0.63, 135}, {0.77, 110}} {
+		out := room(rng, sampleRate, 30)
+		say(out, jarvis, u, 0.2, 1500)
+		if _, err := wakeword.Enroll("testdata", "jarvis", toInt16(out), sampleRate, 1); err != nil {
+			fail(err)
+		}
+	}
+
+	fixtures := []struct {
+		name    string
+		seconds float64
+		parts   func(out []float64)
+	}{
+		// A fourth utterance of the word at 1.0 s
+		{"jarvis.wav", 3, func(out []float64) {
+			say(out, jarvis, utterance{0.73, 125}, 1.0, 1500)
+		}},
+		// A different word of the same length at 1.0 s
+		{"other.wav", 3, func(out []float64) {
+			say(out, other, utterance{0.7, 125}, 1.0, 1500)
+		}},
+		// The word at 0.5 s, again at 1.1 s, inside the cooldown of the
+		// first detection, and once more at 3.0 s
+		{"jarvis_repeated.wav", 4.5, func(out []float64) {
+			say(out, jarvis, utterance{0.7, 125}, 0.5, 1500)
+			say(out, jarvis, utterance{0.67, 130}, 1.1, 1500)
+			say(out, jarvis, utterance{0.73, 120}, 3.0, 1500)
+		}},
+	}
+
+	for _, f := range fixtures {
+		out := room(rng, int(f.seconds*sampleRate), 30)
+		f.parts(out)
+		path := filepath.Join("testdata", f.name)
+		if err := utils.SaveWAV(path, utils.Int16ToBytes(toInt16(out)), sampleRate, 1, 16); err != nil {
+			fail(err)
+		}
+	}
+}
+
+// say mixes an utterance of w into out, starting at the given second,
+// with its voiced part at the given RMS
+func say(out []float64, w word, u utterance, at, rms float64) {
+	last := w.keys[len(w.keys)-1].at
+	n := int(max(last, w.hissTo) * u.tempo * sampleRate)
+	voiced := make([]float64, n)
+	phase := make([]float64, 40)
+	for i := range voiced {
+		t := float64(i) / sampleRate / u.tempo
+		if t > last {
+			break
+		}
+		v, gain := interpolate(w.keys, t)
+		f0 := u.pitch * (1 + 0.05*math.Sin(2*math.Pi*2*t))
+		for k := 1; k <= len(phase); k++ {
+			f := float64(k) * f0
+			if f > 4000 {
+				break
+			}
+			phase[k-1] += 2 * math.Pi * f / sampleRate
+			amp := 1/(1+math.Pow((f-v.f1)/100, 2)) + 0.6/(1+math.Pow((f-v.f2)/150, 2)) + 12/f
+			voiced[i] += gain * amp * math.Sin(phase[k-1])
+		}
+	}
+	scale(voiced, rms)
+
+	// The hiss: fixed-seed high-passed noise, so every utterance has the same "s"
+	if w.hissTo > w.hissFrom {
+		rng := rand.New(rand.NewSource(2))
+		from, to := int(w.hissFrom*u.tempo*sampleRate), int(w.hissTo*u.tempo*sampleRate)
+		prev, y := 0.0, 0.0
+		for i := from; i < to; i++ {
+			x := rng.NormFloat64()
+			y = 0.3 * (y + x - prev)
+			prev = x
+			fade := math.Min(1, math.Min(float64(i-from), float64(to-1-i))/(0.01*sampleRate))
+			voiced[i] += fade * w.hissVolume * rms * 2 * y
+		}
+	}
+
+	start := int(at * sampleRate)
+	for i, s := range voiced {
+		if start+i < len(out) {
+			out[start+i] += s
+		}
+	}
+}
+
+// interpolate returns the vowel and gain of a word at time t
+func interpolate(keys []key, t float64) (vowel, float64) {
+	for i := 1; i < len(keys); i++ {
+		a, b := keys[i-1], keys[i]
+		if t <= b.at {
+			x := (t - a.at) / (b.at - a.at)
+			return vowel{
+				f1: a.vowel.f1 + x*(b.vowel.f1-a.vowel.f1),
+				f2: a.vowel.f2 + x*(b.vowel.f2-a.vowel.f2),
+			}, a.gain + x*(b.gain-a.gain)
+		}
+	}
+	last := keys[len(keys)-1]
+	return last.vowel, last.gain
+}
+
+// room is the background of a quiet room: noise that fades above 1 kHz,
+// at the given RMS
+func room(rng *rand.Rand, n int, rms float64) []float64 {
+	out := make([]float64, n)
+	lp := 0.0
+	for i := range out {
+		lp += 0.33 * (rng.NormFloat64() - lp) // ~1 kHz one-pole low-pass
+		out[i] = lp
+	}
+	return scale(out, rms)
+}
+
+// scale sets the RMS of samples
+func scale(samples []float64, rms float64) []float64 {
+	var sum float64
+	for _, s := range samples {
+		sum += s * s
+	}
+	gain := rms / math.Sqrt(sum/float64(len(samples)))
+	for i := range samples {
+		samples[i] *= gain
+	}
+	return samples
+}
+
+// toInt16 rounds and clips samples to 16 bits
+func toInt16(samples []float64) []int16 {
+	out := make([]int16, len(samples))
+	for i, s := range samples {
+		out[i] = int16(math.Max(math.MinInt16, math.Min(math.MaxInt16, math.Round(s))))
+	}
+	return out
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
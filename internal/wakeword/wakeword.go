@@ -0,0 +1,283 @@
+// Package wakeword spots the configured wake word in the live microphone
+// audio. It runs on the CPU without any model: the user enrolls a few
+// recordings of the word, and the rolling audio window is compared against
+// them by aligning their MFCC features with dynamic time warping.
+package wakeword
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/jarvisstreamer/jarvis/internal/config"
+	"github.com/jarvisstreamer/jarvis/pkg/utils"
+)
+
+const (
+	// MinSamples is how many enrolled recordings Load needs
+	MinSamples = 2
+
+	minWordDuration = 0.25 // Seconds; shorter recordings are noise or clicks
+	maxWordDuration = 2.0  // Seconds; longer ones are a sentence, not a word
+
+	trimBelowPeak = 25.0 // dB under the loudest frame considered silence when enrolling
+	trimPadding   = 2    // Frames of silence kept around an enrolled word
+
+	checkInterval  = 0.1  // Seconds of audio between two checks of the window
+	windowMargin   = 1.25 // Window length relative to the longest template
+	minEnergyRange = 10.0 // dB between the quietest and loudest frame for a window to hold speech
+	cooldown       = 1.0  // Seconds after a detection during which nothing fires
+)
+
+// Dir returns the folder holding the enrolled recordings of a word
+func Dir(dataDir, word string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(word)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		case unicode.IsSpace(r) || r == '-' || r == '_':
+			b.WriteRune('_')
+		}
+	}
+	return filepath.Join(dataDir, "wakeword", b.String())
+}
+
+// Enroll trims the silence around a recording of the wake word and saves
+// it as a new template. It returns the path of the saved file.
+func Enroll(dataDir, word string, samples []int16, sampleRate, channels int) (string, error) {
+	mono := downmix(samples, channels)
+	feats := newFeatures(sampleRate)
+	_, energies := feats.compute(mono)
+	start, end, ok := speechBounds(energies)
+	if !ok {
+		return "", fmt.Errorf("recording has no speech")
+	}
+
+	from := max(0, start-trimPadding) * feats.hop
+	to := min(len(mono), (end+trimPadding)*feats.hop+feats.frameLen)
+	duration := float64(to-from) / float64(sampleRate)
+	if duration < minWordDuration {
+		return "", fmt.Errorf("word too short (%.2fs), say it clearly", duration)
+	}
+	if duration > maxWordDuration {
+		return "", fmt.Errorf("recording too long (%.2fs), say only the wake word", duration)
+	}
+
+	dir := Dir(dataDir, word)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create wake word folder: %w", err)
+	}
+	path := nextSamplePath(dir)
+
+	trimmed := make([]int16, to-from)
+	for i, s := range mono[from:to] {
+		trimmed[i] = int16(s)
+	}
+	if err := utils.SaveWAV(path, utils.Int16ToBytes(trimmed), sampleRate, 1, 16); err != nil {
+		return "", fmt.Errorf("failed to save wake word sample: %w", err)
+	}
+	return path, nil
+}
+
+// Samples returns the enrolled recordings of a word
+func Samples(dataDir, word string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(Dir(dataDir, word), "sample_*.wav"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// nextSamplePath returns the first free sample_N.wav in dir
+func nextSamplePath(dir string) string {
+	for n := 1; ; n++ {
+		path := filepath.Join(dir, fmt.Sprintf("sample_%d.wav", n))
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return path
+		}
+	}
+}
+
+// Detector spots the wake word in a stream of audio chunks. It is not safe
+// for concurrent use.
+type Detector struct {
+	word       string
+	threshold  float64
+	sampleRate int
+	channels   int
+
+	feats     *features
+	templates [][][]float64
+	scale     float64 // Typical distance between two utterances of the word
+
+	window     []float64 // Latest mono samples
+	maxWindow  int
+	step       int // Samples between two checks
+	sinceCheck int
+	quiet      int // Samples left before the detector may fire again
+}
+
+// Load builds a detector from the recordings enrolled for cfg.Word. The
+// audio fed to it must have the given sample rate and channel count.
+func Load(cfg config.WakeWordConfig, dataDir string, sampleRate, channels int) (*Detector, error) {
+	paths, err := Samples(dataDir, cfg.Word)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list wake word samples: %w", err)
+	}
+	if len(paths) < MinSamples {
+		return nil, fmt.Errorf("wake word %q needs at least %d enrolled samples in %s, found %d",
+			cfg.Word, MinSamples, Dir(dataDir, cfg.Word), len(paths))
+	}
+
+	d := &Detector{
+		word:       cfg.Word,
+		threshold:  cfg.Threshold,
+		sampleRate: sampleRate,
+		channels:   channels,
+		feats:      newFeatures(sampleRate),
+		step:       int(checkInterval * float64(sampleRate)),
+	}
+
+	longest := 0
+	for _, path := range paths {
+		samples, rate, ch, err := utils.ReadWAV(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		samples = utils.Resample(samples, rate, sampleRate, ch)
+		frames, _ := d.feats.compute(downmix(samples, ch))
+		if len(frames) == 0 {
+			return nil, fmt.Errorf("%s is too short", path)
+		}
+		d.templates = append(d.templates, frames)
+		longest = max(longest, len(frames))
+	}
+
+	// Utterances of the word are as far from each other as the templates
+	var sum float64
+	pairs := 0
+	for i := range d.templates {
+		for j := i + 1; j < len(d.templates); j++ {
+			sum += dtw(d.templates[i], d.templates[j])
+			pairs++
+		}
+	}
+	d.scale = sum / float64(pairs)
+	if d.scale == 0 {
+		return nil, fmt.Errorf("enrolled samples of %q are identical, record them separately", cfg.Word)
+	}
+
+	d.maxWindow = int(float64(longest)*windowMargin)*d.feats.hop + d.feats.frameLen
+	return d, nil
+}
+
+// Word returns the wake word the detector spots
+func (d *Detector) Word() string {
+	return d.word
+}
+
+// Feed adds an audio chunk to the rolling window and returns true when the
+// wake word was just spoken
+func (d *Detector) Feed(samples []int16) bool {
+	mono := downmix(samples, d.channels)
+	d.window = append(d.window, mono...)
+	if extra := len(d.window) - d.maxWindow; extra > 0 {
+		d.window = append(d.window[:0], d.window[extra:]...)
+	}
+
+	if d.quiet > 0 {
+		d.quiet -= len(mono)
+		return false
+	}
+	d.sinceCheck += len(mono)
+	if d.sinceCheck < d.step || len(d.window) < d.maxWindow {
+		return false
+	}
+	d.sinceCheck = 0
+
+	if d.score(d.window) < d.threshold {
+		return false
+	}
+
+	// Don't fire again on the same utterance
+	d.Reset()
+	d.quiet = int(cooldown * float64(d.sampleRate))
+	return true
+}
+
+// Reset forgets the buffered audio, e.g. after the pipeline recorded it
+func (d *Detector) Reset() {
+	d.window = d.window[:0]
+	d.sinceCheck = 0
+}
+
+// Score returns how closely the best matching stretch of a recording at
+// the detector's sample rate resembles the enrolled word, from 0 to 1
+func (d *Detector) Score(samples []int16, channels int) float64 {
+	return d.score(downmix(samples, channels))
+}
+
+// score scores mono audio
+func (d *Detector) score(mono []float64) float64 {
+	frames, energies := d.feats.compute(mono)
+	if len(frames) == 0 {
+		return 0
+	}
+
+	// Skip windows without speech onset: steady noise or silence
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, e := range energies {
+		low, high = math.Min(low, e), math.Max(high, e)
+	}
+	if high-low < minEnergyRange {
+		return 0
+	}
+
+	best := math.Inf(1)
+	for _, t := range d.templates {
+		best = math.Min(best, subsequenceDTW(t, frames))
+	}
+	return math.Min(1, d.scale/best)
+}
+
+// speechBounds returns the first and last frame within trimBelowPeak of the
+// loudest one
+func speechBounds(energies []float64) (start, end int, ok bool) {
+	if len(energies) == 0 {
+		return 0, 0, false
+	}
+	peak := math.Inf(-1)
+	for _, e := range energies {
+		peak = math.Max(peak, e)
+	}
+	if peak < 20 { // Digital silence
+		return 0, 0, false
+	}
+
+	start, end = -1, -1
+	for i, e := range energies {
+		if e >= peak-trimBelowPeak {
+			if start < 0 {
+				start = i
+			}
+			end = i
+		}
+	}
+	return start, end, true
+}
+
+// downmix averages interleaved channels into mono samples
+func downmix(samples []int16, channels int) []float64 {
+	mono := utils.Downmix(samples, channels)
+	out := make([]float64, len(mono))
+	for i, s := range mono {
+		out[i] = float64(s)
+	}
+	return out
+}
@@ -0,0 +1,223 @@
+package wakeword
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarvisstreamer/jarvis/internal/config"
+	"github.com/jarvisstreamer/jarvis/pkg/utils"
+)
+
+// The fixtures in testdata are written by testdata/generate.go; the
+// enrolled "jarvis" samples are in testdata/wakeword/jarvis
+
+var testConfig = config.WakeWordConfig{Word: "jarvis", Threshold: 0.7}
+
+// load reads a 16 kHz mono fixture
+func load(t *testing.T, name string) []int16 {
+	t.Helper()
+	samples, rate, channels, err := utils.ReadWAV(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate != 16000 || channels != 1 {
+		t.Fatalf("%s is %d Hz, %d channels; want 16000 Hz mono", name, rate, channels)
+	}
+	return samples
+}
+
+// loadDetector builds a detector from the enrolled fixtures
+func loadDetector(t *testing.T, channels int) *Detector {
+	t.Helper()
+	d, err := Load(testConfig, "testdata", 16000, channels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// feed feeds samples to d in chunks of the given frames and returns when
+// it fired, timed by the end of the chunk
+func feed(d *Detector, samples []int16, chunk, channels int) []time.Duration {
+	var fired []time.Duration
+	for i := 0; i < len(samples); i += chunk * channels {
+		end := min(i+chunk*channels, len(samples))
+		if d.Feed(samples[i:end]) {
+			fired = append(fired, time.Duration(end/channels)*time.Second/16000)
+		}
+	}
+	return fired
+}
+
+// stereo duplicates mono samples into two interleaved channels
+func stereo(mono []int16) []int16 {
+	out := make([]int16, 2*len(mono))
+	for i, s := range mono {
+		out[2*i], out[2*i+1] = s, s
+	}
+	return out
+}
+
+func TestSpeechBounds(t *testing.T) {
+	tests := []struct {
+		name       string
+		energies   []float64
+		start, end int
+		ok         bool
+	}{
+		{"empty", nil, 0, 0, false},
+		{"digital silence", []float64{-100, 5, 19}, 0, 0, false},
+		{"word in noise", []float64{30, 32, 60, 70, 65, 46, 31}, 2, 5, true},
+		// A pause inside the word does not split it
+		{"pause", []float64{30, 70, 30, 66, 30}, 1, 3, true},
+		{"one frame", []float64{25}, 0, 0, true},
+		// Frames exactly trimBelowPeak under the peak are kept
+		{"edges", []float64{44, 45, 70, 45, 44}, 1, 3, true},
+	}
+	for _, tt := range tests {
+		start, end, ok := speechBounds(tt.energies)
+		if start != tt.start || end != tt.end || ok != tt.ok {
+			t.Errorf("%s: speechBounds = %d, %d, %v; want %d, %d, %v", tt.name, start, end, ok, tt.start, tt.end, tt.ok)
+		}
+	}
+}
+
+func TestLoadScale(t *testing.T) {
+	d := loadDetector(t, 1)
+	if len(d.templates) != 3 {
+		t.Fatalf("loaded %d templates, want 3", len(d.templates))
+	}
+
+	// The scale is the mean distance between the enrolled utterances
+	want := (dtw(d.templates[0], d.templates[1]) + dtw(d.templates[0], d.templates[2]) +
+		dtw(d.templates[1], d.templates[2])) / 3
+	if math.Abs(d.scale-want) > 1e-9 {
+		t.Errorf("scale = %v, want %v", d.scale, want)
+	}
+
+	// The window fits the longest template with some margin
+	longest := 0
+	for _, tmpl := range d.templates {
+		longest = max(longest, len(tmpl))
+	}
+	if fits := longest*d.feats.hop + d.feats.frameLen; d.maxWindow <= fits {
+		t.Errorf("window of %d samples does not fit the longest template (%d)", d.maxWindow, fits)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	sample, err := os.ReadFile(filepath.Join(Dir("testdata", "jarvis"), "sample_1.wav"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	enroll := func(copies int) string {
+		dataDir := t.TempDir()
+		dir := Dir(dataDir, "jarvis")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for range copies {
+			if err := os.WriteFile(filepath.Join(dir, filepath.Base(nextSamplePath(dir))), sample, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return dataDir
+	}
+
+	for _, tt := range []struct {
+		name   string
+		copies int
+		want   string
+	}{
+		{"not enrolled", 0, "needs at least 2 enrolled samples"},
+		{"one sample", 1, "found 1"},
+		{"same recording twice", 2, "identical"},
+	} {
+		_, err := Load(testConfig, enroll(tt.copies), 16000, 1)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: Load error = %v, want one containing %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	d := loadDetector(t, 1)
+	jarvis, other := load(t, "jarvis.wav"), load(t, "other.wav")
+
+	if score := d.Score(jarvis, 1); score < testConfig.Threshold {
+		t.Errorf("enrolled word scores %.2f, want at least %.2f", score, testConfig.Threshold)
+	}
+	if score := d.Score(other, 1); score >= testConfig.Threshold/2 {
+		t.Errorf("different word scores %.2f, want under %.2f", score, testConfig.Threshold/2)
+	}
+	// Steady room noise before the word has no speech onset
+	if score := d.Score(jarvis[:12000], 1); score != 0 {
+		t.Errorf("room noise scores %.2f, want 0", score)
+	}
+	if score := d.Score(stereo(jarvis), 2); score < testConfig.Threshold {
+		t.Errorf("enrolled word in stereo scores %.2f, want at least %.2f", score, testConfig.Threshold)
+	}
+}
+
+func TestFeed(t *testing.T) {
+	jarvis, other := load(t, "jarvis.wav"), load(t, "other.wav")
+
+	// The word is said 1.0-1.53 s; the window is checked every 100 ms,
+	// whatever the chunk size
+	for _, chunk := range []int{160, 320, 1024, 4000} {
+		fired := feed(loadDetector(t, 1), jarvis, chunk, 1)
+		if len(fired) != 1 || fired[0] < 1400*time.Millisecond || fired[0] > 1800*time.Millisecond {
+			t.Errorf("%d-frame chunks: fired at %v, want once at ~1.5s", chunk, fired)
+		}
+	}
+	if fired := feed(loadDetector(t, 2), stereo(jarvis), 320, 2); len(fired) != 1 {
+		t.Errorf("stereo: fired at %v, want once", fired)
+	}
+	if fired := feed(loadDetector(t, 1), other, 320, 1); len(fired) != 0 {
+		t.Errorf("different word: fired at %v, want never", fired)
+	}
+}
+
+func TestFeedWaitsForFullWindow(t *testing.T) {
+	d := loadDetector(t, 1)
+	jarvis := load(t, "jarvis.wav")
+
+	// Starting 50 ms before the word, it is all in the window before the
+	// window is full; nothing is checked until then
+	fired := feed(d, jarvis[15200:], 320, 1)
+	full := time.Duration(d.maxWindow) * time.Second / 16000
+	if len(fired) != 1 || fired[0] < full {
+		t.Errorf("fired at %v, want once after the %v window filled", fired, full)
+	}
+}
+
+func TestFeedCooldown(t *testing.T) {
+	fired := feed(loadDetector(t, 1), load(t, "jarvis_repeated.wav"), 320, 1)
+
+	// Said at 0.5, 1.1 and 3.0 s: the second utterance falls inside the
+	// cooldown after the first detection
+	if len(fired) != 2 ||
+		fired[0] < 900*time.Millisecond || fired[0] > 1300*time.Millisecond ||
+		fired[1] < 3400*time.Millisecond || fired[1] > 3800*time.Millisecond {
+		t.Errorf("fired at %v, want at ~1.0s and ~3.5s", fired)
+	}
+}
+
+func TestResetForgetsWindow(t *testing.T) {
+	d := loadDetector(t, 1)
+	jarvis := load(t, "jarvis.wav")
+
+	// Reset halfway through the word, before it fired: the rest of it
+	// alone is no match
+	if fired := feed(d, jarvis[:20000], 320, 1); len(fired) != 0 {
+		t.Fatalf("fired at %v before the word ended", fired)
+	}
+	d.Reset()
+	if fired := feed(d, jarvis[20000:], 320, 1); len(fired) != 0 {
+		t.Errorf("fired at %v after Reset, want never", fired)
+	}
+}
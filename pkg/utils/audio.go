@@ -67,6 +67,50 @@ func SaveWAV(filename string, pcmData []byte, sampleRate int, channels int, bits
 	return os.WriteFile(filename, wavData, 0644)
 }
 
+// ReadWAV reads a 16-bit PCM WAV file and returns its interleaved samples
+func ReadWAV(filename string) (samples []int16, sampleRate int, channels int, err error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, 0, 0, fmt.Errorf("not a WAV file")
+	}
+
+	// Walk the chunks: "fmt " describes the audio, "data" holds it
+	bits := 0
+	for pos := 12; pos+8 <= len(data); {
+		id := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		body := data[pos+8:]
+		if size > len(body) {
+			size = len(body)
+		}
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, 0, 0, fmt.Errorf("invalid WAV format chunk")
+			}
+			if format := binary.LittleEndian.Uint16(body); format != 1 {
+				return nil, 0, 0, fmt.Errorf("unsupported WAV encoding %d, only PCM is supported", format)
+			}
+			channels = int(binary.LittleEndian.Uint16(body[2:]))
+			sampleRate = int(binary.LittleEndian.Uint32(body[4:]))
+			bits = int(binary.LittleEndian.Uint16(body[14:]))
+		case "data":
+			if bits != 16 {
+				return nil, 0, 0, fmt.Errorf("unsupported WAV sample size %d bits, only 16 is supported", bits)
+			}
+			return BytesToInt16(body[:size]), sampleRate, channels, nil
+		}
+
+		pos += 8 + size + size%2 // Chunks are word aligned
+	}
+
+	return nil, 0, 0, fmt.Errorf("WAV file has no audio data")
+}
+
 // CalculateRMS calculates the Root Mean Square of audio samples
 func CalculateRMS(samples []int16) float64 {
 	if len(samples) == 0 {
@@ -0,0 +1,124 @@
+package utils
+
+import "math"
+
+// resampleTaps is the number of kernel zero crossings on each side of a
+// resampled sample; more taps filter better and cost more
+const resampleTaps = 16
+
+// Downmix averages interleaved channels into mono
+func Downmix(samples []int16, channels int) []int16 {
+	if channels <= 1 {
+		return samples
+	}
+
+	mono := make([]int16, len(samples)/channels)
+	for i := range mono {
+		var sum int
+		for c := 0; c < channels; c++ {
+			sum += int(samples[i*channels+c])
+		}
+		mono[i] = int16(sum / channels)
+	}
+	return mono
+}
+
+// Resample converts a whole recording of interleaved samples from one
+// sample rate to another
+func Resample(samples []int16, from, to, channels int) []int16 {
+	if from == to {
+		return samples
+	}
+	r := NewResampler(from, to, channels)
+	return append(r.Process(samples), r.Flush()...)
+}
+
+// Resampler converts a stream of interleaved samples from one sample rate
+// to another, chunk by chunk. It interpolates with a windowed sinc that
+// also filters out what the lower rate can't represent, so downsampling
+// doesn't alias.
+type Resampler struct {
+	channels int
+	step     float64 // Input frames per output frame
+	cutoff   float64 // Low-pass cutoff, relative to the input Nyquist frequency
+	half     int     // Kernel half width, in input frames
+
+	buf []float64 // Input frames not yet fully used, interleaved
+	pos float64   // Position of the next output frame in buf, in frames
+}
+
+// NewResampler creates a resampler from one sample rate to another
+func NewResampler(from, to, channels int) *Resampler {
+	if channels < 1 {
+		channels = 1
+	}
+	cutoff := 0.95 * math.Min(1, float64(to)/float64(from))
+	half := int(math.Ceil(resampleTaps / cutoff))
+
+	// Start with silence before the first sample, so the first output
+	// frame lines up with the first input frame
+	return &Resampler{
+		channels: channels,
+		step:     float64(from) / float64(to),
+		cutoff:   cutoff,
+		half:     half,
+		buf:      make([]float64, half*channels),
+		pos:      float64(half),
+	}
+}
+
+// Process resamples a chunk. Output lags the input by the kernel half
+// width; Flush returns what is still held back.
+func (r *Resampler) Process(samples []int16) []int16 {
+	for _, s := range samples {
+		r.buf = append(r.buf, float64(s))
+	}
+
+	frames := len(r.buf) / r.channels
+	var out []int16
+	for r.pos+float64(r.half) < float64(frames) {
+		out = r.interpolate(out)
+		r.pos += r.step
+	}
+
+	// Drop the frames no later output needs
+	if drop := int(r.pos) - r.half; drop > 0 {
+		r.buf = append(r.buf[:0], r.buf[drop*r.channels:]...)
+		r.pos -= float64(drop)
+	}
+	return out
+}
+
+// Flush returns the output held back at the end of the stream
+func (r *Resampler) Flush() []int16 {
+	return r.Process(make([]int16, r.half*r.channels))
+}
+
+// interpolate appends the output frame at r.pos
+func (r *Resampler) interpolate(out []int16) []int16 {
+	center := int(r.pos)
+	first := max(0, center-r.half+1)
+	last := min(len(r.buf)/r.channels-1, center+r.half)
+
+	for c := 0; c < r.channels; c++ {
+		var sum float64
+		for n := first; n <= last; n++ {
+			sum += r.buf[n*r.channels+c] * r.kernel(r.pos-float64(n))
+		}
+		out = append(out, int16(math.Max(math.MinInt16, math.Min(math.MaxInt16, math.Round(sum)))))
+	}
+	return out
+}
+
+// kernel is a Hann-windowed sinc low-pass at the cutoff
+func (r *Resampler) kernel(d float64) float64 {
+	if math.Abs(d) >= float64(r.half) {
+		return 0
+	}
+	window := 0.5 + 0.5*math.Cos(math.Pi*d/float64(r.half))
+	x := r.cutoff * d
+	if x == 0 {
+		return r.cutoff * window
+	}
+	return r.cutoff * math.Sin(math.Pi*x) / (math.Pi * x) * window
+}
@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// tone generates a sine wave of the given frequency and peak amplitude
+func tone(freq float64, rate, frames int, amplitude float64) []int16 {
+	out := make([]int16, frames)
+	for i := range out {
+		out[i] = int16(amplitude * math.Sin(2*math.Pi*freq*float64(i)/float64(rate)))
+	}
+	return out
+}
+
+// rms is the root mean square of samples, skipping the edges where the
+// resampler sees the silence around the recording
+func rms(samples []int16, skip int) float64 {
+	samples = samples[skip : len(samples)-skip]
+	return math.Sqrt(CalculateRMS(samples))
+}
+
+func TestDownmix(t *testing.T) {
+	if got := Downmix([]int16{100, -100, 300, 100, -7, -8}, 2); !slices.Equal(got, []int16{0, 200, -7}) {
+		t.Errorf("stereo downmix = %v", got)
+	}
+	mono := []int16{1, 2, 3}
+	if got := Downmix(mono, 1); !slices.Equal(got, mono) {
+		t.Errorf("mono downmix = %v", got)
+	}
+	// No overflow on loud channels
+	if got := Downmix([]int16{math.MaxInt16, math.MaxInt16}, 2); got[0] != math.MaxInt16 {
+		t.Errorf("loud downmix = %v", got)
+	}
+}
+
+func TestResampleSameRate(t *testing.T) {
+	in := []int16{1, 2, 3}
+	if got := Resample(in, 16000, 16000, 1); !slices.Equal(got, in) {
+		t.Errorf("Resample at the same rate = %v", got)
+	}
+}
+
+func TestResampleKeepsTones(t *testing.T) {
+	for _, tt := range []struct{ from, to int }{
+		{48000, 16000},
+		{44100, 16000},
+		{16000, 48000},
+	} {
+		in := tone(1000, tt.from, tt.from/2, 10000)
+		out := Resample(in, tt.from, tt.to, 1)
+
+		// Half a second in, half a second out, in step with the input
+		if want := tt.to / 2; math.Abs(float64(len(out)-want)) > 1 {
+			t.Errorf("%d -> %d Hz: %d frames, want %d", tt.from, tt.to, len(out), want)
+			continue
+		}
+		if got := rms(out, 200); math.Abs(got-10000/math.Sqrt2) > 100 {
+			t.Errorf("%d -> %d Hz: 1 kHz tone RMS %.0f, want %.0f", tt.from, tt.to, got, 10000/math.Sqrt2)
+		}
+		want := tone(1000, tt.to, len(out), 10000)
+		for i := 200; i < len(out)-200; i++ {
+			if math.Abs(float64(out[i])-float64(want[i])) > 200 {
+				t.Errorf("%d -> %d Hz: frame %d = %d, want ~%d", tt.from, tt.to, i, out[i], want[i])
+				break
+			}
+		}
+	}
+}
+
+func TestResampleFiltersAliases(t *testing.T) {
+	// 10 kHz can't be represented at 16 kHz; without filtering it would
+	// fold back to 6 kHz at full level
+	out := Resample(tone(10000, 48000, 24000, 10000), 48000, 16000, 1)
+	if got := rms(out, 200); got > 70 {
+		t.Errorf("10 kHz tone left RMS %.0f after downsampling to 16 kHz, want it 40 dB down", got)
+	}
+}
+
+func TestResamplerChunksMatchWhole(t *testing.T) {
+	in := tone(440, 48000, 9600, 8000)
+	want := Resample(in, 48000, 16000, 1)
+
+	r := NewResampler(48000, 16000, 1)
+	var got []int16
+	for rest, sizes := in, []int{1024, 7, 1, 333, 2048}; len(rest) > 0; sizes = append(sizes[1:], sizes[0]) {
+		n := min(sizes[0], len(rest))
+		got = append(got, r.Process(rest[:n])...)
+		rest = rest[n:]
+	}
+	got = append(got, r.Flush()...)
+
+	if !slices.Equal(got, want) {
+		t.Errorf("chunked output differs from whole: %d frames vs %d", len(got), len(want))
+	}
+}
+
+func TestResampleStereo(t *testing.T) {
+	// Left carries a tone, right is silent; they must not mix
+	left := tone(1000, 48000, 24000, 10000)
+	stereo := make([]int16, 2*len(left))
+	for i, s := range left {
+		stereo[2*i] = s
+	}
+
+	out := Resample(stereo, 48000, 16000, 2)
+	if len(out)%2 != 0 {
+		t.Fatalf("odd number of stereo samples: %d", len(out))
+	}
+	outLeft, outRight := make([]int16, len(out)/2), make([]int16, len(out)/2)
+	for i := range outLeft {
+		outLeft[i], outRight[i] = out[2*i], out[2*i+1]
+	}
+	if got := rms(outLeft, 200); math.Abs(got-10000/math.Sqrt2) > 100 {
+		t.Errorf("left RMS %.0f, want %.0f", got, 10000/math.Sqrt2)
+	}
+	if got := rms(outRight, 0); got != 0 {
+		t.Errorf("right RMS %.0f, want silence", got)
+	}
+}
@@ -1,8 +1,37 @@
 package utils
 
-import "strings"
+import (
+	"strings"
+	"unicode"
+)
 
 // ContainsIgnoreCase returns true if substr exists in s ignoring case.
 func ContainsIgnoreCase(s, substr string) bool {
 	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
 }
+
+// accentReplacer maps accented vowels to their plain form
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u",
+	"Á", "A", "É", "E", "Í", "I", "Ó", "O", "Ú", "U", "Ü", "U",
+)
+
+// RemoveAccents strips Spanish diacritics from vowels (ñ is preserved).
+func RemoveAccents(s string) string {
+	return accentReplacer.Replace(s)
+}
+
+// NormalizeText lowercases s, removes accents and punctuation (except %) and collapses
+// whitespace, so spoken phrases can be compared reliably.
+func NormalizeText(s string) string {
+	s = RemoveAccents(strings.ToLower(s))
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '%':
+			return r
+		default:
+			return ' '
+		}
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}